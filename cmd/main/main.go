@@ -1,111 +1,234 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
 
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/koushikidey/go-meetingroombook/pkg/cache"
 	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/controllers"
 	"github.com/koushikidey/go-meetingroombook/pkg/googleapi"
-	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/health"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/metrics"
 	"github.com/koushikidey/go-meetingroombook/pkg/routes"
-	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+	session "github.com/koushikidey/go-meetingroombook/pkg/sessions"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracing"
+	"github.com/koushikidey/go-meetingroombook/pkg/version"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 
 	_ "github.com/koushikidey/go-meetingroombook/docs"
-	"github.com/robfig/cron/v3"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"gorm.io/gorm"
 )
 
-var reminderCron *cron.Cron
-
-func startReminderJob() {
-	log.Println("startReminderJob() called")
+// @title Meeting Room Booking API
+// @version 1.0
+// @description API documentation for Meeting Room Booking system
+// @host localhost:9010
+// @BasePath /
 
-	reminderCron = cron.New()
+func main() {
+	args := os.Args[1:]
+	command := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	_, err := reminderCron.AddFunc("@every 1m", func() {
-		log.Println("Cron job triggered at", time.Now().Format(time.RFC3339))
+	cfg, rest, err := config.Load(args)
+	if err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
+	if err := logging.Setup(cfg.Log); err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
 
-		db := config.GetDB()
-		var bookings []models.Booking
-		now := time.Now().UTC()
-		future := now.Add(10 * time.Minute)
-		log.Printf("Looking for bookings between %s and %s",
-			now.Format(time.RFC3339),
-			future.Format(time.RFC3339),
-		)
-		result := db.Where("start_time BETWEEN ? AND ? AND reminder_sent = ?", now, future, false).Find(&bookings)
-		if result.Error != nil {
-			log.Printf("Error fetching bookings: %v", result.Error)
-			return
+	switch command {
+	case "":
+		serve(cfg)
+	case "migrate":
+		runMigrate(cfg, rest)
+	case "config":
+		// Prints what the server would run with, secrets masked.
+		if err := cfg.Dump(os.Stdout); err != nil {
+			fatal("Failed to print the configuration", "error", err)
 		}
-
-		log.Printf("Found %d upcoming bookings", len(bookings))
-
-		for _, booking := range bookings {
-			var employee models.Employee
-			db.First(&employee, booking.EmployeeID)
-
-			msg := fmt.Sprintf("Reminder: You have a meeting in Room %d from %s to %s.",
-				booking.RoomID,
-				booking.StartTime.Format(time.RFC3339),
-				booking.EndTime.Format(time.RFC3339),
-			)
-
-			log.Printf(" Sending reminder to %s", employee.Email)
-			go utils.SendEmail(employee.Email, "Meeting Reminder", msg)
-			db.Model(&booking).Update("ReminderSent", true)
+		if err := cfg.Validate(); err != nil {
+			fatal("Invalid configuration", "error", err)
 		}
-	})
-
-	if err != nil {
-		log.Fatalf(" Failed to add cron job: %v", err)
+	default:
+		fatal(fmt.Sprintf("Unknown command %q; use migrate, config or no command to serve", command))
 	}
-
-	reminderCron.Start()
 }
 
-// @title Meeting Room Booking API
-// @version 1.0
-// @description API documentation for Meeting Room Booking system
-// @host localhost:9010
-// @BasePath /
+// fatal logs the error and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
 
-func main() {
-	log.Println("Application starting...")
+func serve(cfg config.Config) {
+	build := version.Get()
+	slog.Info("Application starting", "version", build.Version, "commit", build.Commit)
+	if err := cfg.Validate(); err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
+	slog.Info("Configuration loaded", "config", cfg.String())
 
-	config.Connect()
-	cache.InitCache()
-	startReminderJob()
-	clientID := os.Getenv("GOOGLE_CLIENT_ID")
-	clientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
-	redirectURL := os.Getenv("GOOGLE_REDIRECT_URL")
+	stopTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal("Failed to set up tracing", "error", err)
+	}
+	db, err := config.Connect(cfg)
+	if err != nil {
+		fatal("Database not ready", "error", err)
+	}
+	if err := tracing.Instrument(db); err != nil {
+		fatal("Failed to trace database queries", "error", err)
+	}
+	deps := controllers.NewDependencies(db, cfg)
+	var checks []health.Check
+	if strings.EqualFold(cfg.Session.Store, "redis") {
+		client, err := openRedis(cfg.Session.RedisURL)
+		if err != nil {
+			fatal("Session store not ready", "error", err)
+		}
+		defer client.Close()
+		deps.Sessions = session.NewStore(session.Redis(client), cfg.Session)
+		checks = append(checks, health.Check{Name: "sessions", Run: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		}})
+	}
+	h := controllers.NewHandler(deps)
+	googleapi.InitOAuth(cfg.Google.ClientID, cfg.Google.ClientSecret, cfg.Google.RedirectURL)
+	deps.Outbox.Start()
+
+	// Every replica schedules the jobs, but only the leader runs them.
+	deps.Elector.Start()
+	for _, job := range h.Jobs(cfg.Jobs) {
+		if err := deps.Jobs.Register(job); err != nil {
+			fatal("Failed to register job", "job", job.Name, "error", err)
+		}
+	}
+	deps.Jobs.Start()
 
-	googleapi.InitOAuth(clientID, clientSecret, redirectURL)
+	checks = append(checks, health.Database(db), health.Migrations(db), health.Scheduler(deps.Jobs))
+	if cfg.Email.ReadyCheck {
+		checks = append(checks, health.SMTP(cfg.Email.Host, cfg.Email.Port))
+	}
+	probes := health.New(checks...)
 
 	router := mux.NewRouter()
-	routes.RegisterMeetingRoomRoutes(router)
-
-	wd, err := os.Getwd()
+	router.Use(otelmux.Middleware(tracing.ServiceName), metrics.Middleware, h.RequestLogging(), h.CSRFProtection())
+	routes.RegisterMeetingRoomRoutes(router, h)
+	router.Handle("/metrics", metrics.Handler()).Methods("GET")
+	router.HandleFunc("/healthz", probes.Healthz).Methods("GET")
+	router.HandleFunc("/readyz", probes.Readyz).Methods("GET")
+	router.HandleFunc("/version", probes.Version).Methods("GET")
+
+	frontendPath, err := filepath.Abs(cfg.Server.FrontendDir)
 	if err != nil {
-		log.Fatal("Failed to get working dir:", err)
+		fatal("Failed to resolve frontend directory", "error", err)
 	}
-	frontendPath := filepath.Join(wd, "frontend")
 	_, err = os.Stat(filepath.Join(frontendPath, "index.html"))
 	if err != nil {
-		log.Fatalf("index.html not found at %s: %v", frontendPath, err)
+		fatal("index.html not found", "dir", frontendPath, "error", err)
 	}
 
-	log.Println("index.html found! Serving from:", frontendPath)
+	slog.Info("Serving the frontend", "dir", frontendPath)
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 	router.PathPrefix("/").Handler(http.StripPrefix("/", http.FileServer(http.Dir(frontendPath))))
 
-	log.Println(" Server running at http://localhost:9010")
-	log.Fatal(http.ListenAndServe("localhost:9010", router))
-}
\ No newline at end of file
+	server := &http.Server{
+		Addr:         cfg.Server.Addr,
+		Handler:      router,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout),
+	}
+
+	stop, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server running", "url", "http://"+cfg.Server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Server failed", "error", err)
+		}
+	case <-stop.Done():
+		slog.Info("Shutting down")
+	}
+
+	ctx, done := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
+	defer done()
+	shutdown(ctx, server, probes, time.Duration(cfg.Server.DrainDelay), deps, db, stopTracing)
+}
+
+// openRedis connects to the Redis server at url and checks that it answers.
+func openRedis(url string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SESSION_REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("could not connect to Redis: %w", err)
+	}
+	return client, nil
+}
+
+// shutdown stops the server in order within ctx's deadline: readiness fails
+// for drainDelay so the orchestrator stops sending traffic, in-flight
+// requests are drained, running jobs are waited for and the scheduler lease
+// handed over, queued emails are sent, the database pool is closed and the
+// remaining spans are exported. Steps that run out of time are skipped with
+// a log message.
+func shutdown(ctx context.Context, server *http.Server, probes *health.Checker, drainDelay time.Duration,
+	deps controllers.Dependencies, db *gorm.DB, stopTracing func(context.Context) error) {
+	probes.Drain()
+	select {
+	case <-time.After(drainDelay):
+	case <-ctx.Done():
+	}
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("Failed to drain requests", "error", err)
+	}
+
+	if err := deps.Jobs.Stop(ctx); err != nil {
+		slog.Warn("Timed out waiting for running jobs")
+	}
+	deps.Elector.Stop(ctx)
+
+	if err := deps.Outbox.Shutdown(ctx); err != nil {
+		slog.Error("Failed to flush queued emails", "error", err)
+	}
+
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("Failed to close the database", "error", err)
+		}
+	}
+	if err := stopTracing(ctx); err != nil {
+		slog.Error("Failed to export the remaining spans", "error", err)
+	}
+	slog.Info("Shutdown complete")
+}
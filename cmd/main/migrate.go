@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"gorm.io/gorm"
+)
+
+const migrateUsage = `usage: meetingroombook migrate [flags] [command]
+
+commands:
+  up           apply every pending migration (default)
+  down [N]     roll back the last N migrations (default 1)
+  status       list migrations and whether they are applied`
+
+// runMigrate implements the migrate subcommand. Only the database settings
+// of cfg are used.
+func runMigrate(cfg config.Config, args []string) {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		db := openDatabase(cfg)
+		ran, err := migrations.Up(db)
+		for _, m := range ran {
+			slog.Info("Applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			fatal("Migration failed", "error", err)
+		}
+		if len(ran) == 0 {
+			slog.Info("Database is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fatal(fmt.Sprintf("Invalid number of steps %q", args[1]))
+			}
+			steps = n
+		}
+		db := openDatabase(cfg)
+		reverted, err := migrations.Down(db, steps)
+		for _, m := range reverted {
+			slog.Info("Rolled back migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			fatal("Rollback failed", "error", err)
+		}
+		if len(reverted) == 0 {
+			slog.Info("Nothing to roll back")
+		}
+	case "status":
+		db := openDatabase(cfg)
+		statuses, err := migrations.List(db)
+		if err != nil {
+			fatal("Failed to list migrations", "error", err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%4d  %-30s %s\n", s.Version, s.Name, applied)
+		}
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		os.Exit(2)
+	}
+}
+
+func openDatabase(cfg config.Config) *gorm.DB {
+	if err := cfg.Database.Validate(); err != nil {
+		fatal("Invalid configuration", "error", err)
+	}
+	db, err := config.Open(cfg.Database)
+	if err != nil {
+		fatal("Failed to open the database", "error", err)
+	}
+	return db
+}
@@ -15,170 +15,253 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/booking/{id}": {
-            "put": {
-                "description": "Allows an authenticated employee to update their booking",
-                "consumes": [
+        "/admin/jobs": {
+            "get": {
+                "description": "Returns every background job with its schedule, whether it is paused, how its last run went, when it runs next and totals over all runs. Administrators only.",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "List background jobs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.JobResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{name}/pause": {
+            "post": {
+                "description": "Stops the scheduled runs of the job on every instance until it is resumed. A run in progress finishes. Administrators only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Jobs"
                 ],
-                "summary": "Update existing booking details",
+                "summary": "Pause a background job",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Booking ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Job name, e.g. reminders",
+                        "name": "name",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Updated booking details",
-                        "name": "booking",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.BookingDTO"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.BookingDTO"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid Employee ID or JSON input",
-                        "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.JobResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized (not logged in)",
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "403": {
-                        "description": "Forbidden (trying to update another employee's booking)",
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "404": {
-                        "description": "Booking not found",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "500": {
-                        "description": "Failed to update booking",
+                        "description": "Job not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Allows an authenticated employee to delete their booking",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/admin/jobs/{name}/resume": {
+            "post": {
+                "description": "Schedules the job again from its next due time. Administrators only.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Jobs"
                 ],
-                "summary": "Delete existing booking details",
+                "summary": "Resume a paused background job",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Booking ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Job name, e.g. reminders",
+                        "name": "name",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobResponse"
+                        }
                     },
-                    {
-                        "description": "Deleted booking details",
-                        "name": "booking",
-                        "in": "body",
-                        "required": true,
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/models.BookingDTO"
+                            "$ref": "#/definitions/apierror.Response"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{name}/run": {
+            "post": {
+                "description": "Starts the job straight away in the background, even while it is paused. Administrators only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Jobs"
+                ],
+                "summary": "Run a background job now",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job name, e.g. reminders",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "400": {
-                        "description": "Invalid Employee ID or JSON input",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.JobResponse"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized (not logged in)",
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "403": {
-                        "description": "Forbidden (trying to update another employee's booking)",
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "404": {
-                        "description": "Booking not found",
+                        "description": "Job not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Failed to delete booking",
+                    "409": {
+                        "description": "Job is already running",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Server is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/bookings": {
+        "/approvals": {
             "get": {
-                "description": "Retrieves all bookings along with all details",
+                "description": "Returns pending bookings for rooms whose approver group includes the logged-in employee",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Approvals"
                 ],
-                "summary": "Get list of all bookings",
+                "summary": "List bookings awaiting my approval",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.BookingDTO"
+                                "$ref": "#/definitions/models.BookingResponse"
                             }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/approver-groups": {
+            "get": {
+                "description": "Returns approver groups with their members",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Approvals"
+                ],
+                "summary": "Get all approver groups",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ApproverGroupResponse"
+                            }
                         }
                     }
                 }
             },
             "post": {
-                "description": "Creates a new booking if the time and capacity constraints are satisfied. Sends confirmation email and adds Google Calendar event if linked.",
+                "description": "Creates a group of employees who can approve bookings for restricted rooms. Administrators only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -186,17 +269,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Approvals"
                 ],
-                "summary": "Create a new booking",
+                "summary": "Create an approver group",
                 "parameters": [
                     {
-                        "description": "Booking request data",
-                        "name": "booking",
+                        "description": "Group name and member employee IDs",
+                        "name": "group",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.BookingDTO"
+                            "$ref": "#/definitions/models.ApproverGroupDTO"
                         }
                     }
                 ],
@@ -204,120 +287,108 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.BookingDTO"
+                            "$ref": "#/definitions/models.ApproverGroupResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input or time conflict",
+                        "description": "Invalid JSON or unknown members",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "409": {
-                        "description": "Booking time conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/bookings/{id}": {
-            "get": {
-                "description": "Retrieves booking details by ID including employee and room info",
+        "/booking-groups": {
+            "post": {
+                "description": "Reserves all listed rooms for the same slot, or none of them if any room is unavailable. Sends one set of notifications and creates one calendar event listing every room.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Bookings"
+                    "Booking Groups"
                 ],
-                "summary": "Get booking by booking ID",
+                "summary": "Book several rooms for one meeting",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Booking ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Rooms and time slot",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingGroupDTO"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.BookingDTO"
+                            "$ref": "#/definitions/models.BookingGroupResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid ID",
+                        "description": "Invalid input or capacity exceeded",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "404": {
-                        "description": "Booking not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Error marshalling data",
+                    "409": {
+                        "description": "One or more rooms are already booked",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/employees": {
-            "get": {
-                "description": "Returns a list of all employees with their bookings and room details",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Employees"
-                ],
-                "summary": "Get all employees",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.EmployeeDTO"
-                            }
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/employees/{id}": {
+        "/booking-groups/{id}": {
             "get": {
-                "description": "Retrieves employee details by ID including bookings and room info, uses cache for faster response",
+                "description": "Returns the group with the bookings of all its rooms",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Employees"
+                    "Booking Groups"
                 ],
-                "summary": "Get employee by ID (with cache)",
+                "summary": "Get a multi-room booking",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Employee ID",
+                        "description": "Booking group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -327,31 +398,37 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.EmployeeDTO"
+                            "$ref": "#/definitions/models.BookingGroupResponse"
                         }
                     },
                     "400": {
                         "description": "Invalid ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "404": {
-                        "description": "Employee not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Error marshalling data",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking group not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             },
             "put": {
-                "description": "Allows an authenticated employee to update their name, email, and password",
+                "description": "Moves the whole group to a new slot and/or changes its rooms. Every room is re-checked and the update is applied to all rooms or none.",
                 "consumes": [
                     "application/json"
                 ],
@@ -359,24 +436,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Employees"
+                    "Booking Groups"
                 ],
-                "summary": "Update an employee's own details",
+                "summary": "Update a multi-room booking",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Employee ID",
+                        "description": "Booking group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated employee details (name, email, password)",
-                        "name": "employee",
+                        "description": "Rooms and time slot",
+                        "name": "group",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.EmployeeDTO"
+                            "$ref": "#/definitions/models.BookingGroupDTO"
                         }
                     }
                 ],
@@ -384,74 +461,102 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.EmployeeDTO"
+                            "$ref": "#/definitions/models.BookingGroupResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid Employee ID or JSON input",
+                        "description": "Invalid input or capacity exceeded",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized (not logged in)",
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "403": {
-                        "description": "Forbidden (trying to update another employee)",
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "404": {
-                        "description": "Employee not found",
+                        "description": "Booking group not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Failed to update employee",
+                    "409": {
+                        "description": "One or more rooms are already booked",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/google/login": {
-            "get": {
-                "description": "Redirects logged-in employee to Google OAuth consent screen for authentication",
-                "produces": [
-                    "text/plain"
-                ],
+            },
+            "delete": {
+                "description": "Cancels the bookings of every room in the group",
                 "tags": [
-                    "Authentication"
+                    "Booking Groups"
+                ],
+                "summary": "Cancel a multi-room booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Initiate Google OAuth login flow",
                 "responses": {
-                    "307": {
-                        "description": "Redirect to Google OAuth consent screen"
+                    "204": {
+                        "description": "Booking group cancelled"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
                     },
                     "401": {
-                        "description": "User not logged in",
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking group not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "A booking of the group cannot be cancelled; none were",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "500": {
-                        "description": "Failed to create auth URL",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/login": {
-            "post": {
-                "description": "Authenticates employee by email and password and starts a session",
+        "/booking/{id}": {
+            "put": {
+                "description": "Allows an authenticated employee, or a delegate with edit permission, to update a booking",
                 "consumes": [
                     "application/json"
                 ],
@@ -459,119 +564,260 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Bookings"
                 ],
-                "summary": "Log in an employee",
+                "summary": "Update existing booking details",
                 "parameters": [
                     {
-                        "description": "Login credentials (email and password)",
-                        "name": "credentials",
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated booking details",
+                        "name": "booking",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.EmployeeDTO"
+                            "$ref": "#/definitions/models.BookingUpdateDTO"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Login successful message",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.BookingResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input",
+                        "description": "Invalid Employee ID or JSON input",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "401": {
-                        "description": "Email not found or incorrect password",
+                        "description": "Unauthorized (not logged in)",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (trying to update another employee's booking)",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update booking",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/logout": {
-            "post": {
-                "description": "Ends the employee's session by clearing session data",
+            },
+            "delete": {
+                "description": "Allows an authenticated employee, or a delegate with cancel permission, to cancel a booking. The booking is kept with status cancelled.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Bookings"
+                ],
+                "summary": "Cancel existing booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional cancellation reason",
+                        "name": "booking",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingTransitionDTO"
+                        }
+                    }
                 ],
-                "summary": "Log out the current employee",
                 "responses": {
-                    "200": {
-                        "description": "Logout successful message",
+                    "204": {
+                        "description": "Booking cancelled"
+                    },
+                    "400": {
+                        "description": "Invalid Employee ID or JSON input",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized (not logged in)",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (trying to update another employee's booking)",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Booking can no longer be cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to cancel booking",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/oauth2callback": {
+        "/bookings": {
             "get": {
-                "description": "Processes OAuth code and state, exchanges code for tokens, and stores them linked to the employee",
+                "description": "Retrieves a page of the bookings the logged-in employee organizes or created, or may see as a delegate of their organizer, with their room and organizer. The next page's cursor is returned in the X-Next-Cursor and Link headers.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Bookings"
                 ],
-                "summary": "Handle Google OAuth callback",
+                "summary": "Get list of bookings",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "OAuth authorization code",
-                        "name": "code",
-                        "in": "query",
-                        "required": true
+                        "description": "Comma-separated statuses, e.g. confirmed,checked_in",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "OAuth state parameter",
-                        "name": "state",
-                        "in": "query",
-                        "required": true
+                        "description": "Only bookings ending after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only bookings starting before this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated room IDs",
+                        "name": "room_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated organizer IDs",
+                        "name": "employee_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Room location",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum room capacity",
+                        "name": "min_capacity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum room capacity",
+                        "name": "max_capacity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, start_time, end_time, created_at or status; prefix with - for descending (default start_time)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from X-Next-Cursor",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Google Calendar authorization successful! You may close this tab.",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BookingResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Missing or invalid code/state parameter",
+                        "description": "Invalid filter, sort, limit or cursor",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "500": {
-                        "description": "Token exchange or database save failed",
+                        "description": "Internal Server Error",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
-            }
-        },
-        "/register": {
+            },
             "post": {
-                "description": "Creates a new employee account with a hashed password",
+                "description": "Creates a new booking if the time and capacity constraints are satisfied. Sends confirmation email and adds Google Calendar event if linked.\nWith \"hold\": true the slot is only held (status tentative) for hold_minutes (default 15) until confirmed via POST /bookings/{id}/confirm.",
                 "consumes": [
                     "application/json"
                 ],
@@ -579,17 +825,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Authentication"
+                    "Bookings"
                 ],
-                "summary": "Register a new employee",
+                "summary": "Create a new booking",
                 "parameters": [
                     {
-                        "description": "Employee registration details",
-                        "name": "employee",
+                        "description": "Booking request data",
+                        "name": "booking",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.EmployeeDTO"
+                            "$ref": "#/definitions/models.BookingDTO"
                         }
                     }
                 ],
@@ -597,206 +843,2810 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/models.BookingResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input or creation failed",
+                        "description": "Invalid input or time conflict",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Failed to hash password",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
-                    }
-                }
-            }
+                    },
+                    "409": {
+                        "description": "Booking time conflict",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
         },
-        "/rooms": {
+        "/bookings/{id}": {
             "get": {
-                "description": "Retrieves all rooms along with their bookings and booked employees",
+                "description": "Retrieves booking details by ID including employee and room info",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rooms"
+                    "Bookings"
+                ],
+                "summary": "Get booking by booking ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get list of all rooms",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.RoomDTO"
-                            }
+                            "$ref": "#/definitions/models.BookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Error marshalling data",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/approve": {
+            "get": {
+                "description": "Landing page for the signed links in approval emails. The decision is submitted with a POST so that link scanners cannot approve bookings.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Approvals"
+                ],
+                "summary": "Show the approve/reject confirmation page",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signed approval token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML confirmation form",
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "400": {
+                        "description": "Invalid or expired token, or one for an earlier request",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
                     }
                 }
             },
             "post": {
-                "description": "Adds a new meeting room to the system",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Confirms a booking for a restricted room. Callers must be in the room's approver group or present a signed token from the approval email.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rooms"
+                    "Approvals"
                 ],
-                "summary": "Create a new room",
+                "summary": "Approve a pending booking",
                 "parameters": [
                     {
-                        "description": "Room details",
-                        "name": "room",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RoomDTO"
-                        }
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signed approval token",
+                        "name": "token",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RoomDTO"
+                            "$ref": "#/definitions/models.BookingResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid JSON or bad request",
+                        "description": "Invalid booking ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Not an approver for this room",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Booking is not pending approval",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
         },
-        "/rooms/{id}": {
-            "put": {
-                "description": "Update details of existing room by ID",
-                "consumes": [
-                    "application/json"
-                ],
+        "/bookings/{id}/confirm": {
+            "post": {
+                "description": "Converts a hold into a real booking. Rooms that require approval move to pending_approval instead of confirmed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rooms"
+                    "Bookings"
                 ],
-                "summary": "Update room details",
+                "summary": "Confirm a tentative hold",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Room ID",
+                        "description": "Booking ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Room details to update",
-                        "name": "room",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.RoomDTO"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RoomDTO"
+                            "$ref": "#/definitions/models.BookingResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid JSON or bad request",
+                        "description": "Invalid booking ID",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
                     "404": {
-                        "description": "Room not found",
+                        "description": "Booking not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Booking is not a hold or the hold has expired",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/apierror.Response"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.BookingDTO": {
-            "type": "object",
-            "properties": {
-                "employee_id": {
-                    "type": "integer"
-                },
-                "end_time": {
-                    "type": "string"
-                },
-                "num_attendees": {
-                    "type": "integer"
-                },
-                "room_id": {
-                    "type": "integer"
-                },
-                "start_time": {
-                    "type": "string"
-                }
-            }
         },
-        "models.EmployeeDTO": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
+        "/bookings/{id}/history": {
+            "get": {
+                "description": "Lists every status change of the booking with its actor and reason, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Get the status history of a booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.BookingHistoryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid booking ID",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
                 }
             }
         },
-        "models.RoomDTO": {
-            "type": "object",
-            "properties": {
-                "capacity": {
-                    "type": "integer"
-                },
-                "location": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
+        "/bookings/{id}/reject": {
+            "get": {
+                "description": "Landing page for the signed links in approval emails. The decision is submitted with a POST so that link scanners cannot approve bookings.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "Approvals"
+                ],
+                "summary": "Show the approve/reject confirmation page",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signed approval token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML confirmation form",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired token, or one for an earlier request",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Rejects a booking for a restricted room and releases the slot. Callers must be in the room's approver group or present a signed token from the approval email.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Approvals"
+                ],
+                "summary": "Reject a pending booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Signed rejection token",
+                        "name": "token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reason shown to the organizer",
+                        "name": "reason",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid booking ID",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an approver for this room",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Booking is not pending approval",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/status": {
+            "post": {
+                "description": "Moves a booking through its lifecycle (e.g. checked_in, completed, cancelled, no_show, released). Only transitions allowed from the current status are accepted. Check-in opens 15 minutes before the start and closes at the end, completed needs the meeting to have started and no_show the no-show grace period to have passed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Change the status of a booking",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Target status and reason",
+                        "name": "transition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingTransitionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid booking ID or JSON input",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Transition not allowed from the current status or at this time",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/bookings/{id}/transfer": {
+            "post": {
+                "description": "Makes another employee the organizer of a booking. The organizer, a delegate with edit permission or an administrator may transfer it. Bookings that are part of a multi-room group are transferred together with the rest of the group.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookings"
+                ],
+                "summary": "Transfer a booking to another organizer",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Booking ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New organizer and optional reason",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingTransferDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, JSON or new organizer",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Booking not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Booking is no longer active or was changed concurrently",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/csrf-token": {
+            "get": {
+                "description": "Returns the token clients signed in with the session cookie must send in the X-CSRF-Token header of every POST, PUT and DELETE request; without it such requests are rejected with 403. The token lasts as long as the session; logging in replaces it, so fetch it again afterwards. Clients that send no session cookie need no token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Get the CSRF token of my session",
+                "responses": {
+                    "200": {
+                        "description": "csrf_token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/delegations": {
+            "get": {
+                "description": "Returns the delegations the logged-in employee has granted and those granted to them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Delegations"
+                ],
+                "summary": "List my delegations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DelegationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Lets another employee book, edit and/or cancel bookings on behalf of the logged-in employee. Granting again replaces the previous permissions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Delegations"
+                ],
+                "summary": "Grant a delegate permission to manage my bookings",
+                "parameters": [
+                    {
+                        "description": "Delegate and permissions",
+                        "name": "delegation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.DelegationDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.DelegationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON or delegate",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/delegations/{id}": {
+            "delete": {
+                "description": "The principal can revoke a delegation and the delegate can give it up",
+                "tags": [
+                    "Delegations"
+                ],
+                "summary": "Revoke a delegation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delegation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Delegation revoked"
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Delegation not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/employees": {
+            "get": {
+                "description": "Returns a page of employees. An employee's bookings are listed by GET /bookings?employee_id=. The next page's cursor is returned in the X-Next-Cursor and Link headers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Employees"
+                ],
+                "summary": "Get list of employees",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated employee IDs",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Email address",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name contains",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "employee or admin",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only active or only deactivated employees",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Home floor",
+                        "name": "home_floor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, name, email or created_at; prefix with - for descending (default id)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from X-Next-Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.EmployeeResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/employees/{id}": {
+            "get": {
+                "description": "Retrieves employee details by ID, using a cache for faster responses. The employee's bookings are listed by GET /bookings?employee_id=.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Employees"
+                ],
+                "summary": "Get employee by ID (with cache)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Employee not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Error marshalling data",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Allows an authenticated employee to update their name, email, and password. Changing the password signs the employee out of all their other sessions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Employees"
+                ],
+                "summary": "Update an employee's own details",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated employee details (name, email, password)",
+                        "name": "employee",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Employee ID or JSON input",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized (not logged in)",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (trying to update another employee)",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Employee not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update employee",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/employees/{id}/bookings/transfer": {
+            "post": {
+                "description": "Makes another employee the organizer of every booking of the employee that has not started yet. Employees may transfer their own bookings; administrators may transfer anyone's.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Employees"
+                ],
+                "summary": "Transfer all future bookings of an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New organizer and optional reason",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BookingTransferDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferResultDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, JSON or new organizer",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Employee not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to load bookings",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/employees/{id}/deactivate": {
+            "post": {
+                "description": "Deactivates an employee (administrators only). Their future bookings are either transferred to another employee or cancelled, their delegations are removed, their Google token is revoked and affected attendees are notified. Deactivated employees are signed out and can no longer log in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Employees"
+                ],
+                "summary": "Offboard an employee",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Employee ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "What to do with future bookings",
+                        "name": "offboarding",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OffboardingDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferResultDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID, JSON, mode or new organizer",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Employee not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Employee is already deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to deactivate employee",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/google/login": {
+            "get": {
+                "description": "Redirects logged-in employee to Google OAuth consent screen for authentication",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Initiate Google OAuth login flow",
+                "responses": {
+                    "307": {
+                        "description": "Redirect to Google OAuth consent screen"
+                    },
+                    "401": {
+                        "description": "User not logged in",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create auth URL",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Answers as long as the process is able to serve HTTP. It does not look at the database or any other dependency; see /readyz for that.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticates employee by email and password and starts a session",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Log in an employee",
+                "parameters": [
+                    {
+                        "description": "Login credentials (email and password)",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CredentialsDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login successful message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Email not found or incorrect password",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Account is deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to start session",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Ends the employee's session on the server and clears the session cookie",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Log out the current employee",
+                "responses": {
+                    "200": {
+                        "description": "Logout successful message",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to end session",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "description": "Returns the employee the session belongs to: their profile, their roles, whether their Google Calendar is linked and when the session expires. Clients call it on start-up to find out whether they are signed in; /session-status is an alias.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Who is logged in",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not logged in",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth2callback": {
+            "get": {
+                "description": "Processes OAuth code and state, exchanges code for tokens, and stores them linked to the logged-in employee. The state must be the one issued by /google/login in the same session.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Handle Google OAuth callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "OAuth authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "OAuth state parameter",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Google Calendar authorization successful! You may close this tab.",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid code/state parameter",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "User not logged in",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Token exchange or database save failed",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Reports whether the server should get traffic: the database answers, its schema is up to date, the job scheduler runs and, when configured, the SMTP server is reachable. Fails as soon as a graceful shutdown starts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready; checks lists what failed",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Creates a new employee account with a hashed password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Register a new employee",
+                "parameters": [
+                    {
+                        "description": "Employee registration details",
+                        "name": "employee",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.EmployeeDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input or creation failed",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to hash password",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/reminder-settings": {
+            "get": {
+                "description": "Returns when and how the logged-in employee is reminded of the bookings they organize. Employees who never chose get one email ten minutes before the start.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminders"
+                ],
+                "summary": "Get my reminder settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReminderSettingsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces the reminder stages of the logged-in employee, e.g. a day, an hour and ten minutes before each booking, each by email and/or as a Google Calendar popup. An empty list turns reminders off. Calendar popups apply to events created from now on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reminders"
+                ],
+                "summary": "Choose my reminders",
+                "parameters": [
+                    {
+                        "description": "Reminder stages",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReminderSettingsDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReminderSettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON or stages",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms": {
+            "get": {
+                "description": "Retrieves a page of rooms. A room's bookings are listed by GET /bookings?room_id=. The next page's cursor is returned in the X-Next-Cursor and Link headers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rooms"
+                ],
+                "summary": "Get list of rooms",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated room IDs",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Location",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Floor",
+                        "name": "floor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum capacity",
+                        "name": "min_capacity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum capacity",
+                        "name": "max_capacity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only rooms that do or do not require approval",
+                        "name": "requires_approval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "id, name, location, floor, capacity or created_at; prefix with - for descending (default id)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated JSON fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from X-Next-Cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RoomResponse"
+                            }
+                        },
+                        "headers": {
+                            "X-Next-Cursor": {
+                                "type": "string",
+                                "description": "Cursor of the next page, absent on the last page"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, limit or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a new meeting room to the system. Administrators only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rooms"
+                ],
+                "summary": "Create a new room",
+                "parameters": [
+                    {
+                        "description": "Room details",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoomDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.RoomResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON or bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/suggestions": {
+            "post": {
+                "description": "Ranks room and time combinations within a window by capacity fit, attendee availability (bookings and optionally Google Calendar free/busy) and distance from the organizer's home floor",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rooms"
+                ],
+                "summary": "Suggest rooms and times for a meeting",
+                "parameters": [
+                    {
+                        "description": "Attendees, duration, window and required amenities",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoomSuggestionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/suggest.Option"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON or window",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}": {
+            "put": {
+                "description": "Update details of existing room by ID, including whether bookings need approval and by whom. Administrators only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rooms"
+                ],
+                "summary": "Update room details",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Room details to update",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoomUpdateDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RoomResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON or bad request",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/session-status": {
+            "get": {
+                "description": "Returns the employee the session belongs to: their profile, their roles, whether their Google Calendar is linked and when the session expires. Clients call it on start-up to find out whether they are signed in; /session-status is an alias.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Who is logged in",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Not logged in",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "description": "Returns the logged-in employee's active sessions, one per device or browser they signed in with, most recently used first. The session of this request is marked as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "List my sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SessionResponse"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "delete": {
+                "description": "Signs the logged-in employee out of the session, e.g. on a lost device. Revoking the current session is the same as logging out.",
+                "tags": [
+                    "Authentication"
+                ],
+                "summary": "Revoke one of my sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Session revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apierror.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Returns the version, git commit and build date of the running server.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Build information",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/version.Info"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "apierror.Body": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/validation.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "apierror.Response": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/apierror.Body"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "models.ApproverGroupDTO": {
+            "type": "object",
+            "properties": {
+                "member_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ApproverGroupResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EmployeeSummary"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingDTO": {
+            "type": "object",
+            "properties": {
+                "attendee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "employee_id": {
+                    "description": "EmployeeID is the organizer. Delegates may book for the employees who\ngranted them permission; it defaults to the logged-in employee.",
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "hold": {
+                    "type": "boolean"
+                },
+                "hold_minutes": {
+                    "type": "integer"
+                },
+                "num_attendees": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingGroupDTO": {
+            "type": "object",
+            "properties": {
+                "attendee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "employee_id": {
+                    "description": "EmployeeID is the organizer, defaulting to the logged-in employee.",
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "rooms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookingGroupRoomDTO"
+                    }
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingGroupResponse": {
+            "type": "object",
+            "properties": {
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookingResponse"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingGroupRoomDTO": {
+            "type": "object",
+            "properties": {
+                "num_attendees": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BookingHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "$ref": "#/definitions/models.BookingStatus"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "$ref": "#/definitions/models.BookingStatus"
+                }
+            }
+        },
+        "models.BookingResponse": {
+            "type": "object",
+            "properties": {
+                "attendees": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EmployeeSummary"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by_id": {
+                    "type": "integer"
+                },
+                "employee": {
+                    "$ref": "#/definitions/models.EmployeeSummary"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "num_attendees": {
+                    "type": "integer"
+                },
+                "room": {
+                    "$ref": "#/definitions/models.RoomSummary"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.BookingStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingStatus": {
+            "type": "string",
+            "enum": [
+                "tentative",
+                "pending_approval",
+                "confirmed",
+                "checked_in",
+                "completed",
+                "cancelled",
+                "no_show",
+                "released",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "BookingTentative",
+                "BookingPendingApproval",
+                "BookingConfirmed",
+                "BookingCheckedIn",
+                "BookingCompleted",
+                "BookingCancelled",
+                "BookingNoShow",
+                "BookingReleased",
+                "BookingRejected"
+            ]
+        },
+        "models.BookingTransferDTO": {
+            "type": "object",
+            "properties": {
+                "new_organizer_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BookingTransitionDTO": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.BookingStatus"
+                }
+            }
+        },
+        "models.BookingUpdateDTO": {
+            "type": "object",
+            "properties": {
+                "attendee_ids": {
+                    "description": "AttendeeIDs replaces the attendees when present.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "num_attendees": {
+                    "type": "integer"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CredentialsDTO": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DelegationDTO": {
+            "type": "object",
+            "properties": {
+                "can_book": {
+                    "type": "boolean"
+                },
+                "can_cancel": {
+                    "type": "boolean"
+                },
+                "can_edit": {
+                    "type": "boolean"
+                },
+                "delegate_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DelegationResponse": {
+            "type": "object",
+            "properties": {
+                "can_book": {
+                    "type": "boolean"
+                },
+                "can_cancel": {
+                    "type": "boolean"
+                },
+                "can_edit": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delegate_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "principal_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DelegationsResponse": {
+            "type": "object",
+            "properties": {
+                "granted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DelegationResponse"
+                    }
+                },
+                "received": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DelegationResponse"
+                    }
+                }
+            }
+        },
+        "models.EmployeeDTO": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "home_floor": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmployeeResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookingResponse"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deactivated_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "home_floor": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/models.EmployeeRole"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmployeeRole": {
+            "type": "string",
+            "enum": [
+                "employee",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "RoleEmployee",
+                "RoleAdmin"
+            ]
+        },
+        "models.EmployeeSummary": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JobResponse": {
+            "type": "object",
+            "properties": {
+                "average_duration_ms": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "last_duration_ms": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "last_finished_at": {
+                    "type": "string"
+                },
+                "last_processed": {
+                    "type": "integer"
+                },
+                "last_started_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_run_at": {
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "run_by": {
+                    "type": "string"
+                },
+                "runs": {
+                    "type": "integer"
+                },
+                "schedule": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.JobStatus"
+                }
+            }
+        },
+        "models.JobStatus": {
+            "type": "string",
+            "enum": [
+                "idle",
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "JobIdle",
+                "JobRunning",
+                "JobSucceeded",
+                "JobFailed"
+            ]
+        },
+        "models.MeResponse": {
+            "type": "object",
+            "properties": {
+                "employee": {
+                    "$ref": "#/definitions/models.EmployeeResponse"
+                },
+                "employee_id": {
+                    "type": "integer"
+                },
+                "google_calendar_linked": {
+                    "type": "boolean"
+                },
+                "roles": {
+                    "description": "Roles are \"employee\", \"admin\" for administrators and \"approver\" for\nmembers of an approver group.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "employee",
+                        "approver"
+                    ]
+                },
+                "session_expires_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OffboardingDTO": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "description": "Mode is \"transfer\" or \"cancel\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.OffboardingMode"
+                        }
+                    ]
+                },
+                "new_organizer_id": {
+                    "description": "NewOrganizerID receives the future bookings when Mode is \"transfer\".",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OffboardingMode": {
+            "type": "string",
+            "enum": [
+                "transfer",
+                "cancel"
+            ],
+            "x-enum-varnames": [
+                "OffboardTransfer",
+                "OffboardCancel"
+            ]
+        },
+        "models.ReminderChannel": {
+            "type": "string",
+            "enum": [
+                "email",
+                "calendar"
+            ],
+            "x-enum-varnames": [
+                "ReminderEmail",
+                "ReminderCalendar"
+            ]
+        },
+        "models.ReminderSettingsDTO": {
+            "type": "object",
+            "properties": {
+                "stages": {
+                    "description": "Stages replaces the current stages; an empty list turns reminders off.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReminderStage"
+                    }
+                }
+            }
+        },
+        "models.ReminderSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "description": "Default is true while the employee has not chosen their own stages.",
+                    "type": "boolean"
+                },
+                "stages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReminderStage"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReminderStage": {
+            "type": "object",
+            "properties": {
+                "channels": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReminderChannel"
+                    }
+                },
+                "minutes_before": {
+                    "type": "integer",
+                    "example": 60
+                }
+            }
+        },
+        "models.RoomDTO": {
+            "type": "object",
+            "properties": {
+                "amenities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "approver_group_id": {
+                    "type": "integer"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "floor": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "requires_approval": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.RoomResponse": {
+            "type": "object",
+            "properties": {
+                "amenities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "approver_group_id": {
+                    "type": "integer"
+                },
+                "bookings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BookingResponse"
+                    }
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "floor": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "requires_approval": {
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RoomSuggestionDTO": {
+            "type": "object",
+            "properties": {
+                "amenities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "attendee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "num_attendees": {
+                    "description": "NumAttendees overrides the head count derived from attendee_ids.",
+                    "type": "integer"
+                },
+                "use_google_calendar": {
+                    "type": "boolean"
+                },
+                "window_end": {
+                    "type": "string"
+                },
+                "window_start": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RoomSummary": {
+            "type": "object",
+            "properties": {
+                "floor": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RoomUpdateDTO": {
+            "type": "object",
+            "properties": {
+                "amenities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "approver_group_id": {
+                    "type": "integer"
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "floor": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "requires_approval": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.SessionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TransferResultDTO": {
+            "type": "object",
+            "properties": {
+                "booking_groups": {
+                    "type": "integer"
+                },
+                "bookings": {
+                    "type": "integer"
+                },
+                "cancelled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "suggest.Option": {
+            "type": "object",
+            "properties": {
+                "busy_attendee_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "capacity": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "floor": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "room_name": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "validation.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "version.Info": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string",
+                    "example": "83c5f30e1d2c"
+                },
+                "date": {
+                    "type": "string",
+                    "example": "2026-10-19T08:00:00Z"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.24.4"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1.4.0"
                 }
             }
         }
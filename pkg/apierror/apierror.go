@@ -0,0 +1,104 @@
+// Package apierror writes error responses in the JSON envelope shared by every
+// endpoint:
+//
+//	{"error": {"code": "validation_failed", "message": "...", "details": [{"field": "email", "code": "invalid_email", "message": "..."}]}}
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+)
+
+// Machine-readable error codes.
+const (
+	CodeBadRequest        = "bad_request"
+	CodeInvalidJSON       = "invalid_json"
+	CodeValidation        = "validation_failed"
+	CodeUnauthorized      = "unauthorized"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeConflict          = "conflict"
+	CodeBookingConflict   = "booking_conflict"
+	CodeCapacityExceeded  = "capacity_exceeded"
+	CodeInvalidTransition = "invalid_transition"
+	CodeStale             = "stale_resource"
+	CodeInternal          = "internal_error"
+	CodeBadGateway        = "bad_gateway"
+)
+
+// Body is the content of the "error" member of an error response.
+// swagger:model Error
+type Body struct {
+	Code    string                  `json:"code"`
+	Message string                  `json:"message"`
+	Details []validation.FieldError `json:"details,omitempty"`
+}
+
+// Response is the JSON envelope of every error response.
+type Response struct {
+	Error Body `json:"error"`
+}
+
+// Error replies with the message and a code derived from the status, as a
+// drop-in replacement for http.Error.
+func Error(w http.ResponseWriter, message string, status int) {
+	Write(w, status, CodeFor(status), message, nil)
+}
+
+// Write replies with an error envelope.
+func Write(w http.ResponseWriter, status int, code, message string, details []validation.FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Response{Error: Body{Code: code, Message: message, Details: details}})
+}
+
+// InvalidJSON replies that the request body could not be parsed.
+func InvalidJSON(w http.ResponseWriter, err error) {
+	message := "Invalid JSON"
+	if err != nil {
+		message += ": " + err.Error()
+	}
+	Write(w, http.StatusBadRequest, CodeInvalidJSON, message, nil)
+}
+
+// Validation replies with the field errors of a failed Validate call.
+func Validation(w http.ResponseWriter, err error) {
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		Write(w, http.StatusBadRequest, CodeValidation, "Request validation failed", fields)
+		return
+	}
+	Write(w, http.StatusBadRequest, CodeValidation, err.Error(), nil)
+}
+
+// Field replies that a single field of the request body is invalid.
+func Field(w http.ResponseWriter, field, code, message string) {
+	Write(w, http.StatusBadRequest, CodeValidation, "Request validation failed",
+		[]validation.FieldError{{Field: field, Code: code, Message: message}})
+}
+
+// CodeFor returns the generic code of an HTTP status.
+func CodeFor(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusBadGateway:
+		return CodeBadGateway
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
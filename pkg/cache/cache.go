@@ -2,15 +2,17 @@ package cache
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"strconv"
 	"time"
 
+	"github.com/koushikidey/go-meetingroombook/pkg/metrics"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
 	"github.com/patrickmn/go-cache"
 )
 
-type allCache struct {
+// Cache keeps recently read employees in memory.
+type Cache struct {
 	employees *cache.Cache
 }
 
@@ -19,21 +21,22 @@ const (
 	purgeTime             = 10 * time.Minute
 )
 
-var C *allCache
-
-func InitCache() {
-	C = &allCache{
+func New() *Cache {
+	return &Cache{
 		employees: cache.New(defaultExpirationTime, purgeTime),
 	}
 }
 
-func (c *allCache) Read(id uint) ([]byte, bool) {
+func (c *Cache) Read(id uint) ([]byte, bool) {
 	employee, ok := c.employees.Get(strconv.FormatUint(uint64(id), 10))
-	if ok {
-		log.Println("data fetched from cache")
-		res, err := json.Marshal(employee.(models.Employee))
+	if !ok {
+		metrics.CacheRequests.WithLabelValues("employees", "miss").Inc()
+	} else {
+		metrics.CacheRequests.WithLabelValues("employees", "hit").Inc()
+		slog.Debug("Employee read from cache", "employee_id", id)
+		res, err := json.Marshal(models.NewEmployeeResponse(employee.(models.Employee)))
 		if err != nil {
-			log.Println("error in cache marshal:", err)
+			slog.Error("Failed to encode cached employee", "employee_id", id, "error", err)
 			return nil, false
 		}
 		return res, true
@@ -41,6 +44,9 @@ func (c *allCache) Read(id uint) ([]byte, bool) {
 	return nil, false
 }
 
-func (c *allCache) Update(id uint, employee models.Employee) {
+func (c *Cache) Update(id uint, employee models.Employee) {
 	c.employees.Set(strconv.FormatUint(uint64(id), 10), employee, cache.DefaultExpiration)
-}
\ No newline at end of file
+}
+func (c *Cache) Delete(id uint) {
+	c.employees.Delete(strconv.FormatUint(uint64(id), 10))
+}
@@ -1,19 +1,22 @@
 package config
 
 import (
-	"log"
-
-	"os"
+	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/joho/godotenv"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func init() {
 	if err := godotenv.Load(); err != nil {
-		log.Println("Info: .env file not loaded, using system environment variables")
+		slog.Info(".env file not loaded, using system environment variables")
 	}
 	// err := godotenv.Load(".env")
 	// if err != nil {
@@ -21,25 +24,55 @@ func init() {
 	// }
 }
 
-var (
-	db *gorm.DB
-)
-
-func GetDB() *gorm.DB {
-	return db
+// PromoteAdmins makes the employees with the given emails administrators.
+func PromoteAdmins(db *gorm.DB, emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	return db.Model(&models.Employee{}).Where("email IN ?", emails).Update("role", models.RoleAdmin).Error
 }
 
-func MigrateDB(db *gorm.DB) {
-	db.AutoMigrate(&models.Room{}, &models.Employee{}, &models.Booking{}, &models.GoogleToken{})
+// Dialector returns the gorm dialector for a database driver: "mysql"
+// (the default), "postgres" or "sqlite".
+func Dialector(driver, dsn string) (gorm.Dialector, error) {
+	switch strings.ToLower(driver) {
+	case "", "mysql":
+		return mysql.Open(dsn), nil
+	case "postgres", "postgresql":
+		return postgres.Open(dsn), nil
+	case "sqlite", "sqlite3":
+		return sqlite.Open(dsn), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
 }
-func Connect() {
-	dsn := os.Getenv("DB_DSN")
 
-	d, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// Open opens the database without touching its schema.
+func Open(d DatabaseConfig) (*gorm.DB, error) {
+	dialector, err := Dialector(d.Driver, d.DSN)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{})
 	if err != nil {
-		log.Fatalf("Could not connect to the database: %v", err)
+		return nil, fmt.Errorf("could not connect to the database: %w", err)
 	}
+	return db, nil
+}
 
-	db = d
-	MigrateDB(db)
-}
\ No newline at end of file
+// Connect opens the database and refuses to continue unless its schema is
+// at the version this build expects; migrations are applied separately with
+// the migrate command. It is meant to be called once at startup; the
+// connection is then passed to whoever needs it.
+func Connect(c Config) (*gorm.DB, error) {
+	db, err := Open(c.Database)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrations.Check(db); err != nil {
+		return nil, err
+	}
+	if err := PromoteAdmins(db, c.AdminEmails); err != nil {
+		slog.Error("Failed to promote administrators", "error", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,431 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+)
+
+// Config is everything the application can be configured with. Load fills
+// it from, in increasing order of precedence, the defaults, a JSON file, the
+// environment and command-line flags.
+type Config struct {
+	Server   ServerConfig   `json:"server"`
+	Database DatabaseConfig `json:"database"`
+	Session  SessionConfig  `json:"session"`
+	Email    EmailConfig    `json:"email"`
+	Google   GoogleConfig   `json:"google"`
+	Approval ApprovalConfig `json:"approval"`
+	Jobs     JobsConfig     `json:"jobs"`
+	Log      LogConfig      `json:"log"`
+	Tracing  TracingConfig  `json:"tracing"`
+	// AdminEmails are promoted to administrators at startup.
+	AdminEmails []string `json:"admin_emails"`
+}
+
+type ServerConfig struct {
+	Addr string `json:"addr"`
+	// BaseURL is where employees reach the app, used in emailed links.
+	BaseURL     string `json:"base_url"`
+	FrontendDir string `json:"frontend_dir"`
+	// ReadTimeout and WriteTimeout bound reading a request and writing its
+	// response; IdleTimeout closes unused keep-alive connections.
+	ReadTimeout  Duration `json:"read_timeout"`
+	WriteTimeout Duration `json:"write_timeout"`
+	IdleTimeout  Duration `json:"idle_timeout"`
+	// ShutdownTimeout is how long shutdown may take in total: draining
+	// requests, finishing jobs and flushing queued emails.
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	// DrainDelay is how long, at the start of a shutdown, the server keeps
+	// serving while reporting itself not ready, so load balancers stop
+	// sending requests before it stops accepting them.
+	DrainDelay Duration `json:"drain_delay"`
+}
+
+type DatabaseConfig struct {
+	Driver string `json:"driver"`
+	DSN    string `json:"dsn"`
+}
+
+type SessionConfig struct {
+	// Key signs the session cookie. It must be at least 32 bytes.
+	Key string `json:"key"`
+	// PreviousKeys are keys Key replaced. Cookies signed with them are still
+	// accepted, so rotating the key does not sign everybody out.
+	PreviousKeys []string `json:"previous_keys"`
+	// Secure limits the session cookie to HTTPS.
+	Secure bool `json:"secure"`
+	// IdleTimeout ends sessions that were not used for that long;
+	// AbsoluteTimeout ends them that long after sign-in regardless.
+	IdleTimeout     Duration `json:"idle_timeout"`
+	AbsoluteTimeout Duration `json:"absolute_timeout"`
+	// Store is where sessions are kept: database, or redis at RedisURL,
+	// e.g. redis://:password@localhost:6379/0.
+	Store    string `json:"store"`
+	RedisURL string `json:"redis_url"`
+}
+
+type EmailConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	// ReadyCheck makes the server report itself not ready while the SMTP
+	// server cannot be reached. Emails are queued either way.
+	ReadyCheck bool `json:"ready_check"`
+}
+
+type GoogleConfig struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RedirectURL  string `json:"redirect_url"`
+}
+
+type ApprovalConfig struct {
+	// Secret signs the approve/reject links emailed to approvers.
+	Secret string `json:"secret"`
+	// Timeout is how long approvers have to answer a request.
+	Timeout Duration `json:"timeout"`
+}
+
+type JobsConfig struct {
+	// NoShowGrace is how long after the start a confirmed booking that
+	// nobody checked in to is released.
+	NoShowGrace Duration `json:"no_show_grace"`
+	// Retention is how long sent emails and reminder records are kept.
+	Retention Duration `json:"retention"`
+}
+
+type LogConfig struct {
+	// Level is the least severe level logged: debug, info, warn or error.
+	Level string `json:"level"`
+	// Format is text for logfmt-style lines or json for one object per line.
+	Format string `json:"format"`
+}
+
+type TracingConfig struct {
+	// Exporter is where spans go: none, or otlp to send them to the
+	// collector set by the standard OTEL_EXPORTER_OTLP_* variables.
+	// Sampling follows OTEL_TRACES_SAMPLER and the service name
+	// OTEL_SERVICE_NAME.
+	Exporter string `json:"exporter"`
+}
+
+// Duration is a time.Duration written as "90m" or "24h" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the configuration used for anything not set explicitly.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:            "localhost:9010",
+			BaseURL:         "http://localhost:9010",
+			FrontendDir:     "frontend",
+			ReadTimeout:     Duration(15 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			IdleTimeout:     Duration(time.Minute),
+			ShutdownTimeout: Duration(30 * time.Second),
+		},
+		Database: DatabaseConfig{Driver: "mysql"},
+		Session:  SessionConfig{IdleTimeout: Duration(2 * time.Hour), AbsoluteTimeout: Duration(24 * time.Hour), Store: "database"},
+		Email:    EmailConfig{Host: "smtp.gmail.com", Port: 587},
+		Approval: ApprovalConfig{Timeout: Duration(24 * time.Hour)},
+		Jobs:     JobsConfig{NoShowGrace: Duration(15 * time.Minute), Retention: Duration(90 * 24 * time.Hour)},
+		Log:      LogConfig{Level: "info", Format: "text"},
+		Tracing:  TracingConfig{Exporter: "none"},
+	}
+}
+
+// binding ties a setting to its environment variable and flag.
+type binding struct {
+	env    string
+	flag   string
+	usage  string
+	target interface{}
+}
+
+func (c *Config) bindings() []binding {
+	return []binding{
+		{"ADDR", "addr", "address the HTTP server listens on", &c.Server.Addr},
+		{"APP_BASE_URL", "base-url", "public URL of the app, used in emailed links", &c.Server.BaseURL},
+		{"FRONTEND_DIR", "frontend-dir", "directory with the frontend's index.html", &c.Server.FrontendDir},
+		{"SERVER_READ_TIMEOUT", "read-timeout", "maximum time to read a request", &c.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", "write-timeout", "maximum time to write a response", &c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", "idle-timeout", "how long idle keep-alive connections stay open", &c.Server.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long a graceful shutdown may take", &c.Server.ShutdownTimeout},
+		{"SHUTDOWN_DRAIN_DELAY", "shutdown-drain-delay", "how long readiness fails before the server stops accepting requests", &c.Server.DrainDelay},
+		{"DB_DRIVER", "db-driver", "database driver: mysql, postgres or sqlite", &c.Database.Driver},
+		{"DB_DSN", "db-dsn", "database connection string", &c.Database.DSN},
+		{"SESSION_KEY", "session-key", "key signing session cookies, at least 32 bytes", &c.Session.Key},
+		{"SESSION_PREVIOUS_KEYS", "session-previous-keys", "comma-separated keys that signed session cookies before SESSION_KEY", &c.Session.PreviousKeys},
+		{"SESSION_SECURE", "session-secure", "send the session cookie over HTTPS only", &c.Session.Secure},
+		{"SESSION_IDLE_TIMEOUT", "session-idle-timeout", "how long unused sessions last, e.g. 2h", &c.Session.IdleTimeout},
+		{"SESSION_ABSOLUTE_TIMEOUT", "session-absolute-timeout", "how long sessions last after sign-in, e.g. 24h", &c.Session.AbsoluteTimeout},
+		{"SESSION_STORE", "session-store", "where sessions are kept: database or redis", &c.Session.Store},
+		{"SESSION_REDIS_URL", "session-redis-url", "Redis URL for the redis session store", &c.Session.RedisURL},
+		{"EMAIL_HOST", "email-host", "SMTP server", &c.Email.Host},
+		{"EMAIL_PORT", "email-port", "SMTP port", &c.Email.Port},
+		{"EMAIL_USER", "email-user", "SMTP user and sender address", &c.Email.User},
+		{"EMAIL_PASS", "email-pass", "SMTP password", &c.Email.Password},
+		{"EMAIL_READY_CHECK", "email-ready-check", "report not ready while the SMTP server is unreachable", &c.Email.ReadyCheck},
+		{"GOOGLE_CLIENT_ID", "google-client-id", "Google OAuth client ID", &c.Google.ClientID},
+		{"GOOGLE_CLIENT_SECRET", "google-client-secret", "Google OAuth client secret", &c.Google.ClientSecret},
+		{"GOOGLE_REDIRECT_URL", "google-redirect-url", "Google OAuth redirect URL", &c.Google.RedirectURL},
+		{"APPROVAL_SECRET", "approval-secret", "key signing approval links", &c.Approval.Secret},
+		{"APPROVAL_TIMEOUT", "approval-timeout", "how long approvers have to answer, e.g. 24h", &c.Approval.Timeout},
+		{"NO_SHOW_GRACE", "no-show-grace", "how long after the start unattended bookings are released", &c.Jobs.NoShowGrace},
+		{"DATA_RETENTION", "data-retention", "how long sent emails and reminders are kept, e.g. 2160h", &c.Jobs.Retention},
+		{"LOG_LEVEL", "log-level", "least severe level logged: debug, info, warn or error", &c.Log.Level},
+		{"LOG_FORMAT", "log-format", "log format: text or json", &c.Log.Format},
+		{"TRACING_EXPORTER", "tracing-exporter", "where traces are sent: none or otlp", &c.Tracing.Exporter},
+		{"ADMIN_EMAILS", "admin-emails", "comma-separated emails of administrators", &c.AdminEmails},
+	}
+}
+
+// set parses value into the binding's target.
+func (b binding) set(value string) error {
+	switch target := b.target.(type) {
+	case *string:
+		*target = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", b.env, value)
+		}
+		*target = n
+	case *bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not true or false", b.env, value)
+		}
+		*target = v
+	case *Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a duration", b.env, value)
+		}
+		*target = Duration(d)
+	case *[]string:
+		*target = utils.SplitList(value)
+	default:
+		return fmt.Errorf("%s: unsupported setting type %T", b.env, b.target)
+	}
+	return nil
+}
+
+// Load builds the configuration from the flags in args and the environment,
+// and returns the arguments left after the flags. The JSON file is named by
+// -config or CONFIG_FILE.
+func Load(args []string) (Config, []string, error) {
+	c := Default()
+	bindings := c.bindings()
+
+	fs := flag.NewFlagSet("meetingroombook", flag.ContinueOnError)
+	file := fs.String("config", os.Getenv("CONFIG_FILE"), "JSON configuration file")
+	// Flags are applied last, once the file and the environment are loaded.
+	var flagged []func() error
+	for _, b := range bindings {
+		b := b
+		fs.Func(b.flag, b.usage, func(value string) error {
+			flagged = append(flagged, func() error { return b.set(value) })
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return c, nil, err
+	}
+
+	if *file != "" {
+		raw, err := os.ReadFile(*file)
+		if err != nil {
+			return c, nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return c, nil, fmt.Errorf("invalid config file %s: %w", *file, err)
+		}
+	}
+	for _, b := range bindings {
+		if value, ok := os.LookupEnv(b.env); ok && value != "" {
+			if err := b.set(value); err != nil {
+				return c, nil, err
+			}
+		}
+	}
+	for _, apply := range flagged {
+		if err := apply(); err != nil {
+			return c, nil, err
+		}
+	}
+	return c, fs.Args(), nil
+}
+
+// Validate reports every problem that would stop the server from working.
+func (c Config) Validate() error {
+	var errs []error
+	problem := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if err := c.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Server.Addr == "" {
+		problem("ADDR is required")
+	}
+	if c.Server.BaseURL == "" {
+		problem("APP_BASE_URL is required")
+	}
+	timeouts := []struct {
+		name  string
+		value Duration
+	}{
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			problem("%s must be positive", timeout.name)
+		}
+	}
+	if c.Server.DrainDelay < 0 {
+		problem("SHUTDOWN_DRAIN_DELAY must not be negative")
+	} else if c.Server.DrainDelay >= c.Server.ShutdownTimeout {
+		problem("SHUTDOWN_DRAIN_DELAY must be shorter than SHUTDOWN_TIMEOUT")
+	}
+	if c.Session.Key == "" {
+		problem("SESSION_KEY is required")
+	} else if len(c.Session.Key) < 32 {
+		problem("SESSION_KEY must be at least 32 bytes")
+	}
+	for _, key := range c.Session.PreviousKeys {
+		if len(key) < 32 {
+			problem("SESSION_PREVIOUS_KEYS must be at least 32 bytes each")
+			break
+		}
+	}
+	if c.Session.IdleTimeout <= 0 || c.Session.AbsoluteTimeout <= 0 {
+		problem("SESSION_IDLE_TIMEOUT and SESSION_ABSOLUTE_TIMEOUT must be positive")
+	}
+	switch strings.ToLower(c.Session.Store) {
+	case "database":
+	case "redis":
+		if c.Session.RedisURL == "" {
+			problem("SESSION_REDIS_URL is required for the redis session store")
+		}
+	default:
+		problem("SESSION_STORE must be database or redis")
+	}
+	if c.Approval.Secret == "" {
+		problem("APPROVAL_SECRET is required")
+	}
+	if c.Approval.Timeout <= 0 {
+		problem("APPROVAL_TIMEOUT must be positive")
+	}
+	if c.Jobs.NoShowGrace <= 0 {
+		problem("NO_SHOW_GRACE must be positive")
+	}
+	if c.Jobs.Retention < Duration(24*time.Hour) {
+		problem("DATA_RETENTION must be at least 24h")
+	}
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		problem("LOG_LEVEL must be debug, info, warn or error")
+	}
+	if format := strings.ToLower(c.Log.Format); format != "text" && format != "json" {
+		problem("LOG_FORMAT must be text or json")
+	}
+	if exporter := strings.ToLower(c.Tracing.Exporter); exporter != "none" && exporter != "otlp" {
+		problem("TRACING_EXPORTER must be none or otlp")
+	}
+	if c.Email.User != "" && c.Email.Password == "" {
+		problem("EMAIL_PASS is required when EMAIL_USER is set")
+	}
+	if c.Email.Port <= 0 || c.Email.Port > 65535 {
+		problem("EMAIL_PORT must be between 1 and 65535")
+	}
+	if c.Google.ClientID != "" && (c.Google.ClientSecret == "" || c.Google.RedirectURL == "") {
+		problem("GOOGLE_CLIENT_SECRET and GOOGLE_REDIRECT_URL are required when GOOGLE_CLIENT_ID is set")
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks the settings needed to reach the database, which is all
+// the migrate command uses.
+func (d DatabaseConfig) Validate() error {
+	if _, err := Dialector(d.Driver, d.DSN); err != nil {
+		return err
+	}
+	if d.DSN == "" {
+		return errors.New("DB_DSN is required")
+	}
+	return nil
+}
+
+const redacted = "[redacted]"
+
+// Redacted returns a copy with the secrets masked, safe to log.
+func (c Config) Redacted() Config {
+	mask := func(s *string) {
+		if *s != "" {
+			*s = redacted
+		}
+	}
+	mask(&c.Database.DSN)
+	mask(&c.Session.Key)
+	mask(&c.Session.RedisURL)
+	if len(c.Session.PreviousKeys) > 0 {
+		keys := make([]string, len(c.Session.PreviousKeys))
+		for i := range keys {
+			keys[i] = redacted
+		}
+		c.Session.PreviousKeys = keys
+	}
+	mask(&c.Email.Password)
+	mask(&c.Google.ClientSecret)
+	mask(&c.Approval.Secret)
+	return c
+}
+
+// Dump writes the redacted configuration as indented JSON.
+func (c Config) Dump(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c.Redacted())
+}
+
+// String is the redacted configuration on a single line.
+func (c Config) String() string {
+	var b strings.Builder
+	if err := json.NewEncoder(&b).Encode(c.Redacted()); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(file, []byte(`{
+		"server": {"addr": "file:1"},
+		"database": {"driver": "postgres", "dsn": "from-file"},
+		"approval": {"timeout": "2h"}
+	}`), 0o600)
+	assert.NoError(t, err)
+	t.Setenv("CONFIG_FILE", file)
+	t.Setenv("DB_DSN", "from-env")
+	t.Setenv("ADDR", "env:2")
+	t.Setenv("ADMIN_EMAILS", "a@example.com, b@example.com")
+
+	cfg, rest, err := Load([]string{"-addr", "flag:3", "status"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status"}, rest)
+	assert.Equal(t, "flag:3", cfg.Server.Addr)
+	assert.Equal(t, "from-env", cfg.Database.DSN)
+	assert.Equal(t, "postgres", cfg.Database.Driver)
+	assert.Equal(t, Duration(2*time.Hour), cfg.Approval.Timeout)
+	assert.Equal(t, []string{"a@example.com", "b@example.com"}, cfg.AdminEmails)
+	// Untouched settings keep their defaults.
+	assert.Equal(t, 587, cfg.Email.Port)
+}
+
+func TestLoadRejectsMalformedValues(t *testing.T) {
+	t.Setenv("APPROVAL_TIMEOUT", "soon")
+	_, _, err := Load(nil)
+	assert.ErrorContains(t, err, "APPROVAL_TIMEOUT")
+}
+
+func TestValidateReportsMissingSecrets(t *testing.T) {
+	err := Default().Validate()
+	assert.ErrorContains(t, err, "DB_DSN is required")
+	assert.ErrorContains(t, err, "SESSION_KEY is required")
+	assert.ErrorContains(t, err, "APPROVAL_SECRET is required")
+
+	cfg := Default()
+	cfg.Database.DSN = "user:pass@/rooms"
+	cfg.Session.Key = "0123456789abcdef0123456789abcdef"
+	cfg.Approval.Secret = "approval"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Session.Key = "short"
+	cfg.Database.Driver = "oracle"
+	cfg.Log = LogConfig{Level: "verbose", Format: "xml"}
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Session.Store = "redis"
+	err = cfg.Validate()
+	assert.ErrorContains(t, err, "at least 32 bytes")
+	assert.ErrorContains(t, err, "unsupported database driver")
+	assert.ErrorContains(t, err, "LOG_LEVEL")
+	assert.ErrorContains(t, err, "LOG_FORMAT")
+	assert.ErrorContains(t, err, "TRACING_EXPORTER")
+	assert.ErrorContains(t, err, "SESSION_REDIS_URL is required")
+}
+
+func TestDumpRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Database.DSN = "user:hunter2@/rooms"
+	cfg.Session.Key = "session-secret"
+	cfg.Session.PreviousKeys = []string{"retired-secret"}
+	cfg.Session.RedisURL = "redis://:redis-secret@cache:6379/0"
+	cfg.Email.Password = "mail-secret"
+	cfg.Approval.Secret = "approval-secret"
+
+	var out bytes.Buffer
+	assert.NoError(t, cfg.Dump(&out))
+	for _, secret := range []string{"hunter2", "session-secret", "retired-secret", "redis-secret", "mail-secret", "approval-secret"} {
+		assert.NotContains(t, out.String(), secret)
+		assert.NotContains(t, cfg.String(), secret)
+	}
+	assert.Contains(t, out.String(), `"timeout": "24h0m0s"`)
+	assert.Equal(t, "session-secret", cfg.Session.Key, "the original is left intact")
+	assert.Equal(t, []string{"retired-secret"}, cfg.Session.PreviousKeys)
+}
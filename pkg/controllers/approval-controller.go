@@ -0,0 +1,342 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/csrf"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+)
+
+// requestApproval tells the organizer that the booking is awaiting approval and
+// emails every member of the room's approver group signed approve/reject links.
+func (h *Handler) requestApproval(ctx context.Context, booking models.Booking, room models.Room, employee models.Employee) {
+	message := fmt.Sprintf("Hi %s,\n\nYour booking of %s from %s to %s is awaiting approval. You will be notified once it has been reviewed.",
+		employee.Name, room.Name, booking.StartTime, booking.EndTime)
+	h.sendEmail(ctx, employee.Email, "Meeting Room Booking Pending Approval", message)
+	h.notifyApprovers(ctx, booking, room, employee)
+}
+
+// notifyApprovers emails every member of the room's approver group signed
+// approve/reject links for the booking.
+func (h *Handler) notifyApprovers(ctx context.Context, booking models.Booking, room models.Room, employee models.Employee) {
+	if room.ApproverGroupID == nil {
+		logging.FromContext(ctx).Warn("Room requires approval but has no approver group", "room_id", room.ID)
+		return
+	}
+	group, err := h.repos.ApproverGroups.Get(*room.ApproverGroupID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to load approver group", "approver_group_id", *room.ApproverGroupID, "error", err)
+		return
+	}
+
+	links := ""
+	approveToken, err := utils.SignApprovalToken(h.approvalSecret, booking.ID, "approve", booking.ApprovalRequest(), *booking.ExpiresAt)
+	if err == nil {
+		rejectToken, _ := utils.SignApprovalToken(h.approvalSecret, booking.ID, "reject", booking.ApprovalRequest(), *booking.ExpiresAt)
+		links = fmt.Sprintf("\n\nApprove: %s/bookings/%d/approve?token=%s\nReject: %s/bookings/%d/reject?token=%s",
+			h.baseURL, booking.ID, approveToken, h.baseURL, booking.ID, rejectToken)
+	} else {
+		logging.FromContext(ctx).Error("Approval links not generated", "booking_id", booking.ID, "error", err)
+	}
+
+	for _, approver := range group.Members {
+		message := fmt.Sprintf("Hi %s,\n\n%s has requested %s from %s to %s for %d attendees. The request expires at %s.%s",
+			approver.Name, employee.Name, room.Name, booking.StartTime, booking.EndTime,
+			booking.NumAttendees, booking.ExpiresAt.Format(time.RFC1123), links)
+		h.sendEmail(ctx, approver.Email, "Meeting Room Approval Requested", message)
+	}
+}
+
+// CreateApproverGroup godoc
+// @Summary Create an approver group
+// @Description Creates a group of employees who can approve bookings for restricted rooms. Administrators only.
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Param group body models.ApproverGroupDTO true "Group name and member employee IDs"
+// @Success 201 {object} models.ApproverGroupResponse
+// @Failure 400 {object} apierror.Response "Invalid JSON or unknown members"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /approver-groups [post]
+func (h *Handler) CreateApproverGroup(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var input models.ApproverGroupDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	members, err := h.repos.Employees.FindAll(input.MemberIDs)
+	if err != nil {
+		apierror.Field(w, "member_ids", validation.CodeNotFound, "one or more members do not exist")
+		return
+	}
+
+	group := models.ApproverGroup{Name: input.Name, Members: members}
+	if err := h.repos.ApproverGroups.Create(&group); err != nil {
+		apierror.Error(w, "Could not create approver group", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.NewApproverGroupResponse(group))
+}
+
+// GetApproverGroups godoc
+// @Summary Get all approver groups
+// @Description Returns approver groups with their members
+// @Tags Approvals
+// @Produce json
+// @Success 200 {array} models.ApproverGroupResponse
+// @Router /approver-groups [get]
+func (h *Handler) GetApproverGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.repos.ApproverGroups.All()
+	if err != nil {
+		apierror.Error(w, "Failed to fetch approver groups", http.StatusInternalServerError)
+		return
+	}
+	resp, _ := json.Marshal(models.NewApproverGroupResponses(groups))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// GetPendingApprovals godoc
+// @Summary List bookings awaiting my approval
+// @Description Returns pending bookings for rooms whose approver group includes the logged-in employee
+// @Tags Approvals
+// @Produce json
+// @Success 200 {array} models.BookingResponse
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Router /approvals [get]
+func (h *Handler) GetPendingApprovals(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	bookings, err := h.repos.Bookings.PendingFor(employeeID)
+	if err != nil {
+		apierror.Error(w, "Failed to fetch pending approvals", http.StatusInternalServerError)
+		return
+	}
+	resp, _ := json.Marshal(models.NewBookingResponses(bookings))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+var approvalPage = template.Must(template.New("approval").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="UTF-8" /><title>Booking approval</title></head>
+<body>
+  <h2>{{.Title}} booking #{{.ID}}</h2>
+  <form method="POST" action="/bookings/{{.ID}}/{{.Action}}">
+    <input type="hidden" name="token" value="{{.Token}}" />
+    <input type="hidden" name="csrf_token" value="{{.CSRFToken}}" />
+    {{if eq .Action "reject"}}<input type="text" name="reason" placeholder="Reason (optional)" />{{end}}
+    <button type="submit">{{.Title}}</button>
+  </form>
+</body>
+</html>`))
+
+// ApprovalPage godoc
+// @Summary Show the approve/reject confirmation page
+// @Description Landing page for the signed links in approval emails. The decision is submitted with a POST so that link scanners cannot approve bookings.
+// @Tags Approvals
+// @Produce html
+// @Param id path int true "Booking ID"
+// @Param token query string true "Signed approval token"
+// @Success 200 {string} string "HTML confirmation form"
+// @Failure 400 {object} apierror.Response "Invalid or expired token, or one for an earlier request"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Router /bookings/{id}/approve [get]
+// @Router /bookings/{id}/reject [get]
+func (h *Handler) ApprovalPage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+	action := "approve"
+	if strings.HasSuffix(r.URL.Path, "/reject") {
+		action = "reject"
+	}
+	booking, err := h.repos.Bookings.Get(uint(id))
+	if err != nil {
+		apierror.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	token := r.URL.Query().Get("token")
+	if err := utils.VerifyApprovalToken(h.approvalSecret, token, booking.ID, action, booking.ApprovalRequest()); err != nil {
+		apierror.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	csrfToken, err := csrf.Token(w, r, h.sessions)
+	if err != nil {
+		apierror.Error(w, "Failed to create CSRF token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	approvalPage.Execute(w, map[string]interface{}{
+		"ID":        id,
+		"Action":    action,
+		"Title":     strings.ToUpper(action[:1]) + action[1:],
+		"Token":     token,
+		"CSRFToken": csrfToken,
+	})
+}
+
+// ApproveBooking godoc
+// @Summary Approve a pending booking
+// @Description Confirms a booking for a restricted room. Callers must be in the room's approver group or present a signed token from the approval email.
+// @Tags Approvals
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param token formData string false "Signed approval token"
+// @Success 200 {object} models.BookingResponse
+// @Failure 400 {object} apierror.Response "Invalid booking ID"
+// @Failure 403 {object} apierror.Response "Not an approver for this room"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Failure 409 {object} apierror.Response "Booking is not pending approval"
+// @Router /bookings/{id}/approve [post]
+func (h *Handler) ApproveBooking(w http.ResponseWriter, r *http.Request) {
+	h.decideBooking(w, r, "approve")
+}
+
+// RejectBooking godoc
+// @Summary Reject a pending booking
+// @Description Rejects a booking for a restricted room and releases the slot. Callers must be in the room's approver group or present a signed token from the approval email.
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param token formData string false "Signed rejection token"
+// @Param reason formData string false "Reason shown to the organizer"
+// @Success 200 {object} models.BookingResponse
+// @Failure 400 {object} apierror.Response "Invalid booking ID"
+// @Failure 403 {object} apierror.Response "Not an approver for this room"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Failure 409 {object} apierror.Response "Booking is not pending approval"
+// @Router /bookings/{id}/reject [post]
+func (h *Handler) RejectBooking(w http.ResponseWriter, r *http.Request) {
+	h.decideBooking(w, r, "reject")
+}
+
+func (h *Handler) decideBooking(w http.ResponseWriter, r *http.Request, action string) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var input struct {
+		Token  string `json:"token"`
+		Reason string `json:"reason"`
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		if err := utils.ParseBody(r, &input); err != nil {
+			apierror.InvalidJSON(w, err)
+			return
+		}
+	} else {
+		input.Token = r.FormValue("token")
+		input.Reason = r.FormValue("reason")
+	}
+
+	booking, err := h.repos.Bookings.Get(uint(id), "Room", "Employee")
+	if err != nil {
+		apierror.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+
+	var actorID *uint
+	if input.Token != "" {
+		if err := utils.VerifyApprovalToken(h.approvalSecret, input.Token, booking.ID, action, booking.ApprovalRequest()); err != nil {
+			apierror.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	} else {
+		employeeID, ok := h.currentEmployeeID(r)
+		if !ok {
+			apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		actorID = &employeeID
+	}
+
+	if err := h.service.Decide(&booking, action == "approve", actorID, input.Reason); err != nil {
+		writeServiceError(w, r, err, "Failed to update booking status")
+		return
+	}
+
+	h.notifyDecision(r.Context(), &booking, input.Reason)
+
+	resp, _ := json.Marshal(models.NewBookingResponse(booking))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// notifyDecision emails the organizer about an approval decision and, once
+// confirmed, adds the booking to their calendar.
+func (h *Handler) notifyDecision(ctx context.Context, booking *models.Booking, reason string) {
+	if booking.GroupID != nil {
+		if booking.Status == models.BookingConfirmed {
+			h.finalizeBookingGroup(ctx, *booking.GroupID)
+		} else {
+			h.cancelBookingGroup(ctx, *booking.GroupID, nil,
+				fmt.Sprintf("%s was not approved: %s", booking.Room.Name, reason))
+		}
+		return
+	}
+
+	employee := booking.Employee
+	if booking.Status == models.BookingConfirmed {
+		message := fmt.Sprintf("Hi %s,\n\nYour booking of %s from %s to %s has been approved and is confirmed.",
+			employee.Name, booking.Room.Name, booking.StartTime, booking.EndTime)
+		h.sendEmail(ctx, employee.Email, "Meeting Room Booking Approved", message)
+		h.addCalendarEvent(ctx, booking, employee)
+		return
+	}
+
+	message := fmt.Sprintf("Hi %s,\n\nYour booking of %s from %s to %s has been rejected.",
+		employee.Name, booking.Room.Name, booking.StartTime, booking.EndTime)
+	if reason != "" {
+		message += "\n\nReason: " + reason
+	}
+	h.sendEmail(ctx, employee.Email, "Meeting Room Booking Rejected", message)
+}
+
+// ExpirePendingApprovals rejects pending bookings whose approval deadline has
+// passed, releasing the slot and notifying the organizer, and returns how
+// many were.
+func (h *Handler) ExpirePendingApprovals(ctx context.Context, now time.Time) (int, error) {
+	expired, err := h.service.ExpirePendingApprovals(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch expired approval requests: %w", err)
+	}
+	for i := range expired {
+		booking := &expired[i]
+		logging.FromContext(ctx).Info("Approval request expired", "booking_id", booking.ID)
+		h.notifyDecision(ctx, booking, "the approval request expired without a response")
+	}
+	return len(expired), nil
+}
@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/csrf"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
-	session "github.com/koushikidey/go-meetingroombook/pkg/sessions"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -18,25 +20,39 @@ import (
 // @Produce json
 // @Param employee body models.EmployeeDTO true "Employee registration details"
 // @Success 201 {object} map[string]string
-// @Failure 400 {string} string "Invalid input or creation failed"
-// @Failure 500 {string} string "Failed to hash password"
+// @Failure 400 {object} apierror.Response "Invalid input or creation failed"
+// @Failure 500 {object} apierror.Response "Failed to hash password"
 // @Router /register [post]
-func Register(w http.ResponseWriter, r *http.Request) {
-	var input models.Employee
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	var input models.EmployeeDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	taken, err := h.repos.Employees.EmailTaken(input.Email, 0)
+	if err != nil {
+		apierror.Error(w, "Failed to create employee", http.StatusInternalServerError)
+		return
+	}
+	if taken {
+		apierror.Field(w, "email", validation.CodeTaken, "is already registered")
 		return
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
-		http.Error(w, "Failed to hash password", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to hash password", http.StatusInternalServerError)
 		return
 	}
-	input.Password = string(hashedPassword)
-	config.Connect()
-	if err := config.GetDB().Create(&input).Error; err != nil {
-		http.Error(w, "Failed to create employee", http.StatusBadRequest)
+	employee := models.Employee{
+		Name:      input.Name,
+		Email:     input.Email,
+		Password:  string(hashedPassword),
+		HomeFloor: input.HomeFloor,
+		Role:      models.RoleEmployee,
+	}
+	if err := h.repos.Employees.Create(&employee); err != nil {
+		apierror.Error(w, "Failed to create employee", http.StatusBadRequest)
 		return
 	}
 
@@ -50,43 +66,44 @@ func Register(w http.ResponseWriter, r *http.Request) {
 // @Tags Authentication
 // @Accept json
 // @Produce json
-// @Param credentials body models.EmployeeDTO true "Login credentials (email and password)"
+// @Param credentials body models.CredentialsDTO true "Login credentials (email and password)"
 // @Success 200 {object} map[string]string "Login successful message"
-// @Failure 400 {string} string "Invalid input"
-// @Failure 401 {string} string "Email not found or incorrect password"
+// @Failure 400 {object} apierror.Response "Invalid input"
+// @Failure 401 {object} apierror.Response "Email not found or incorrect password"
+// @Failure 403 {object} apierror.Response "Account is deactivated"
+// @Failure 500 {object} apierror.Response "Failed to start session"
 // @Router /login [post]
-func Login(w http.ResponseWriter, r *http.Request) {
-	var input models.Employee
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	var input models.CredentialsDTO
+	if !decodeBody(w, r, &input) {
 		return
 	}
 
-	var employee models.Employee
-	config.Connect()
-	if err := config.GetDB().Where("email = ?", input.Email).First(&employee).Error; err != nil {
-		http.Error(w, "Email not found", http.StatusUnauthorized)
+	employee, err := h.repos.Employees.GetByEmail(input.Email)
+	if err != nil {
+		apierror.Error(w, "Email not found", http.StatusUnauthorized)
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(employee.Password), []byte(input.Password)); err != nil {
-		http.Error(w, "Incorrect password", http.StatusUnauthorized)
+		apierror.Error(w, "Incorrect password", http.StatusUnauthorized)
+		return
+	}
+	if !employee.IsActive() {
+		apierror.Error(w, "Account is deactivated", http.StatusForbidden)
 		return
 	}
 
-	sess, _ := session.GetStore().Get(r, "session")
+	sess, _ := h.sessions.Get(r, "session")
 	sess.Values["employee_id"] = employee.ID
-	sess.Options.HttpOnly = true
-	sess.Options.SameSite = http.SameSiteLaxMode
-	sess.Options.Secure = false
-	sess.Options.Path = "/"
-	// if os.Getenv("ENV") != "production" {
-	// 	sess.Options.Secure = false
-	// } else {
-	// 	sess.Options.Secure = true
-	// }
-	sess.Save(r, w)
-	// fmt.Printf("Login success: session set for employee_id=%d\n", employee.ID)
+	// A token fetched before logging in may have been planted along with the
+	// session cookie, so the logged-in session gets a fresh one.
+	csrf.Reset(sess)
+	if err := sess.Save(r, w); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to save session", "employee_id", employee.ID, "error", err)
+		apierror.Error(w, "Failed to start session", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Login successful"})
@@ -94,14 +111,26 @@ func Login(w http.ResponseWriter, r *http.Request) {
 
 // Logout godoc
 // @Summary Log out the current employee
-// @Description Ends the employee's session by clearing session data
+// @Description Ends the employee's session on the server and clears the session cookie
 // @Tags Authentication
 // @Produce json
 // @Success 200 {object} map[string]string "Logout successful message"
+// @Failure 500 {object} apierror.Response "Failed to end session"
 // @Router /logout [post]
-func Logout(w http.ResponseWriter, r *http.Request) {
-	session, _ := session.GetStore().Get(r, "session")
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.sessions.Get(r, "session")
 	session.Options.MaxAge = -1
-	session.Save(r, w)
+	if err := session.Save(r, w); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to end session", "error", err)
+		apierror.Error(w, "Failed to end session", http.StatusInternalServerError)
+		return
+	}
 	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out successfully"})
-}
\ No newline at end of file
+}
+
+// currentEmployeeID returns the ID of the logged-in employee, if any.
+func (h *Handler) currentEmployeeID(r *http.Request) (uint, bool) {
+	sess, _ := h.sessions.Get(r, "session")
+	employeeID, ok := sess.Values["employee_id"].(uint)
+	return employeeID, ok
+}
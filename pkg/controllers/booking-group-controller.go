@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"google.golang.org/api/calendar/v3"
+)
+
+// CreateBookingGroup godoc
+// @Summary Book several rooms for one meeting
+// @Description Reserves all listed rooms for the same slot, or none of them if any room is unavailable. Sends one set of notifications and creates one calendar event listing every room.
+// @Tags Booking Groups
+// @Accept json
+// @Produce json
+// @Param group body models.BookingGroupDTO true "Rooms and time slot"
+// @Success 201 {object} models.BookingGroupResponse
+// @Failure 400 {object} apierror.Response "Invalid input or capacity exceeded"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 409 {object} apierror.Response "One or more rooms are already booked"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /booking-groups [post]
+func (h *Handler) CreateBookingGroup(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input models.BookingGroupDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	group, err := h.service.CreateGroup(employeeID, input)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to save group booking")
+		return
+	}
+
+	h.announceBookingGroup(r.Context(), group.ID)
+	h.respondWithGroup(w, group.ID, http.StatusCreated)
+}
+
+// GetBookingGroup godoc
+// @Summary Get a multi-room booking
+// @Description Returns the group with the bookings of all its rooms
+// @Tags Booking Groups
+// @Produce json
+// @Param id path int true "Booking group ID"
+// @Success 200 {object} models.BookingGroupResponse
+// @Failure 400 {object} apierror.Response "Invalid ID"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Booking group not found"
+// @Router /booking-groups/{id} [get]
+func (h *Handler) GetBookingGroup(w http.ResponseWriter, r *http.Request) {
+	group, _, ok := h.loadOwnBookingGroup(w, r, "")
+	if !ok {
+		return
+	}
+	h.respondWithGroup(w, group.ID, http.StatusOK)
+}
+
+// UpdateBookingGroup godoc
+// @Summary Update a multi-room booking
+// @Description Moves the whole group to a new slot and/or changes its rooms. Every room is re-checked and the update is applied to all rooms or none.
+// @Tags Booking Groups
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking group ID"
+// @Param group body models.BookingGroupDTO true "Rooms and time slot"
+// @Success 200 {object} models.BookingGroupResponse
+// @Failure 400 {object} apierror.Response "Invalid input or capacity exceeded"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Booking group not found"
+// @Failure 409 {object} apierror.Response "One or more rooms are already booked"
+// @Router /booking-groups/{id} [put]
+func (h *Handler) UpdateBookingGroup(w http.ResponseWriter, r *http.Request) {
+	group, employeeID, ok := h.loadOwnBookingGroup(w, r, models.DelegateEdit)
+	if !ok {
+		return
+	}
+
+	var input models.BookingGroupDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	previous := *group
+	if err := h.service.UpdateGroup(employeeID, group, input); err != nil {
+		writeServiceError(w, r, err, "Failed to save group booking")
+		return
+	}
+
+	h.removeGroupCalendarEvent(r.Context(), &previous)
+	h.announceBookingGroup(r.Context(), group.ID)
+	h.respondWithGroup(w, group.ID, http.StatusOK)
+}
+
+// DeleteBookingGroup godoc
+// @Summary Cancel a multi-room booking
+// @Description Cancels the bookings of every room in the group
+// @Tags Booking Groups
+// @Param id path int true "Booking group ID"
+// @Success 204 "Booking group cancelled"
+// @Failure 400 {object} apierror.Response "Invalid ID"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Booking group not found"
+// @Failure 409 {object} apierror.Response "A booking of the group cannot be cancelled; none were"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /booking-groups/{id} [delete]
+func (h *Handler) DeleteBookingGroup(w http.ResponseWriter, r *http.Request) {
+	group, employeeID, ok := h.loadOwnBookingGroup(w, r, models.DelegateCancel)
+	if !ok {
+		return
+	}
+	if err := h.cancelBookingGroup(r.Context(), group.ID, &employeeID, "cancelled by organizer"); err != nil {
+		writeServiceError(w, r, err, "Failed to cancel booking group")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOwnBookingGroup loads the group named in the URL and makes sure the
+// logged-in employee may perform the action on it (an empty action means
+// viewing), writing an error response otherwise. It also returns the ID of the
+// logged-in employee.
+func (h *Handler) loadOwnBookingGroup(w http.ResponseWriter, r *http.Request, action models.DelegateAction) (*models.BookingGroup, uint, bool) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, 0, false
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid booking group ID", http.StatusBadRequest)
+		return nil, 0, false
+	}
+
+	group, err := h.service.GetGroup(employeeID, uint(id), action)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to retrieve booking group")
+		return nil, 0, false
+	}
+	return &group, employeeID, true
+}
+
+func (h *Handler) respondWithGroup(w http.ResponseWriter, groupID uint, status int) {
+	group, err := h.repos.BookingGroups.Get(groupID, "Bookings.Room")
+	if err != nil {
+		apierror.Error(w, "Failed to retrieve booking group", http.StatusInternalServerError)
+		return
+	}
+	resp, _ := json.Marshal(models.NewBookingGroupResponse(group))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(resp)
+}
+
+func roomNames(bookings []models.Booking) string {
+	names := make([]string, 0, len(bookings))
+	for _, b := range bookings {
+		names = append(names, b.Room.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// announceBookingGroup asks approvers to review the group's restricted rooms
+// and tells the organizer what happens next. Groups without restricted rooms
+// are confirmed straight away.
+func (h *Handler) announceBookingGroup(ctx context.Context, groupID uint) {
+	group, err := h.repos.BookingGroups.GetActive(groupID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to load booking group", "group_id", groupID, "error", err)
+		return
+	}
+
+	var pending []models.Booking
+	for _, b := range group.Bookings {
+		if b.Status == models.BookingPendingApproval {
+			pending = append(pending, b)
+		}
+	}
+	if len(pending) == 0 {
+		h.finalizeBookingGroup(ctx, groupID)
+		return
+	}
+
+	employee := group.Employee
+	message := fmt.Sprintf("Hi %s,\n\nYour booking of %s from %s to %s is awaiting approval for %s. All rooms will be confirmed together once approved.",
+		employee.Name, roomNames(group.Bookings), group.StartTime, group.EndTime, roomNames(pending))
+	h.sendEmail(ctx, employee.Email, "Meeting Room Booking Pending Approval", message)
+	for _, b := range pending {
+		h.notifyApprovers(ctx, b, b.Room, employee)
+	}
+}
+
+// finalizeBookingGroup sends the confirmation and creates the shared calendar
+// event once every room in the group is confirmed.
+func (h *Handler) finalizeBookingGroup(ctx context.Context, groupID uint) {
+	group, err := h.repos.BookingGroups.GetActive(groupID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to load booking group", "group_id", groupID, "error", err)
+		return
+	}
+	if len(group.Bookings) == 0 || group.CalendarID != "" {
+		return
+	}
+	for _, b := range group.Bookings {
+		if b.Status != models.BookingConfirmed {
+			return
+		}
+	}
+
+	employee := group.Employee
+	rooms := roomNames(group.Bookings)
+	message := fmt.Sprintf("Hi %s,\n\nYour booking is confirmed from %s to %s in %s.",
+		employee.Name, group.StartTime, group.EndTime, rooms)
+	h.sendEmail(ctx, employee.Email, "Meeting Room Booking Confirmation", message)
+	h.addGroupCalendarEvent(ctx, &group, employee)
+}
+
+// addGroupCalendarEvent creates the group's shared event in the organizer's
+// Google Calendar, if linked.
+func (h *Handler) addGroupCalendarEvent(ctx context.Context, group *models.BookingGroup, employee models.Employee) {
+	rooms := roomNames(group.Bookings)
+	summary := group.Title
+	if summary == "" {
+		summary = "Meeting Room Booking"
+	}
+	event := &calendar.Event{
+		Summary:     summary,
+		Location:    rooms,
+		Description: fmt.Sprintf("Booked by %s", employee.Name),
+		Start: &calendar.EventDateTime{
+			DateTime: group.StartTime.Format(time.RFC3339),
+			TimeZone: "Asia/Kolkata",
+		},
+		End: &calendar.EventDateTime{
+			DateTime: group.EndTime.Format(time.RFC3339),
+			TimeZone: "Asia/Kolkata",
+		},
+		Reminders: h.calendarReminders(employee.ID),
+	}
+	if eventID, ok := h.insertCalendarEvent(ctx, employee.ID, event); ok {
+		group.CalendarID = eventID
+		if err := h.repos.BookingGroups.SetCalendarID(group.ID, eventID); err != nil {
+			logging.FromContext(ctx).Error("Failed to update calendar ID of booking group", "group_id", group.ID, "error", err)
+		}
+	}
+}
+
+// cancelBookingGroup cancels every active booking of the group, removes the
+// shared calendar event and notifies the organizer once. If any booking
+// cannot be cancelled, none is and the error is returned.
+func (h *Handler) cancelBookingGroup(ctx context.Context, groupID uint, actorID *uint, reason string) error {
+	group, cancelled, err := h.service.CancelGroup(groupID, actorID, reason)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to cancel booking group", "group_id", groupID, "error", err)
+		return err
+	}
+	h.removeGroupCalendarEvent(ctx, &group)
+	if len(cancelled) == 0 {
+		return nil
+	}
+
+	employee := group.Employee
+	message := fmt.Sprintf("Hi %s,\n\nYour booking of %s from %s to %s has been cancelled (%s).",
+		employee.Name, roomNames(cancelled), group.StartTime, group.EndTime, reason)
+	h.sendEmail(ctx, employee.Email, "Meeting Room Booking Cancelled", message)
+	return nil
+}
+
+func (h *Handler) removeGroupCalendarEvent(ctx context.Context, group *models.BookingGroup) {
+	if group.CalendarID == "" {
+		return
+	}
+	h.removeCalendarEvent(ctx, models.Booking{EmployeeID: group.EmployeeID, CalendarID: group.CalendarID})
+	if err := h.repos.BookingGroups.SetCalendarID(group.ID, ""); err != nil {
+		logging.FromContext(ctx).Error("Failed to clear calendar ID of booking group", "group_id", group.ID, "error", err)
+	}
+}
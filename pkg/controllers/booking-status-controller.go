@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+)
+
+// writeTransitionError maps errors of status changes to HTTP responses.
+func writeTransitionError(w http.ResponseWriter, err error) {
+	var invalid *models.InvalidTransitionError
+	switch {
+	case errors.As(err, &invalid):
+		apierror.Write(w, http.StatusConflict, apierror.CodeInvalidTransition, err.Error(), nil)
+	case errors.Is(err, repository.ErrStaleBooking):
+		apierror.Write(w, http.StatusConflict, apierror.CodeStale, err.Error(), nil)
+	default:
+		apierror.Error(w, "Failed to update booking status", http.StatusInternalServerError)
+	}
+}
+
+// TransitionBooking godoc
+// @Summary Change the status of a booking
+// @Description Moves a booking through its lifecycle (e.g. checked_in, completed, cancelled, no_show, released). Only transitions allowed from the current status are accepted. Check-in opens 15 minutes before the start and closes at the end, completed needs the meeting to have started and no_show the no-show grace period to have passed.
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param transition body models.BookingTransitionDTO true "Target status and reason"
+// @Success 200 {object} models.BookingResponse
+// @Failure 400 {object} apierror.Response "Invalid booking ID or JSON input"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Failure 409 {object} apierror.Response "Transition not allowed from the current status or at this time"
+// @Router /bookings/{id}/status [post]
+func (h *Handler) TransitionBooking(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var input models.BookingTransitionDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+	booking, err := h.service.ChangeStatus(employeeID, uint(id), input)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to update booking status")
+		return
+	}
+	if input.Status == models.BookingCancelled ||
+		(input.Status == models.BookingReleased && time.Now().Before(booking.StartTime)) {
+		h.removeCalendarEvent(r.Context(), booking)
+	}
+
+	resp, _ := json.Marshal(models.NewBookingResponse(booking))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// GetBookingHistory godoc
+// @Summary Get the status history of a booking
+// @Description Lists every status change of the booking with its actor and reason, oldest first
+// @Tags Bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {array} models.BookingHistoryResponse
+// @Failure 400 {object} apierror.Response "Invalid booking ID"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Router /bookings/{id}/history [get]
+func (h *Handler) GetBookingHistory(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	transitions, err := h.service.History(employeeID, uint(id))
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to retrieve booking history")
+		return
+	}
+
+	resp, _ := json.Marshal(models.NewBookingHistoryResponses(transitions))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// CompleteFinishedBookings marks confirmed and checked-in bookings whose end
+// time has passed as completed and returns how many were.
+func (h *Handler) CompleteFinishedBookings(now time.Time) (int, error) {
+	completed, err := h.service.CompleteFinished(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch finished bookings: %w", err)
+	}
+	return completed, nil
+}
+
+// ReleaseNoShows releases confirmed bookings nobody checked in to within
+// grace of the start, tells the organizers and returns how many were.
+func (h *Handler) ReleaseNoShows(ctx context.Context, now time.Time, grace time.Duration) (int, error) {
+	released, err := h.service.ReleaseNoShows(now, grace)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch unattended bookings: %w", err)
+	}
+	for _, booking := range released {
+		message := fmt.Sprintf("Hi %s,\n\nNobody checked in to your booking of %s from %s to %s within %s of the start, so the room has been released.",
+			booking.Employee.Name, booking.Room.Name, booking.StartTime, booking.EndTime, grace)
+		h.sendEmail(ctx, booking.Employee.Email, "Meeting Room Released", message)
+	}
+	return len(released), nil
+}
+
+// removeCalendarEvent deletes the booking's Google Calendar event, if any.
+func (h *Handler) removeCalendarEvent(ctx context.Context, booking models.Booking) {
+	if booking.CalendarID == "" {
+		return
+	}
+	if err := h.calendar.DeleteEvent(ctx, booking.EmployeeID, booking.CalendarID); err != nil {
+		logging.FromContext(ctx).Error("Failed to delete calendar event", "booking_id", booking.ID, "employee_id", booking.EmployeeID, "error", err)
+	}
+}
+
+// parseStatusFilter parses a comma-separated list of statuses.
+func parseStatusFilter(value string) ([]models.BookingStatus, error) {
+	var statuses []models.BookingStatus
+	for _, s := range utils.SplitList(value) {
+		status := models.BookingStatus(s)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("unknown status %q", s)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+)
+
+// CreateDelegation godoc
+// @Summary Grant a delegate permission to manage my bookings
+// @Description Lets another employee book, edit and/or cancel bookings on behalf of the logged-in employee. Granting again replaces the previous permissions.
+// @Tags Delegations
+// @Accept json
+// @Produce json
+// @Param delegation body models.DelegationDTO true "Delegate and permissions"
+// @Success 201 {object} models.DelegationResponse
+// @Failure 400 {object} apierror.Response "Invalid JSON or delegate"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /delegations [post]
+func (h *Handler) CreateDelegation(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input models.DelegationDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+	if input.DelegateID == employeeID {
+		apierror.Field(w, "delegate_id", validation.CodeInvalid, "you cannot delegate to yourself")
+		return
+	}
+
+	delegate, err := h.repos.Employees.Get(input.DelegateID)
+	if err != nil || !delegate.IsActive() {
+		apierror.Field(w, "delegate_id", validation.CodeNotFound, "delegate does not exist")
+		return
+	}
+
+	delegation := models.Delegation{
+		PrincipalID: employeeID,
+		DelegateID:  input.DelegateID,
+		CanBook:     input.CanBook,
+		CanEdit:     input.CanEdit,
+		CanCancel:   input.CanCancel,
+	}
+	if err := h.repos.Delegations.Grant(&delegation); err != nil {
+		apierror.Error(w, "Could not save delegation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.NewDelegationResponse(delegation))
+}
+
+// GetDelegations godoc
+// @Summary List my delegations
+// @Description Returns the delegations the logged-in employee has granted and those granted to them
+// @Tags Delegations
+// @Produce json
+// @Success 200 {object} models.DelegationsResponse
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Router /delegations [get]
+func (h *Handler) GetDelegations(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	granted, err := h.repos.Delegations.Granted(employeeID)
+	if err != nil {
+		apierror.Error(w, "Failed to fetch delegations", http.StatusInternalServerError)
+		return
+	}
+	received, err := h.repos.Delegations.Received(employeeID)
+	if err != nil {
+		apierror.Error(w, "Failed to fetch delegations", http.StatusInternalServerError)
+		return
+	}
+
+	resp, _ := json.Marshal(models.DelegationsResponse{
+		Granted:  models.NewDelegationResponses(granted),
+		Received: models.NewDelegationResponses(received),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// DeleteDelegation godoc
+// @Summary Revoke a delegation
+// @Description The principal can revoke a delegation and the delegate can give it up
+// @Tags Delegations
+// @Param id path int true "Delegation ID"
+// @Success 204 "Delegation revoked"
+// @Failure 400 {object} apierror.Response "Invalid ID"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Delegation not found"
+// @Router /delegations/{id} [delete]
+func (h *Handler) DeleteDelegation(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid delegation ID", http.StatusBadRequest)
+		return
+	}
+
+	delegation, err := h.repos.Delegations.Get(uint(id))
+	if err != nil {
+		apierror.Error(w, "Delegation not found", http.StatusNotFound)
+		return
+	}
+	if delegation.PrincipalID != employeeID && delegation.DelegateID != employeeID {
+		apierror.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	if err := h.repos.Delegations.Delete(&delegation); err != nil {
+		apierror.Error(w, "Failed to revoke delegation", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,15 +2,16 @@ package controllers
 
 import (
 	"encoding/json"
-	"io"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
-	"github.com/koushikidey/go-meetingroombook/pkg/cache"
-	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/listing"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
-	session "github.com/koushikidey/go-meetingroombook/pkg/sessions"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
@@ -20,23 +21,23 @@ import (
 
 // 	body, err := io.ReadAll(r.Body)
 // 	if err != nil {
-// 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+// 		apierror.Error(w, "Failed to read request body", http.StatusBadRequest)
 // 		return
 // 	}
 
 // 	if err := json.Unmarshal(body, &emp); err != nil {
-// 		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+// 		apierror.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
 // 		return
 // 	}
 // 	config.Connect()
 // 	db := config.GetDB()
 // 	if err := db.Create(&emp).Error; err != nil {
-// 		http.Error(w, "Could not create employee: "+err.Error(), http.StatusInternalServerError)
+// 		apierror.Error(w, "Could not create employee: "+err.Error(), http.StatusInternalServerError)
 // 		return
 // 	}
 // 	var createdEmployee models.Employee
 // 	if err := db.First(&createdEmployee, emp.ID).Error; err != nil {
-// 		http.Error(w, "Could not retrieve created employee: "+err.Error(), http.StatusInternalServerError)
+// 		apierror.Error(w, "Could not retrieve created employee: "+err.Error(), http.StatusInternalServerError)
 // 		return
 // 	}
 
@@ -46,64 +47,123 @@ import (
 // }
 
 // GetEmployees godoc
-// @Summary Get all employees
-// @Description Returns a list of all employees with their bookings and room details
+// @Summary Get list of employees
+// @Description Returns a page of employees. An employee's bookings are listed by GET /bookings?employee_id=. The next page's cursor is returned in the X-Next-Cursor and Link headers.
 // @Tags Employees
 // @Produce json
-// @Success 200 {array} models.EmployeeDTO
+// @Param id query string false "Comma-separated employee IDs"
+// @Param email query string false "Email address"
+// @Param name query string false "Name contains"
+// @Param role query string false "employee or admin"
+// @Param active query bool false "Only active or only deactivated employees"
+// @Param home_floor query int false "Home floor"
+// @Param sort query string false "id, name, email or created_at; prefix with - for descending (default id)"
+// @Param fields query string false "Comma-separated JSON fields to return"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from X-Next-Cursor"
+// @Success 200 {array} models.EmployeeResponse
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} apierror.Response "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
 // @Router /employees [get]
-func GetEmployees(w http.ResponseWriter, r *http.Request) {
-	var employees []models.Employee
-	config.Connect()
-	db := config.GetDB()
-	db.Preload("Bookings.Room").Preload("Bookings.Employee").Find(&employees)
-	resp, _ := json.Marshal(employees)
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(resp)
+func (h *Handler) GetEmployees(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.currentEmployeeID(r); !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	list, err := listing.Parse(r.URL.Query(), employeeListSpec)
+	if err != nil {
+		apierror.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	employees, err := h.repos.Employees.List(list)
+	if err != nil {
+		writeListError(w, err, "Failed to fetch employees")
+		return
+	}
+	list.Page(w, r, &employees)
+	list.WriteJSON(w, models.NewEmployeeResponses(employees))
+}
+
+var employeeListSpec = listing.Spec{
+	Sorts: map[string]listing.SortKey{
+		"id":         {Column: "id", Field: "ID", Kind: listing.Number},
+		"name":       {Column: "name", Field: "Name", Kind: listing.String},
+		"email":      {Column: "email", Field: "Email", Kind: listing.String},
+		"created_at": {Column: "created_at", Field: "CreatedAt", Kind: listing.Time},
+	},
+	DefaultSort: "id",
+	Filters: map[string]listing.Filter{
+		"id":         listing.IDs("id"),
+		"email":      listing.Text("email = ?"),
+		"name":       listing.Contains("name"),
+		"role":       listing.Text("role = ?"),
+		"home_floor": listing.Int("home_floor = ?"),
+		"active": func(db *gorm.DB, value string) (*gorm.DB, error) {
+			active, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not true or false", value)
+			}
+			if active {
+				return db.Where("deactivated_at IS NULL"), nil
+			}
+			return db.Where("deactivated_at IS NOT NULL"), nil
+		},
+	},
 }
 
 // GetEmployeeByIDWithCache godoc
 // @Summary Get employee by ID (with cache)
-// @Description Retrieves employee details by ID including bookings and room info, uses cache for faster response
+// @Description Retrieves employee details by ID, using a cache for faster responses. The employee's bookings are listed by GET /bookings?employee_id=.
 // @Tags Employees
 // @Produce json
 // @Param id path uint true "Employee ID"
-// @Success 200 {object} models.EmployeeDTO
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "Employee not found"
-// @Failure 500 {string} string "Error marshalling data"
+// @Success 200 {object} models.EmployeeResponse
+// @Failure 400 {object} apierror.Response "Invalid ID"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 404 {object} apierror.Response "Employee not found"
+// @Failure 500 {object} apierror.Response "Error marshalling data"
 // @Router /employees/{id} [get]
-func GetEmployeeByIDWithCache(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetEmployeeByIDWithCache(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.currentEmployeeID(r); !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	vars := mux.Vars(r)
 	idParam := vars["id"]
 
 	idUint, err := strconv.ParseUint(idParam, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		apierror.Error(w, "Invalid ID", http.StatusBadRequest)
 		return
 	}
 
-	if res, ok := cache.C.Read(uint(idUint)); ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write(res)
-		return
+	if h.cache != nil {
+		if res, ok := h.cache.Read(uint(idUint)); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(res)
+			return
+		}
 	}
 
-	db := config.GetDB()
-	var employee models.Employee
-	result := db.Preload("Bookings.Room").Preload("Bookings.Employee").First(&employee, idUint)
-	if result.Error != nil {
-		http.Error(w, "Employee not found", http.StatusNotFound)
+	employee, err := h.repos.Employees.Get(uint(idUint))
+	if err != nil {
+		apierror.Error(w, "Employee not found", http.StatusNotFound)
 		return
 	}
 
-	resp, err := json.Marshal(employee)
+	resp, err := json.Marshal(models.NewEmployeeResponse(employee))
 	if err != nil {
-		http.Error(w, "Error marshalling", http.StatusInternalServerError)
+		apierror.Error(w, "Error marshalling", http.StatusInternalServerError)
 		return
 	}
 
-	cache.C.Update(uint(idUint), employee)
+	if h.cache != nil {
+		h.cache.Update(uint(idUint), employee)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(resp)
@@ -111,109 +171,120 @@ func GetEmployeeByIDWithCache(w http.ResponseWriter, r *http.Request) {
 
 // UpdateEmployees godoc
 // @Summary Update an employee's own details
-// @Description Allows an authenticated employee to update their name, email, and password
+// @Description Allows an authenticated employee to update their name, email, and password. Changing the password signs the employee out of all their other sessions.
 // @Tags Employees
 // @Accept json
 // @Produce json
 // @Param id path int true "Employee ID"
 // @Param employee body models.EmployeeDTO true "Updated employee details (name, email, password)"
-// @Success 200 {object} models.EmployeeDTO
-// @Failure 400 {string} string "Invalid Employee ID or JSON input"
-// @Failure 401 {string} string "Unauthorized (not logged in)"
-// @Failure 403 {string} string "Forbidden (trying to update another employee)"
-// @Failure 404 {string} string "Employee not found"
-// @Failure 500 {string} string "Failed to update employee"
+// @Success 200 {object} models.EmployeeResponse
+// @Failure 400 {object} apierror.Response "Invalid Employee ID or JSON input"
+// @Failure 401 {object} apierror.Response "Unauthorized (not logged in)"
+// @Failure 403 {object} apierror.Response "Forbidden (trying to update another employee)"
+// @Failure 404 {object} apierror.Response "Employee not found"
+// @Failure 500 {object} apierror.Response "Failed to update employee"
 // @Router /employees/{id} [put]
-func UpdateEmployees(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) UpdateEmployees(w http.ResponseWriter, r *http.Request) {
 
-	session, _ := session.GetStore().Get(r, "session")
+	session, _ := h.sessions.Get(r, "session")
 	employeeID, ok := session.Values["employee_id"].(uint)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid Employee ID", http.StatusBadRequest)
+		apierror.Error(w, "Invalid Employee ID", http.StatusBadRequest)
 		return
 	}
 
-	var existing models.Employee
-	config.Connect()
-	db := config.GetDB()
-	if err := db.First(&existing, id).Error; err != nil {
-		http.Error(w, "Employee not found", http.StatusNotFound)
+	existing, err := h.repos.Employees.Get(uint(id))
+	if err != nil {
+		apierror.Error(w, "Employee not found", http.StatusNotFound)
 		return
 	}
 
 	if existing.ID != employeeID {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apierror.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
 
-	body, _ := io.ReadAll(r.Body)
-	var updated models.Employee
-	if err := json.Unmarshal(body, &updated); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+	var updated models.EmployeeDTO
+	if !decodeBody(w, r, &updated) {
+		return
+	}
+	taken, err := h.repos.Employees.EmailTaken(updated.Email, existing.ID)
+	if err != nil {
+		apierror.Error(w, "Failed to update employee", http.StatusInternalServerError)
+		return
+	}
+	if taken {
+		apierror.Field(w, "email", validation.CodeTaken, "is already registered")
 		return
 	}
 
 	existing.Name = updated.Name
 	existing.Email = updated.Email
+	if updated.HomeFloor != nil {
+		existing.HomeFloor = updated.HomeFloor
+	}
+	passwordChanged := bcrypt.CompareHashAndPassword([]byte(existing.Password), []byte(updated.Password)) != nil
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(updated.Password), bcrypt.DefaultCost)
 	if err != nil {
-		http.Error(w, "Failed to hash password", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to hash password", http.StatusInternalServerError)
 		return
 	}
 	existing.Password = string(hashedPassword)
 
-	if err := db.Save(&existing).Error; err != nil {
-		http.Error(w, "Failed to update employee", http.StatusInternalServerError)
+	if err := h.repos.Employees.Save(&existing); err != nil {
+		apierror.Error(w, "Failed to update employee", http.StatusInternalServerError)
 		return
 	}
-	resp, _ := json.Marshal(existing)
+	if passwordChanged {
+		h.revokeSessions(r.Context(), existing.ID, session.ID)
+	}
+	if h.cache != nil {
+		h.cache.Delete(existing.ID)
+	}
+	resp, _ := json.Marshal(models.NewEmployeeResponse(existing))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(resp)
 
 }
 
-func GetEmployee(w http.ResponseWriter, r *http.Request) {
-	session, _ := session.GetStore().Get(r, "session")
+func (h *Handler) GetEmployee(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.sessions.Get(r, "session")
 	employeeID, ok := session.Values["employee_id"].(uint)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid Employee id", http.StatusBadRequest)
+		apierror.Error(w, "Invalid Employee id", http.StatusBadRequest)
 		return
 	}
 
-	var employee models.Employee
-	config.Connect()
-	db := config.GetDB()
-	result := db.Preload("Bookings.Room").Preload("Bookings.Employee").First(&employee, id)
-
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			http.Error(w, "Employee not found", http.StatusNotFound)
+	employee, err := h.repos.Employees.GetWithBookings(uint(id))
+	if err != nil {
+		if repository.IsNotFound(err) {
+			apierror.Error(w, "Employee not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, "Failed to retrieve employee", http.StatusInternalServerError)
+		apierror.Error(w, "Failed to retrieve employee", http.StatusInternalServerError)
 		return
 	}
 
 	if employee.ID != employeeID {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apierror.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
 
-	resp, _ := json.Marshal(employee)
+	resp, _ := json.Marshal(models.NewEmployeeResponse(employee))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(resp)
 }
\ No newline at end of file
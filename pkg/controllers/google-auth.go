@@ -1,74 +1,99 @@
 package controllers
 
 import (
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 
-	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
 	"github.com/koushikidey/go-meetingroombook/pkg/googleapi"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
-	session "github.com/koushikidey/go-meetingroombook/pkg/sessions"
 )
 
+// googleStateKey is the session value holding the state of the Google
+// authorization in progress.
+const googleStateKey = "google_oauth_state"
+
 // GoogleLogin godoc
 // @Summary Initiate Google OAuth login flow
 // @Description Redirects logged-in employee to Google OAuth consent screen for authentication
 // @Tags Authentication
 // @Produce plain
 // @Success 307 "Redirect to Google OAuth consent screen"
-// @Failure 401 {string} string "User not logged in"
-// @Failure 500 {string} string "Failed to create auth URL"
+// @Failure 401 {object} apierror.Response "User not logged in"
+// @Failure 500 {object} apierror.Response "Failed to create auth URL"
 // @Router /google/login [get]
-func GoogleLogin(w http.ResponseWriter, r *http.Request) {
-	sess, _ := session.GetStore().Get(r, "session")
+func (h *Handler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
+	sess, _ := h.sessions.Get(r, "session")
 	userID, ok := sess.Values["employee_id"].(uint)
 	if !ok {
-		http.Error(w, "User not logged in", http.StatusUnauthorized)
+		apierror.Error(w, "User not logged in", http.StatusUnauthorized)
 		return
 	}
 
-	authURL, err := googleapi.GetAuthURLWithUser(userID)
+	state, err := googleapi.NewState()
 	if err != nil {
-		http.Error(w, "Failed to create auth URL: "+err.Error(), http.StatusInternalServerError)
+		apierror.Error(w, "Failed to create auth URL: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sess.Values[googleStateKey] = state
+	if err := sess.Save(r, w); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to save session", "employee_id", userID, "error", err)
+		apierror.Error(w, "Failed to create auth URL", http.StatusInternalServerError)
 		return
 	}
 
-	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
+	http.Redirect(w, r, googleapi.GetAuthURL(state), http.StatusTemporaryRedirect)
 }
 
 // GoogleCallback godoc
 // @Summary Handle Google OAuth callback
-// @Description Processes OAuth code and state, exchanges code for tokens, and stores them linked to the employee
+// @Description Processes OAuth code and state, exchanges code for tokens, and stores them linked to the logged-in employee. The state must be the one issued by /google/login in the same session.
 // @Tags Authentication
 // @Produce plain
 // @Param code query string true "OAuth authorization code"
 // @Param state query string true "OAuth state parameter"
 // @Success 200 {string} string "Google Calendar authorization successful! You may close this tab."
-// @Failure 400 {string} string "Missing or invalid code/state parameter"
-// @Failure 500 {string} string "Token exchange or database save failed"
+// @Failure 400 {object} apierror.Response "Missing or invalid code/state parameter"
+// @Failure 401 {object} apierror.Response "User not logged in"
+// @Failure 500 {object} apierror.Response "Token exchange or database save failed"
 // @Router /oauth2callback [get]
-func GoogleCallback(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
 	state := r.URL.Query().Get("state")
 
 	if code == "" {
-		http.Error(w, "Missing code in request", http.StatusBadRequest)
+		apierror.Error(w, "Missing code in request", http.StatusBadRequest)
 		return
 	}
 	if state == "" {
-		http.Error(w, "Missing state in request", http.StatusBadRequest)
+		apierror.Error(w, "Missing state in request", http.StatusBadRequest)
 		return
 	}
 
-	userID, err := googleapi.ParseState(state)
-	if err != nil {
-		http.Error(w, "Invalid state parameter: "+err.Error(), http.StatusBadRequest)
+	sess, _ := h.sessions.Get(r, "session")
+	userID, ok := sess.Values["employee_id"].(uint)
+	if !ok {
+		apierror.Error(w, "User not logged in", http.StatusUnauthorized)
+		return
+	}
+	expected, _ := sess.Values[googleStateKey].(string)
+	if expected == "" || subtle.ConstantTimeCompare([]byte(state), []byte(expected)) != 1 {
+		apierror.Error(w, "Invalid state parameter", http.StatusBadRequest)
+		return
+	}
+	// The state is good for one callback only.
+	delete(sess.Values, googleStateKey)
+	if err := sess.Save(r, w); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to save session", "employee_id", userID, "error", err)
+		apierror.Error(w, "Failed to save session", http.StatusInternalServerError)
 		return
 	}
 
 	token, err := googleapi.ExchangeCode(code)
 	if err != nil {
-		http.Error(w, "Token exchange failed: "+err.Error(), http.StatusInternalServerError)
+		apierror.Error(w, "Token exchange failed: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
@@ -78,9 +103,8 @@ func GoogleCallback(w http.ResponseWriter, r *http.Request) {
 		RefreshToken: token.RefreshToken,
 		Expiry:       token.Expiry,
 	}
-	db := config.GetDB()
-	if err := db.Create(&newToken).Error; err != nil {
-		http.Error(w, "Failed to save Google token: "+err.Error(), http.StatusInternalServerError)
+	if err := h.repos.Tokens.Save(&newToken); err != nil {
+		apierror.Error(w, "Failed to save Google token: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
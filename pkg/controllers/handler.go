@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/cache"
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/csrf"
+	"github.com/koushikidey/go-meetingroombook/pkg/googleapi"
+	"github.com/koushikidey/go-meetingroombook/pkg/jobs"
+	"github.com/koushikidey/go-meetingroombook/pkg/leader"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/outbox"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/services"
+	session "github.com/koushikidey/go-meetingroombook/pkg/sessions"
+	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+	"google.golang.org/api/calendar/v3"
+	"gorm.io/gorm"
+)
+
+// Calendar adds and removes events in the Google Calendar of employees who
+// linked it. *googleapi.Client implements it.
+type Calendar interface {
+	InsertEvent(ctx context.Context, employeeID uint, event *calendar.Event) (string, error)
+	DeleteEvent(ctx context.Context, employeeID uint, calendarEventID string) error
+	FreeBusy(ctx context.Context, employeeID uint, start, end time.Time) ([]googleapi.BusyPeriod, error)
+	// RevokeToken revokes and forgets the employee's Google token.
+	RevokeToken(ctx context.Context, employeeID uint) error
+	// RefreshTokens renews the tokens expiring before the deadline.
+	RefreshTokens(ctx context.Context, before time.Time) (int, error)
+}
+
+// Mailer sends plain-text emails. The request ID of ctx is kept with the
+// email.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// Dependencies are the collaborators of the handlers.
+type Dependencies struct {
+	Repos    repository.Repositories
+	Service  *services.BookingService
+	Calendar Calendar
+	Mailer   Mailer
+	// Outbox queues emails for Mailer and is flushed at shutdown.
+	Outbox *outbox.Outbox
+	// Elector decides which instance runs the scheduled jobs.
+	Elector *leader.Elector
+	// Jobs runs the background jobs; see Handler.Jobs.
+	Jobs     *jobs.Scheduler
+	Cache    *cache.Cache
+	Sessions *session.Store
+	// BaseURL is where employees reach the app, used in emailed links.
+	BaseURL string
+	// ApprovalSecret signs the approve/reject links.
+	ApprovalSecret string
+}
+
+// NewDependencies wires the production collaborators around db: gorm
+// repositories, Google Calendar, email over SMTP through the outbox, a job
+// scheduler led through a database lease, an in-memory cache and sessions
+// kept in the database, all set up from cfg.
+func NewDependencies(db *gorm.DB, cfg config.Config) Dependencies {
+	repos := repository.New(db)
+	mail := outbox.New(repos.Outbox, utils.SMTPMailer{
+		Host:     cfg.Email.Host,
+		Port:     cfg.Email.Port,
+		User:     cfg.Email.User,
+		Password: cfg.Email.Password,
+	})
+	elector := leader.New(repos.Leases, "scheduler", leader.Holder(), leader.DefaultTTL)
+	return Dependencies{
+		Repos:          repos,
+		Service:        services.NewBookingService(repos, time.Duration(cfg.Approval.Timeout), time.Duration(cfg.Jobs.NoShowGrace)),
+		Calendar:       googleapi.NewClient(repos.Tokens),
+		Mailer:         mail,
+		Outbox:         mail,
+		Elector:        elector,
+		Jobs:           jobs.New(repos.Jobs, elector, leader.Holder()),
+		Cache:          cache.New(),
+		Sessions:       session.NewStore(session.Database(repos.Sessions), cfg.Session),
+		BaseURL:        strings.TrimRight(cfg.Server.BaseURL, "/"),
+		ApprovalSecret: cfg.Approval.Secret,
+	}
+}
+
+// Handler serves the HTTP API and runs the scheduled booking jobs.
+type Handler struct {
+	repos    repository.Repositories
+	service  *services.BookingService
+	calendar Calendar
+	mailer   Mailer
+	cache    *cache.Cache
+	sessions *session.Store
+	jobs     *jobs.Scheduler
+	baseURL  string
+
+	approvalSecret string
+}
+
+func NewHandler(deps Dependencies) *Handler {
+	return &Handler{
+		repos:    deps.Repos,
+		service:  deps.Service,
+		calendar: deps.Calendar,
+		mailer:   deps.Mailer,
+		cache:    deps.Cache,
+		sessions: deps.Sessions,
+		jobs:     deps.Jobs,
+		baseURL:  deps.BaseURL,
+
+		approvalSecret: deps.ApprovalSecret,
+	}
+}
+
+// WithContext returns a copy of the handler whose database work runs with
+// ctx, so that the queries of a request or job run show up in its trace.
+func (h *Handler) WithContext(ctx context.Context) *Handler {
+	scoped := *h
+	scoped.repos = h.repos.WithContext(ctx)
+	scoped.service = h.service.WithContext(ctx)
+	return &scoped
+}
+
+// RequestLogging is the middleware that gives every request an ID and a
+// logger naming the signed-in employee; see logging.Middleware.
+func (h *Handler) RequestLogging() mux.MiddlewareFunc {
+	return logging.Middleware(h.currentEmployeeID)
+}
+
+// CSRFProtection is the middleware that makes state-changing requests with
+// the session cookie prove they come from our own pages; see
+// csrf.Middleware.
+func (h *Handler) CSRFProtection() mux.MiddlewareFunc {
+	return csrf.Middleware(h.sessions)
+}
+
+// sendEmail hands the email to the mailer, which queues it in the outbox so
+// slow mail servers do not hold up the response. Failures are only logged.
+func (h *Handler) sendEmail(ctx context.Context, to, subject, body string) {
+	if err := h.mailer.Send(ctx, to, subject, body); err != nil {
+		logging.FromContext(ctx).Error("Failed to send email", "subject", subject, "to", to, "error", err)
+	}
+}
+
+var serviceStatus = map[services.Kind]int{
+	services.KindInvalid:   http.StatusBadRequest,
+	services.KindForbidden: http.StatusForbidden,
+	services.KindNotFound:  http.StatusNotFound,
+	services.KindConflict:  http.StatusConflict,
+}
+
+// writeServiceError maps errors of the booking service to HTTP responses.
+// Unexpected errors are reported with the fallback message.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var refused *services.Error
+	var invalid *models.InvalidTransitionError
+	switch {
+	case errors.As(err, &refused):
+		apierror.Write(w, serviceStatus[refused.Kind], refused.Code, refused.Message, refused.Details)
+	case errors.As(err, &invalid), errors.Is(err, repository.ErrStaleBooking):
+		writeTransitionError(w, err)
+	default:
+		logging.FromContext(r.Context()).Error(fallback, "error", err)
+		apierror.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
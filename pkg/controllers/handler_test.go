@@ -0,0 +1,473 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/googleapi"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	session "github.com/koushikidey/go-meetingroombook/pkg/sessions"
+	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/api/calendar/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeMailer records emails instead of sending them.
+type fakeMailer struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (m *fakeMailer) Send(_ context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, to+": "+subject)
+	return nil
+}
+
+// fakeCalendar behaves as if nobody linked their Google Calendar.
+type fakeCalendar struct{}
+
+func (fakeCalendar) InsertEvent(context.Context, uint, *calendar.Event) (string, error) {
+	return "", googleapi.ErrNotLinked
+}
+
+func (fakeCalendar) DeleteEvent(context.Context, uint, string) error {
+	return googleapi.ErrNotLinked
+}
+
+func (fakeCalendar) FreeBusy(context.Context, uint, time.Time, time.Time) ([]googleapi.BusyPeriod, error) {
+	return nil, googleapi.ErrNotLinked
+}
+
+func (fakeCalendar) RevokeToken(context.Context, uint) error {
+	return nil
+}
+
+func (fakeCalendar) RefreshTokens(context.Context, time.Time) (int, error) {
+	return 0, nil
+}
+
+const testSessionKey = "test-session-key-of-at-least-32-bytes"
+
+func testConfig() config.Config {
+	cfg := config.Default()
+	cfg.Session.Key = testSessionKey
+	cfg.Approval.Secret = "test-approval-secret"
+	return cfg
+}
+
+func newTestHandler(db *gorm.DB) *Handler {
+	deps := NewDependencies(db, testConfig())
+	deps.Mailer = &fakeMailer{}
+	deps.Calendar = fakeCalendar{}
+	return NewHandler(deps)
+}
+
+// loggedIn returns a request carrying the cookie of a new session of the
+// employee with h.
+func loggedIn(t *testing.T, h *Handler, method, url string, body interface{}, employeeID uint) *http.Request {
+	payload, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req := httptest.NewRequest(method, url, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	sess, _ := h.sessions.New(req, "session")
+	sess.Values[session.EmployeeKey] = employeeID
+	assert.NoError(t, sess.Save(req, rr))
+	for _, cookie := range rr.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestUpdateBookingDetectsConflicts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	h := newTestHandler(db)
+
+	organizer := models.Employee{Name: "Organizer", Email: "organizer@example.com"}
+	db.Create(&organizer)
+	room := models.Room{Name: "Focus"}
+	db.Create(&room)
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	first := models.Booking{RoomID: room.ID, EmployeeID: organizer.ID, CreatedByID: organizer.ID,
+		StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingConfirmed}
+	second := models.Booking{RoomID: room.ID, EmployeeID: organizer.ID, CreatedByID: organizer.ID,
+		StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), Status: models.BookingConfirmed}
+	db.Create(&first)
+	db.Create(&second)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/bookings/{id}", h.UpdateBooking).Methods("PUT")
+
+	// Moving the second booking onto the first one is refused.
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "PUT", "/bookings/2", models.BookingUpdateDTO{
+		RoomID: room.ID, StartTime: start.Add(30 * time.Minute), EndTime: start.Add(90 * time.Minute), NumAttendees: 2,
+	}, organizer.ID))
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	var response apierror.Response
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, apierror.CodeBookingConflict, response.Error.Code)
+
+	// Moving it within its own slot is fine.
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "PUT", "/bookings/2", models.BookingUpdateDTO{
+		RoomID: room.ID, StartTime: start.Add(150 * time.Minute), EndTime: start.Add(3 * time.Hour), NumAttendees: 2,
+	}, organizer.ID))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestApprovalLinksOnlyWorkForTheCurrentRequest(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	h := newTestHandler(db)
+
+	organizer := models.Employee{Name: "Organizer", Email: "organizer@example.com"}
+	approver := models.Employee{Name: "Approver", Email: "approver@example.com"}
+	db.Create(&organizer)
+	db.Create(&approver)
+	group := models.ApproverGroup{Name: "Facilities", Members: []models.Employee{approver}}
+	db.Create(&group)
+	room := models.Room{Name: "Boardroom", RequiresApproval: true, ApproverGroupID: &group.ID}
+	db.Create(&room)
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	requested := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+	expires := time.Now().Add(time.Hour)
+	booking := models.Booking{RoomID: room.ID, EmployeeID: organizer.ID, CreatedByID: organizer.ID,
+		StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingPendingApproval,
+		PendingSince: &requested, ExpiresAt: &expires}
+	db.Create(&booking)
+	earlier, err := utils.SignApprovalToken(h.approvalSecret, booking.ID, "approve", requested, expires)
+	assert.NoError(t, err)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/bookings/{id}", h.UpdateBooking).Methods("PUT")
+	router.HandleFunc("/bookings/{id}/approve", h.ApprovalPage).Methods("GET")
+	router.HandleFunc("/bookings/{id}/approve", h.ApproveBooking).Methods("POST")
+	approve := func(token string) int {
+		payload, _ := json.Marshal(map[string]string{"token": token})
+		req := httptest.NewRequest("POST", fmt.Sprintf("/bookings/%d/approve", booking.ID), bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// Editing the booking sends it for approval again.
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "PUT", fmt.Sprintf("/bookings/%d", booking.ID), models.BookingUpdateDTO{
+		RoomID: room.ID, StartTime: start, EndTime: start.Add(2 * time.Hour), NumAttendees: 2,
+	}, organizer.ID))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", fmt.Sprintf("/bookings/%d/approve?token=%s", booking.ID, earlier), nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, http.StatusForbidden, approve(earlier))
+
+	current, err := h.repos.Bookings.Get(booking.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.BookingPendingApproval, current.Status)
+	assert.True(t, current.ApprovalRequest().After(requested))
+	token, err := utils.SignApprovalToken(h.approvalSecret, booking.ID, "approve", current.ApprovalRequest(), *current.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, approve(token))
+}
+
+// unsavableSessions keeps sessions in the database but fails to change them
+// once broken is set, like a session store that went away.
+type unsavableSessions struct {
+	session.Backend
+	broken bool
+}
+
+func (b *unsavableSessions) Save(ctx context.Context, s models.Session) error {
+	if b.broken {
+		return errors.New("session store unavailable")
+	}
+	return b.Backend.Save(ctx, s)
+}
+
+func (b *unsavableSessions) Delete(ctx context.Context, id string) error {
+	if b.broken {
+		return errors.New("session store unavailable")
+	}
+	return b.Backend.Delete(ctx, id)
+}
+
+func TestLoginAndLogoutReportSessionStoreFailures(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	backend := &unsavableSessions{Backend: session.Database(repository.NewSessionRepository(db))}
+	deps := NewDependencies(db, testConfig())
+	deps.Mailer = &fakeMailer{}
+	deps.Calendar = fakeCalendar{}
+	deps.Sessions = session.NewStore(backend, testConfig().Session)
+	h := NewHandler(deps)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct-horse"), bcrypt.MinCost)
+	employee := models.Employee{Name: "Ann", Email: "ann@example.com", Password: string(hash)}
+	db.Create(&employee)
+	credentials, _ := json.Marshal(models.CredentialsDTO{Email: employee.Email, Password: "correct-horse"})
+
+	backend.broken = true
+	rr := httptest.NewRecorder()
+	h.Login(rr, httptest.NewRequest("POST", "/login", bytes.NewReader(credentials)))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Empty(t, rr.Result().Cookies(), "no session was started")
+
+	backend.broken = false
+	req := loggedIn(t, h, "POST", "/logout", nil, employee.ID)
+	backend.broken = true
+	rr = httptest.NewRecorder()
+	h.Logout(rr, req)
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+	backend.broken = false
+	active, err := h.sessions.List(context.Background(), employee.ID)
+	assert.NoError(t, err)
+	assert.Len(t, active, 1, "the session is still alive")
+}
+
+func TestDelegatesBookOnlyWhileTheyHavePermission(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	h := newTestHandler(db)
+
+	principal := models.Employee{Name: "Principal", Email: "principal@example.com"}
+	delegate := models.Employee{Name: "Delegate", Email: "delegate@example.com"}
+	stranger := models.Employee{Name: "Stranger", Email: "stranger@example.com"}
+	db.Create(&principal)
+	db.Create(&delegate)
+	db.Create(&stranger)
+	room := models.Room{Name: "Focus"}
+	db.Create(&room)
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	bookFor := func(actor uint, hours time.Duration) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.CreateBooking(rr, loggedIn(t, h, "POST", "/bookings", models.BookingDTO{
+			RoomID: room.ID, EmployeeID: principal.ID, StartTime: start.Add(hours * time.Hour), EndTime: start.Add((hours + 1) * time.Hour),
+		}, actor))
+		return rr
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/delegations", h.CreateDelegation).Methods("POST")
+	router.HandleFunc("/delegations/{id}", h.DeleteDelegation).Methods("DELETE")
+	router.HandleFunc("/booking/{id}", h.DeleteBooking).Methods("DELETE")
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "POST", "/delegations", models.DelegationDTO{
+		DelegateID: delegate.ID, CanBook: true,
+	}, principal.ID))
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var delegation models.DelegationResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &delegation))
+
+	// The delegate books on the principal's behalf; the booking is theirs.
+	rr = bookFor(delegate.ID, 0)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	var booking models.BookingResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &booking))
+	assert.Equal(t, principal.ID, booking.EmployeeID)
+	assert.Equal(t, delegate.ID, booking.CreatedByID)
+
+	// Booking does not grant cancelling, and others may do neither.
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "DELETE", fmt.Sprintf("/booking/%d", booking.ID), nil, delegate.ID))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+	assert.Equal(t, http.StatusForbidden, bookFor(stranger.ID, 2).Code)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "DELETE", fmt.Sprintf("/delegations/%d", delegation.ID), nil, stranger.ID))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	// Once revoked, the delegate can no longer book for the principal.
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "DELETE", fmt.Sprintf("/delegations/%d", delegation.ID), nil, principal.ID))
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, http.StatusForbidden, bookFor(delegate.ID, 2).Code)
+	var bookings int64
+	db.Model(&models.Booking{}).Where("employee_id = ?", principal.ID).Count(&bookings)
+	assert.Equal(t, int64(1), bookings)
+}
+
+func TestGoogleCallbackOnlyAcceptsTheStateOfTheSession(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	h := newTestHandler(db)
+	googleapi.InitOAuth("client-id", "client-secret", "http://localhost:8080/oauth2callback")
+
+	victim := models.Employee{Name: "Victim", Email: "victim@example.com"}
+	attacker := models.Employee{Name: "Attacker", Email: "attacker@example.com"}
+	db.Create(&victim)
+	db.Create(&attacker)
+
+	// Starting the flow keeps the state in the session.
+	start := loggedIn(t, h, "GET", "/google/login", nil, attacker.ID)
+	rr := httptest.NewRecorder()
+	h.GoogleLogin(rr, start)
+	assert.Equal(t, http.StatusTemporaryRedirect, rr.Code)
+	location, err := url.Parse(rr.Header().Get("Location"))
+	assert.NoError(t, err)
+	state := location.Query().Get("state")
+	assert.NotEmpty(t, state)
+
+	callback := func(req *http.Request) int {
+		rr := httptest.NewRecorder()
+		h.GoogleCallback(rr, req)
+		return rr.Code
+	}
+	at := fmt.Sprintf("/oauth2callback?code=c0de&state=%s", url.QueryEscape(state))
+
+	// The state cannot be replayed into another session, a state naming an
+	// employee is not trusted, and a callback needs a session.
+	assert.Equal(t, http.StatusBadRequest, callback(loggedIn(t, h, "GET", at, nil, victim.ID)))
+	forged := base64.URLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:0123456789abcdef", victim.ID)))
+	assert.Equal(t, http.StatusBadRequest, callback(loggedIn(t, h, "GET", "/oauth2callback?code=c0de&state="+forged, nil, victim.ID)))
+	assert.Equal(t, http.StatusUnauthorized, callback(httptest.NewRequest("GET", at, nil)))
+
+	wrong := httptest.NewRequest("GET", "/oauth2callback?code=c0de&state=wrong", nil)
+	for _, cookie := range start.Cookies() {
+		wrong.AddCookie(cookie)
+	}
+	assert.Equal(t, http.StatusBadRequest, callback(wrong))
+	var tokens int64
+	db.Model(&models.GoogleToken{}).Count(&tokens)
+	assert.Zero(t, tokens)
+}
+
+func TestBookingListOnlyShowsBookingsTheCallerMaySee(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	h := newTestHandler(db)
+
+	organizer := models.Employee{Name: "Organizer", Email: "organizer@example.com"}
+	delegate := models.Employee{Name: "Delegate", Email: "delegate@example.com"}
+	other := models.Employee{Name: "Other", Email: "other@example.com"}
+	db.Create(&organizer)
+	db.Create(&delegate)
+	db.Create(&other)
+	db.Create(&models.Delegation{PrincipalID: organizer.ID, DelegateID: delegate.ID, CanEdit: true})
+	room := models.Room{Name: "Focus"}
+	db.Create(&room)
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	book := func(employeeID uint, hours time.Duration, status models.BookingStatus) uint {
+		booking := models.Booking{RoomID: room.ID, EmployeeID: employeeID, CreatedByID: employeeID,
+			StartTime: start.Add(hours * time.Hour), EndTime: start.Add((hours + 1) * time.Hour), Status: status}
+		db.Create(&booking)
+		return booking.ID
+	}
+	confirmed := book(organizer.ID, 0, models.BookingConfirmed)
+	cancelled := book(organizer.ID, 1, models.BookingCancelled)
+	others := book(other.ID, 2, models.BookingConfirmed)
+
+	list := func(target string, employeeID uint) ([]uint, string) {
+		rr := httptest.NewRecorder()
+		h.GetBookings(rr, loggedIn(t, h, "GET", target, nil, employeeID))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var bookings []models.BookingResponse
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &bookings))
+		ids := []uint{}
+		for _, b := range bookings {
+			ids = append(ids, b.ID)
+		}
+		return ids, rr.Header().Get("X-Next-Cursor")
+	}
+
+	ids, _ := list("/bookings", organizer.ID)
+	assert.Equal(t, []uint{confirmed, cancelled}, ids)
+	ids, _ = list("/bookings", delegate.ID)
+	assert.Equal(t, []uint{confirmed, cancelled}, ids)
+	ids, _ = list("/bookings", other.ID)
+	assert.Equal(t, []uint{others}, ids)
+
+	// Filters and pages narrow what the caller may see, never widen it.
+	ids, _ = list("/bookings?status=confirmed", delegate.ID)
+	assert.Equal(t, []uint{confirmed}, ids)
+	ids, _ = list(fmt.Sprintf("/bookings?employee_id=%d", other.ID), organizer.ID)
+	assert.Empty(t, ids)
+	ids, cursor := list("/bookings?limit=1", organizer.ID)
+	assert.Equal(t, []uint{confirmed}, ids)
+	ids, cursor = list("/bookings?limit=1&cursor="+url.QueryEscape(cursor), organizer.ID)
+	assert.Equal(t, []uint{cancelled}, ids)
+	assert.Empty(t, cursor)
+
+	rr := httptest.NewRecorder()
+	h.GetBookings(rr, httptest.NewRequest("GET", "/bookings", nil))
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestEmployeesAndRoomsNeedASessionAndLeaveOutBookings(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	h := newTestHandler(db)
+
+	organizer := models.Employee{Name: "Organizer", Email: "organizer@example.com"}
+	other := models.Employee{Name: "Other", Email: "other@example.com"}
+	db.Create(&organizer)
+	db.Create(&other)
+	room := models.Room{Name: "Focus"}
+	db.Create(&room)
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	db.Create(&models.Booking{RoomID: room.ID, EmployeeID: organizer.ID, CreatedByID: organizer.ID,
+		StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingConfirmed})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/employees", h.GetEmployees).Methods("GET")
+	router.HandleFunc("/employees/{id}", h.GetEmployeeByIDWithCache).Methods("GET")
+	router.HandleFunc("/rooms", h.GetRooms).Methods("GET")
+	targets := []string{
+		"/employees?include=bookings",
+		fmt.Sprintf("/employees/%d", organizer.ID),
+		"/rooms?include=bookings",
+	}
+	for _, target := range targets {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, http.StatusUnauthorized, rr.Code, target)
+
+		// Twice, so that the second read of the employee comes from the
+		// cache.
+		for i := 0; i < 2; i++ {
+			rr = httptest.NewRecorder()
+			router.ServeHTTP(rr, loggedIn(t, h, "GET", target, nil, other.ID))
+			assert.Equal(t, http.StatusOK, rr.Code, target)
+			assert.NotContains(t, rr.Body.String(), `"bookings"`, target)
+		}
+	}
+}
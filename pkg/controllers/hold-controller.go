@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+)
+
+// ConfirmBooking godoc
+// @Summary Confirm a tentative hold
+// @Description Converts a hold into a real booking. Rooms that require approval move to pending_approval instead of confirmed.
+// @Tags Bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} models.BookingResponse
+// @Failure 400 {object} apierror.Response "Invalid booking ID"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Failure 409 {object} apierror.Response "Booking is not a hold or the hold has expired"
+// @Router /bookings/{id}/confirm [post]
+func (h *Handler) ConfirmBooking(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	booking, err := h.service.ConfirmHold(employeeID, uint(id))
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to confirm booking")
+		return
+	}
+
+	h.announceBooking(r.Context(), &booking, booking.Room, booking.Employee, "Meeting Room Booking Confirmation")
+
+	resp, _ := json.Marshal(models.NewBookingResponse(booking))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// ReleaseExpiredHolds releases tentative holds whose expiry has passed and
+// lets the holder know, and returns how many were released.
+func (h *Handler) ReleaseExpiredHolds(ctx context.Context, now time.Time) (int, error) {
+	released, err := h.service.ReleaseExpiredHolds(now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch expired holds: %w", err)
+	}
+	for i := range released {
+		h.notifyHoldReleased(ctx, &released[i])
+	}
+	return len(released), nil
+}
+
+func (h *Handler) notifyHoldReleased(ctx context.Context, booking *models.Booking) {
+	message := fmt.Sprintf("Hi %s,\n\nYour hold on %s from %s to %s expired before it was confirmed and the room has been released.",
+		booking.Employee.Name, booking.Room.Name, booking.StartTime, booking.EndTime)
+	h.sendEmail(ctx, booking.Employee.Email, "Meeting Room Hold Released", message)
+}
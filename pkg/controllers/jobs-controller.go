@@ -0,0 +1,303 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/jobs"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/reminders"
+)
+
+// Jobs returns the background jobs of the app, to be registered with the
+// scheduler.
+func (h *Handler) Jobs(cfg config.JobsConfig) []jobs.Job {
+	grace := time.Duration(cfg.NoShowGrace)
+	retention := time.Duration(cfg.Retention)
+	return []jobs.Job{
+		{
+			Name:        "reminders",
+			Description: "Emails organizers before their bookings, at the stages they chose",
+			Schedule:    "@every 1m",
+			Run: func(ctx context.Context, now time.Time) (int, error) {
+				return reminders.New(h.repos.WithContext(ctx), h.mailer).Send(ctx, now)
+			},
+		},
+		{
+			Name:        "approval-expiry",
+			Description: "Rejects booking requests that approvers did not answer in time",
+			Schedule:    "@every 1m",
+			Run: func(ctx context.Context, now time.Time) (int, error) {
+				return h.WithContext(ctx).ExpirePendingApprovals(ctx, now)
+			},
+		},
+		{
+			Name:        "hold-expiry",
+			Description: "Releases tentative holds that were not confirmed in time",
+			Schedule:    "@every 1m",
+			Run: func(ctx context.Context, now time.Time) (int, error) {
+				return h.WithContext(ctx).ReleaseExpiredHolds(ctx, now)
+			},
+		},
+		{
+			Name:        "no-show-release",
+			Description: fmt.Sprintf("Releases confirmed bookings nobody checked in to within %s of the start", grace),
+			Schedule:    "@every 1m",
+			Run: func(ctx context.Context, now time.Time) (int, error) {
+				return h.WithContext(ctx).ReleaseNoShows(ctx, now, grace)
+			},
+		},
+		{
+			Name:        "booking-completion",
+			Description: "Marks bookings that ended as completed",
+			Schedule:    "@every 5m",
+			Run: func(ctx context.Context, now time.Time) (int, error) {
+				return h.WithContext(ctx).CompleteFinishedBookings(now)
+			},
+		},
+		{
+			Name:        "daily-digest",
+			Description: "Emails every employee the list of their meetings for the day",
+			Schedule:    "0 7 * * *",
+			Run: func(ctx context.Context, now time.Time) (int, error) {
+				return h.WithContext(ctx).SendDailyDigests(ctx, now)
+			},
+		},
+		{
+			Name:        "token-refresh",
+			Description: "Renews Google Calendar tokens before they expire",
+			Schedule:    "@every 15m",
+			Run: func(ctx context.Context, now time.Time) (int, error) {
+				return h.calendar.RefreshTokens(ctx, now.Add(30*time.Minute))
+			},
+		},
+		{
+			Name:        "data-retention",
+			Description: fmt.Sprintf("Deletes sent emails and reminder records older than %s, and expired sessions", retention),
+			Schedule:    "0 3 * * *",
+			Run: func(ctx context.Context, now time.Time) (int, error) {
+				return h.WithContext(ctx).PurgeOldData(now, retention)
+			},
+		},
+	}
+}
+
+// SendDailyDigests emails every active employee with meetings on the day of
+// now, as organizer or attendee, the list of them. It returns how many
+// emails were sent.
+func (h *Handler) SendDailyDigests(ctx context.Context, now time.Time) (int, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	bookings, err := h.repos.Bookings.Agenda(day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch today's bookings: %w", err)
+	}
+
+	type digest struct {
+		employee models.Employee
+		lines    []string
+	}
+	digests := map[uint]*digest{}
+	var order []uint
+	add := func(employee models.Employee, line string) {
+		if employee.ID == 0 || !employee.IsActive() {
+			return
+		}
+		d, ok := digests[employee.ID]
+		if !ok {
+			d = &digest{employee: employee}
+			digests[employee.ID] = d
+			order = append(order, employee.ID)
+		}
+		d.lines = append(d.lines, line)
+	}
+	for _, b := range bookings {
+		line := fmt.Sprintf("- %s to %s in %s", b.StartTime.In(now.Location()).Format("15:04"),
+			b.EndTime.In(now.Location()).Format("15:04"), b.Room.Name)
+		if b.Status != models.BookingConfirmed && b.Status != models.BookingCheckedIn {
+			line += fmt.Sprintf(" (%s)", strings.ReplaceAll(string(b.Status), "_", " "))
+		}
+		add(b.Employee, line)
+		for _, attendee := range b.Attendees {
+			if attendee.ID != b.EmployeeID {
+				add(attendee, line+fmt.Sprintf(", organized by %s", b.Employee.Name))
+			}
+		}
+	}
+
+	sent := 0
+	for _, id := range order {
+		d := digests[id]
+		message := fmt.Sprintf("Hi %s,\n\nYour meetings on %s:\n\n%s",
+			d.employee.Name, day.Format("Monday, 2 January"), strings.Join(d.lines, "\n"))
+		if err := h.mailer.Send(ctx, d.employee.Email, "Your Meetings Today", message); err != nil {
+			return sent, fmt.Errorf("failed to send digest to %s: %w", d.employee.Email, err)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// PurgeOldData deletes sent and failed emails and reminder records older
+// than the retention period, and the sessions that expired, and returns how
+// many rows were deleted.
+func (h *Handler) PurgeOldData(now time.Time, retention time.Duration) (int, error) {
+	cutoff := now.Add(-retention)
+	emails, err := h.repos.Outbox.Purge(cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge emails: %w", err)
+	}
+	sentReminders, err := h.repos.Reminders.Purge(cutoff)
+	if err != nil {
+		return int(emails), fmt.Errorf("failed to purge reminders: %w", err)
+	}
+	sessions, err := h.repos.Sessions.DeleteExpired(now)
+	if err != nil {
+		return int(emails + sentReminders), fmt.Errorf("failed to purge sessions: %w", err)
+	}
+	return int(emails + sentReminders + sessions), nil
+}
+
+// requireAdmin writes an error response and returns false unless the request
+// comes from an administrator.
+func (h *Handler) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if !h.service.IsAdmin(employeeID) {
+		apierror.Error(w, "Forbidden", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// GetJobs godoc
+// @Summary List background jobs
+// @Description Returns every background job with its schedule, whether it is paused, how its last run went, when it runs next and totals over all runs. Administrators only.
+// @Tags Jobs
+// @Produce json
+// @Success 200 {array} models.JobResponse
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /admin/jobs [get]
+func (h *Handler) GetJobs(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	list, descriptions, err := h.jobs.List()
+	if err != nil {
+		apierror.Error(w, "Failed to fetch jobs", http.StatusInternalServerError)
+		return
+	}
+	responses := make([]models.JobResponse, len(list))
+	for i, job := range list {
+		responses[i] = models.NewJobResponse(job, descriptions[job.Name])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+// RunJob godoc
+// @Summary Run a background job now
+// @Description Starts the job straight away in the background, even while it is paused. Administrators only.
+// @Tags Jobs
+// @Produce json
+// @Param name path string true "Job name, e.g. reminders"
+// @Success 202 {object} models.JobResponse
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Job not found"
+// @Failure 409 {object} apierror.Response "Job is already running"
+// @Failure 503 {object} apierror.Response "Server is shutting down"
+// @Router /admin/jobs/{name}/run [post]
+func (h *Handler) RunJob(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if err := h.jobs.Trigger(name); err != nil {
+		writeJobError(w, err, "Failed to run job")
+		return
+	}
+	h.writeJob(w, name, http.StatusAccepted)
+}
+
+// PauseJob godoc
+// @Summary Pause a background job
+// @Description Stops the scheduled runs of the job on every instance until it is resumed. A run in progress finishes. Administrators only.
+// @Tags Jobs
+// @Produce json
+// @Param name path string true "Job name, e.g. reminders"
+// @Success 200 {object} models.JobResponse
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Job not found"
+// @Router /admin/jobs/{name}/pause [post]
+func (h *Handler) PauseJob(w http.ResponseWriter, r *http.Request) {
+	h.setJobPaused(w, r, true)
+}
+
+// ResumeJob godoc
+// @Summary Resume a paused background job
+// @Description Schedules the job again from its next due time. Administrators only.
+// @Tags Jobs
+// @Produce json
+// @Param name path string true "Job name, e.g. reminders"
+// @Success 200 {object} models.JobResponse
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Job not found"
+// @Router /admin/jobs/{name}/resume [post]
+func (h *Handler) ResumeJob(w http.ResponseWriter, r *http.Request) {
+	h.setJobPaused(w, r, false)
+}
+
+func (h *Handler) setJobPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if err := h.jobs.SetPaused(name, paused); err != nil {
+		writeJobError(w, err, "Failed to update job")
+		return
+	}
+	h.writeJob(w, name, http.StatusOK)
+}
+
+func (h *Handler) writeJob(w http.ResponseWriter, name string, status int) {
+	job, description, err := h.jobs.Get(name)
+	if err != nil {
+		writeJobError(w, err, "Failed to fetch job")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.NewJobResponse(job, description))
+}
+
+func writeJobError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, jobs.ErrUnknownJob):
+		apierror.Error(w, "Job not found", http.StatusNotFound)
+	case errors.Is(err, jobs.ErrJobRunning):
+		apierror.Error(w, "Job is already running", http.StatusConflict)
+	case errors.Is(err, jobs.ErrStopped):
+		apierror.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+	default:
+		apierror.Error(w, fallback, http.StatusInternalServerError)
+	}
+}
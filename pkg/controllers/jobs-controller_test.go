@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupJobsDB(t *testing.T) (*gorm.DB, *Handler, *fakeMailer) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	deps := NewDependencies(db, testConfig())
+	mailer := &fakeMailer{}
+	deps.Mailer = mailer
+	deps.Calendar = fakeCalendar{}
+	return db, NewHandler(deps), mailer
+}
+
+func TestReleaseNoShows(t *testing.T) {
+	db, h, mailer := setupJobsDB(t)
+	organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+	db.Create(&organizer)
+	room := models.Room{Name: "Focus"}
+	db.Create(&room)
+
+	now := time.Now()
+	book := func(start time.Time, status models.BookingStatus) models.Booking {
+		b := models.Booking{RoomID: room.ID, EmployeeID: organizer.ID, CreatedByID: organizer.ID,
+			StartTime: start, EndTime: start.Add(time.Hour), Status: status}
+		assert.NoError(t, db.Create(&b).Error)
+		return b
+	}
+	unattended := book(now.Add(-20*time.Minute), models.BookingConfirmed)
+	book(now.Add(-20*time.Minute).Add(-2*time.Hour), models.BookingConfirmed) // already over
+	book(now.Add(-5*time.Minute), models.BookingConfirmed)                    // still within grace
+	book(now.Add(-30*time.Minute).Add(3*time.Hour), models.BookingConfirmed)  // not started
+	checkedIn := book(now.Add(-40*time.Minute), models.BookingCheckedIn)
+
+	released, err := h.ReleaseNoShows(context.Background(), now, 15*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+
+	var noShow, attended models.Booking
+	db.First(&noShow, unattended.ID)
+	assert.Equal(t, models.BookingNoShow, noShow.Status)
+	db.First(&attended, checkedIn.ID)
+	assert.Equal(t, models.BookingCheckedIn, attended.Status)
+	assert.Equal(t, []string{"ann@example.com: Meeting Room Released"}, mailer.sent)
+}
+
+func TestSendDailyDigests(t *testing.T) {
+	db, h, mailer := setupJobsDB(t)
+	ann := models.Employee{Name: "Ann", Email: "ann@example.com"}
+	bob := models.Employee{Name: "Bob", Email: "bob@example.com"}
+	idle := models.Employee{Name: "Idle", Email: "idle@example.com"}
+	db.Create(&ann)
+	db.Create(&bob)
+	db.Create(&idle)
+	room := models.Room{Name: "Focus"}
+	db.Create(&room)
+
+	now := time.Date(2030, 3, 4, 7, 0, 0, 0, time.Local)
+	bookings := []models.Booking{
+		{StartTime: now.Add(2 * time.Hour), Attendees: []models.Employee{bob}},
+		{StartTime: now.Add(4 * time.Hour), Status: models.BookingPendingApproval},
+		{StartTime: now.Add(24 * time.Hour)},                                 // tomorrow
+		{StartTime: now.Add(5 * time.Hour), Status: models.BookingCancelled}, // cancelled
+	}
+	for i := range bookings {
+		b := &bookings[i]
+		b.RoomID, b.EmployeeID, b.CreatedByID, b.EndTime = room.ID, ann.ID, ann.ID, b.StartTime.Add(time.Hour)
+		if b.Status == "" {
+			b.Status = models.BookingConfirmed
+		}
+		assert.NoError(t, db.Create(b).Error)
+	}
+
+	sent, err := h.SendDailyDigests(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, []string{"ann@example.com: Your Meetings Today", "bob@example.com: Your Meetings Today"}, mailer.sent)
+}
+
+func TestJobsAdminAPI(t *testing.T) {
+	db, h, _ := setupJobsDB(t)
+	admin := models.Employee{Name: "Admin", Email: "admin@example.com", Role: models.RoleAdmin}
+	employee := models.Employee{Name: "Ann", Email: "ann@example.com"}
+	db.Create(&admin)
+	db.Create(&employee)
+	for _, job := range h.Jobs(testConfig().Jobs) {
+		assert.NoError(t, h.jobs.Register(job))
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/jobs", h.GetJobs).Methods("GET")
+	router.HandleFunc("/admin/jobs/{name}/pause", h.PauseJob).Methods("POST")
+	router.HandleFunc("/admin/jobs/{name}/resume", h.ResumeJob).Methods("POST")
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "GET", "/admin/jobs", nil, employee.ID))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "GET", "/admin/jobs", nil, admin.ID))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var listed []models.JobResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &listed))
+	names := make([]string, len(listed))
+	for i, job := range listed {
+		names[i] = job.Name
+	}
+	assert.Equal(t, []string{"approval-expiry", "booking-completion", "daily-digest", "data-retention",
+		"hold-expiry", "no-show-release", "reminders", "token-refresh"}, names)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "POST", "/admin/jobs/daily-digest/pause", nil, admin.ID))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var paused models.JobResponse
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &paused))
+	assert.True(t, paused.Paused)
+	assert.Nil(t, paused.NextRunAt)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "POST", "/admin/jobs/daily-digest/resume", nil, admin.ID))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, loggedIn(t, h, "POST", "/admin/jobs/missing/pause", nil, admin.ID))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
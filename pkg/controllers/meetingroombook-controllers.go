@@ -1,22 +1,22 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
 	"strconv"
 
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
 	"github.com/koushikidey/go-meetingroombook/pkg/googleapi"
+	"github.com/koushikidey/go-meetingroombook/pkg/listing"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
-	session "github.com/koushikidey/go-meetingroombook/pkg/sessions"
 	"github.com/koushikidey/go-meetingroombook/pkg/utils"
-	"golang.org/x/oauth2"
 	"google.golang.org/api/calendar/v3"
 	"gorm.io/gorm"
 )
@@ -24,163 +24,114 @@ import (
 // CreateBooking godoc
 // @Summary Create a new booking
 // @Description Creates a new booking if the time and capacity constraints are satisfied. Sends confirmation email and adds Google Calendar event if linked.
+// @Description With "hold": true the slot is only held (status tentative) for hold_minutes (default 15) until confirmed via POST /bookings/{id}/confirm.
 // @Tags Bookings
 // @Accept json
 // @Produce json
 // @Param booking body models.BookingDTO true "Booking request data"
-// @Success 201 {object} models.BookingDTO
-// @Failure 400 {string} string "Invalid input or time conflict"
-// @Failure 401 {string} string "Unauthorized"
-// @Failure 409 {string} string "Booking time conflict"
-// @Failure 500 {string} string "Internal Server Error"
+// @Success 201 {object} models.BookingResponse
+// @Failure 400 {object} apierror.Response "Invalid input or time conflict"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 409 {object} apierror.Response "Booking time conflict"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
 // @Router /bookings [post]
-func CreateBooking(w http.ResponseWriter, r *http.Request) {
-	sessionData, _ := session.GetStore().Get(r, "session")
+func (h *Handler) CreateBooking(w http.ResponseWriter, r *http.Request) {
+	sessionData, _ := h.sessions.Get(r, "session")
 	employeeID, ok := sessionData.Values["employee_id"].(uint)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	config.Connect()
-	db := config.GetDB()
-
-	var booking models.Booking
-	if err := json.Unmarshal(body, &booking); err != nil {
-		http.Error(w, "Invalid JSON format", http.StatusBadRequest)
-		return
-	}
-	if booking.EndTime.Before(booking.StartTime) {
-		http.Error(w, "End time is before start time", http.StatusBadRequest)
-		return
-	}
-	if err := utils.ValidateTimeFormat(booking.StartTime); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	if err := utils.ValidateTimeFormat(booking.EndTime); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-
-	var existingBookings []models.Booking
-	db.Where("room_id = ?", booking.RoomID).Find(&existingBookings)
-
-	var room models.Room
-	db.Where("ID = ?", booking.RoomID).Find(&room)
-	numberOfAttendees := booking.NumAttendees
-	maxCapacity := *room.Capacity
-	if _, err := utils.IsCapacityExceeding(numberOfAttendees, maxCapacity); err != nil {
-		http.Error(w, "Capacity Exceeded", http.StatusBadRequest)
+	var input models.BookingDTO
+	if !decodeBody(w, r, &input) {
 		return
 	}
-
-	for _, b := range existingBookings {
-		conflict, err := utils.IsBookingConflict(booking.StartTime, booking.EndTime, b.StartTime, b.EndTime, b.Room, booking.Room)
-		if err != nil {
-			http.Error(w, "Error checking for conflicts", http.StatusInternalServerError)
-			return
-		}
-		if conflict {
-			http.Error(w, "Booking time conflicts with an existing booking", http.StatusConflict)
-			return
-		}
-	}
-
-	booking.EmployeeID = employeeID
-	if err := db.Create(&booking).Error; err != nil {
-		http.Error(w, "Could not create booking", http.StatusInternalServerError)
+	booking, err := h.service.Create(employeeID, input)
+	if err != nil {
+		writeServiceError(w, r, err, "Could not create booking")
 		return
 	}
 
-	var employee models.Employee
-	db.First(&employee, employeeID)
-	message := fmt.Sprintf("Hi %s,\n\nYour meeting room booking is confirmed from %s to %s in Room ID %d.",
-		employee.Name, booking.StartTime, booking.EndTime, booking.RoomID)
-	go utils.SendEmail(employee.Email, "Meeting Room Booking Confirmation", message)
-
-	var token models.GoogleToken
-	if err := db.Where("employee_id = ?", employeeID).First(&token).Error; err == nil {
-		oauthToken := &oauth2.Token{
-			AccessToken:  token.AccessToken,
-			RefreshToken: token.RefreshToken,
-			Expiry:       token.Expiry,
-		}
-
-		client := googleapi.GetClient(oauthToken)
-
-		srv, err := calendar.New(client)
-		if err == nil {
-			event := &calendar.Event{
-				Summary:     "Meeting Room Booking",
-				Location:    fmt.Sprintf("Room ID %d", booking.RoomID),
-				Description: fmt.Sprintf("Booked by %s", employee.Name),
-				Start: &calendar.EventDateTime{
-					DateTime: booking.StartTime.Format(time.RFC3339),
-					TimeZone: "Asia/Kolkata",
-				},
-				End: &calendar.EventDateTime{
-					DateTime: booking.EndTime.Format(time.RFC3339),
-					TimeZone: "Asia/Kolkata",
-				},
-			}
-
-			createdEvent, err := srv.Events.Insert("primary", event).Do()
-			if err != nil {
-				fmt.Printf("Failed to create Google Calendar event: %v\n", err)
-			} else {
+	h.announceBooking(r.Context(), &booking, booking.Room, booking.Employee, "Meeting Room Booking Confirmation")
 
-				booking.CalendarID = createdEvent.Id
-				result := db.Model(&models.Booking{}).Where("id = ?", booking.ID).Update("calendar_id", createdEvent.Id)
-				if result.Error != nil {
-					fmt.Println("Failed to update calendar ID:", result.Error)
-				}
-
-			}
-
-		} else {
-			fmt.Printf("Failed to create Google Calendar client: %v\n", err)
-		}
-	} else {
-		fmt.Println("Google Calendar not linked for employee:", employeeID)
-	}
-
-	resp, _ := json.Marshal(booking)
+	resp, _ := json.Marshal(models.NewBookingResponse(booking))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	w.Write(resp)
 }
 
 // GetBookings godoc
-// @Summary Get list of all bookings
-// @Description Retrieves all bookings along with all details
+// @Summary Get list of bookings
+// @Description Retrieves a page of the bookings the logged-in employee organizes or created, or may see as a delegate of their organizer, with their room and organizer. The next page's cursor is returned in the X-Next-Cursor and Link headers.
 // @Tags Bookings
 // @Produce json
-// @Success 200 {array} models.BookingDTO
-// @Failure 500 {string} string "Internal Server Error"
+// @Param status query string false "Comma-separated statuses, e.g. confirmed,checked_in"
+// @Param from query string false "Only bookings ending after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param to query string false "Only bookings starting before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param room_id query string false "Comma-separated room IDs"
+// @Param employee_id query string false "Comma-separated organizer IDs"
+// @Param location query string false "Room location"
+// @Param min_capacity query int false "Minimum room capacity"
+// @Param max_capacity query int false "Maximum room capacity"
+// @Param sort query string false "id, start_time, end_time, created_at or status; prefix with - for descending (default start_time)"
+// @Param fields query string false "Comma-separated JSON fields to return"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from X-Next-Cursor"
+// @Success 200 {array} models.BookingResponse
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} apierror.Response "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
 // @Router /bookings [get]
-func GetBookings(w http.ResponseWriter, r *http.Request) {
-	// session, _ := session.GetStore().Get(r, "session")
-	// employeeID, ok := session.Values["employee_id"].(uint)
-	// if !ok {
-	// 	http.Error(w, "Unauthorized", http.StatusUnauthorized)
-	// 	return
-	// }
+func (h *Handler) GetBookings(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.sessions.Get(r, "session")
+	employeeID, ok := session.Values["employee_id"].(uint)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
-	var bookings []models.Booking
-	config.Connect()
-	db := config.GetDB()
-	//db.Preload("Room").Preload("Employee").Where("employee_id = ?", employeeID).Find(&bookings)
-	db.Preload("Room").Preload("Employee").Find(&bookings)
-	resp, _ := json.Marshal(bookings)
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(resp)
+	list, err := listing.Parse(r.URL.Query(), bookingListSpec)
+	if err != nil {
+		apierror.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookings, err := h.repos.Bookings.List(list, employeeID)
+	if err != nil {
+		writeListError(w, err, "Failed to fetch bookings")
+		return
+	}
+	list.Page(w, r, &bookings)
+	list.WriteJSON(w, models.NewBookingResponses(bookings))
+}
+
+var bookingListSpec = listing.Spec{
+	Sorts: map[string]listing.SortKey{
+		"id":         {Column: "id", Field: "ID", Kind: listing.Number},
+		"start_time": {Column: "start_time", Field: "StartTime", Kind: listing.Time},
+		"end_time":   {Column: "end_time", Field: "EndTime", Kind: listing.Time},
+		"created_at": {Column: "created_at", Field: "CreatedAt", Kind: listing.Time},
+		"status":     {Column: "status", Field: "Status", Kind: listing.String},
+	},
+	DefaultSort: "start_time",
+	Filters: map[string]listing.Filter{
+		"status": func(db *gorm.DB, value string) (*gorm.DB, error) {
+			statuses, err := parseStatusFilter(value)
+			if err != nil {
+				return nil, err
+			}
+			return db.Where("status IN ?", statuses), nil
+		},
+		"from":         listing.Timestamp("end_time > ?"),
+		"to":           listing.Timestamp("start_time < ?"),
+		"room_id":      listing.IDs("room_id"),
+		"employee_id":  listing.IDs("employee_id"),
+		"location":     listing.Text("room_id IN (SELECT id FROM rooms WHERE location = ?)"),
+		"min_capacity": listing.Int("room_id IN (SELECT id FROM rooms WHERE capacity >= ?)"),
+		"max_capacity": listing.Int("room_id IN (SELECT id FROM rooms WHERE capacity <= ?)"),
+	},
 }
 
 // GetBooking godoc
@@ -189,259 +140,203 @@ func GetBookings(w http.ResponseWriter, r *http.Request) {
 // @Tags Bookings
 // @Produce json
 // @Param id path uint true "Booking ID"
-// @Success 200 {object} models.BookingDTO
-// @Failure 400 {string} string "Invalid ID"
-// @Failure 404 {string} string "Booking not found"
-// @Failure 500 {string} string "Error marshalling data"
+// @Success 200 {object} models.BookingResponse
+// @Failure 400 {object} apierror.Response "Invalid ID"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Failure 500 {object} apierror.Response "Error marshalling data"
 // @Router /bookings/{id} [get]
-func GetBooking(w http.ResponseWriter, r *http.Request) {
-	session, _ := session.GetStore().Get(r, "session")
+func (h *Handler) GetBooking(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.sessions.Get(r, "session")
 	employeeID, ok := session.Values["employee_id"].(uint)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
-		return
-	}
-
-	var booking models.Booking
-	config.Connect()
-	db := config.GetDB()
-	result := db.Preload("Room").Preload("Employee").First(&booking, id)
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			http.Error(w, "Booking not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "Failed to retrieve booking", http.StatusInternalServerError)
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
 		return
 	}
 
-	if booking.EmployeeID != employeeID {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+	booking, err := h.service.Get(employeeID, uint(id))
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to retrieve booking")
 		return
 	}
 
-	resp, _ := json.Marshal(booking)
+	resp, _ := json.Marshal(models.NewBookingResponse(booking))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(resp)
 }
 
 // UpdateBooking godoc
 // @Summary Update existing booking details
-// @Description Allows an authenticated employee to update their booking
+// @Description Allows an authenticated employee, or a delegate with edit permission, to update a booking
 // @Tags Bookings
 // @Accept json
 // @Produce json
 // @Param id path int true "Booking ID"
-// @Param booking body models.BookingDTO true "Updated booking details"
-// @Success 200 {object} models.BookingDTO
-// @Failure 400 {string} string "Invalid Employee ID or JSON input"
-// @Failure 401 {string} string "Unauthorized (not logged in)"
-// @Failure 403 {string} string "Forbidden (trying to update another employee's booking)"
-// @Failure 404 {string} string "Booking not found"
-// @Failure 500 {string} string "Failed to update booking"
+// @Param booking body models.BookingUpdateDTO true "Updated booking details"
+// @Success 200 {object} models.BookingResponse
+// @Failure 400 {object} apierror.Response "Invalid Employee ID or JSON input"
+// @Failure 401 {object} apierror.Response "Unauthorized (not logged in)"
+// @Failure 403 {object} apierror.Response "Forbidden (trying to update another employee's booking)"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Failure 500 {object} apierror.Response "Failed to update booking"
 // @Router /booking/{id} [put]
-func UpdateBooking(w http.ResponseWriter, r *http.Request) {
-	session, _ := session.GetStore().Get(r, "session")
+func (h *Handler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.sessions.Get(r, "session")
 	employeeID, ok := session.Values["employee_id"].(uint)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
-		return
-	}
-
-	var existing models.Booking
-	config.Connect()
-	db := config.GetDB()
-	if err := db.First(&existing, id).Error; err != nil {
-		http.Error(w, "Booking not found", http.StatusNotFound)
-		return
-	}
-
-	if existing.EmployeeID != employeeID {
-		http.Error(w, "Forbidden", http.StatusForbidden)
-		return
-	}
-
-	body, _ := io.ReadAll(r.Body)
-	var updated models.Booking
-	if err := json.Unmarshal(body, &updated); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
-	}
-
-	if err := utils.ValidateTimeFormat(updated.StartTime); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
 		return
 	}
-	if err := utils.ValidateTimeFormat(updated.EndTime); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	var existingBookings []models.Booking
-	db.Where("room_id = ?", updated.RoomID).Find(&existingBookings)
 
-	var room models.Room
-	db.Where("ID = ?", updated.RoomID).Find(&room)
-	numberOfAttendees := updated.NumAttendees
-	maxCapacity := *room.Capacity
-	if _, err := utils.IsCapacityExceeding(numberOfAttendees, maxCapacity); err != nil {
-		http.Error(w, "Capacity Exceeded", http.StatusBadRequest)
+	var input models.BookingUpdateDTO
+	if !decodeBody(w, r, &input) {
 		return
 	}
-
-	var conflicts []models.Booking
-	db.Where("room_id = ? AND id != ?", updated.RoomID, id).Find(&conflicts)
-	for _, b := range conflicts {
-		conflict, err := utils.IsBookingConflict(updated.StartTime, updated.EndTime, b.StartTime, b.EndTime, updated.Room, b.Room)
-		if err != nil {
-			http.Error(w, "Error checking for conflicts", http.StatusInternalServerError)
-			return
-		}
-		if conflict {
-			http.Error(w, "Updated time conflicts with another booking", http.StatusConflict)
-			return
-		}
-	}
-	err = googleapi.DeleteCalendarEvent(existing.EmployeeID, existing.CalendarID)
+	updated, previous, err := h.service.Update(employeeID, uint(id), input)
 	if err != nil {
-		log.Println("Failed to delete calendar event:", err)
-	}
-
-	existing.RoomID = updated.RoomID
-	existing.EmployeeID = updated.EmployeeID
-	existing.StartTime = updated.StartTime
-	existing.EndTime = updated.EndTime
-	existing.NumAttendees = updated.NumAttendees
-
-	if err := db.Save(&existing).Error; err != nil {
-		http.Error(w, "Failed to update booking", http.StatusInternalServerError)
+		writeServiceError(w, r, err, "Failed to update booking")
 		return
 	}
 
-	var employee models.Employee
-	db.First(&employee, employeeID)
-	message := fmt.Sprintf("Hi %s,\n\nYour meeting room booking is confirmed from %s to %s in Room ID %d.",
-		employee.Name, existing.StartTime, existing.EndTime, existing.RoomID)
-	go utils.SendEmail(employee.Email, "Meeting Room Booking Updated and Confirmed", message)
-
-	var token models.GoogleToken
-	if err := db.Where("employee_id = ?", employeeID).First(&token).Error; err == nil {
-		oauthToken := &oauth2.Token{
-			AccessToken:  token.AccessToken,
-			RefreshToken: token.RefreshToken,
-			Expiry:       token.Expiry,
-		}
-
-		client := googleapi.GetClient(oauthToken)
-
-		srv, err := calendar.New(client)
-		if err == nil {
-			event := &calendar.Event{
-				Summary:     "Meeting Room Booking",
-				Location:    fmt.Sprintf("Room ID %d", existing.RoomID),
-				Description: fmt.Sprintf("Booked by %s", employee.Name),
-				Start: &calendar.EventDateTime{
-					DateTime: existing.StartTime.Format(time.RFC3339),
-					TimeZone: "Asia/Kolkata",
-				},
-				End: &calendar.EventDateTime{
-					DateTime: existing.EndTime.Format(time.RFC3339),
-					TimeZone: "Asia/Kolkata",
-				},
-			}
-			createdEvent, err := srv.Events.Insert("primary", event).Do()
-			if err != nil {
-				fmt.Printf("Failed to create Google Calendar event: %v\n", err)
-			} else {
-
-				existing.CalendarID = createdEvent.Id
-				result := db.Model(&models.Booking{}).Where("id = ?", existing.ID).Update("calendar_id", createdEvent.Id)
-				if result.Error != nil {
-					fmt.Println("Failed to update calendar ID:", result.Error)
-				}
+	h.removeCalendarEvent(r.Context(), previous)
+	h.announceBooking(r.Context(), &updated, updated.Room, updated.Employee, "Meeting Room Booking Updated and Confirmed")
 
-			}
-
-		} else {
-			fmt.Printf("Failed to create Google Calendar client: %v\n", err)
-		}
-	} else {
-		fmt.Println("Google Calendar not linked for employee:", employeeID)
-	}
-
-	resp, _ := json.Marshal(existing)
+	resp, _ := json.Marshal(models.NewBookingResponse(updated))
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(resp)
 }
 
 // DeleteBooking godoc
-// @Summary Delete existing booking details
-// @Description Allows an authenticated employee to delete their booking
+// @Summary Cancel existing booking
+// @Description Allows an authenticated employee, or a delegate with cancel permission, to cancel a booking. The booking is kept with status cancelled.
 // @Tags Bookings
 // @Accept json
 // @Produce json
 // @Param id path int true "Booking ID"
-// @Param booking body models.BookingDTO true "Deleted booking details"
-// @Failure 400 {string} string "Invalid Employee ID or JSON input"
-// @Failure 401 {string} string "Unauthorized (not logged in)"
-// @Failure 403 {string} string "Forbidden (trying to update another employee's booking)"
-// @Failure 404 {string} string "Booking not found"
-// @Failure 500 {string} string "Failed to delete booking"
+// @Param booking body models.BookingTransitionDTO false "Optional cancellation reason"
+// @Success 204 "Booking cancelled"
+// @Failure 400 {object} apierror.Response "Invalid Employee ID or JSON input"
+// @Failure 401 {object} apierror.Response "Unauthorized (not logged in)"
+// @Failure 403 {object} apierror.Response "Forbidden (trying to update another employee's booking)"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Failure 409 {object} apierror.Response "Booking can no longer be cancelled"
+// @Failure 500 {object} apierror.Response "Failed to cancel booking"
 // @Router /booking/{id} [delete]
-func DeleteBooking(w http.ResponseWriter, r *http.Request) {
-	session, _ := session.GetStore().Get(r, "session")
+func (h *Handler) DeleteBooking(w http.ResponseWriter, r *http.Request) {
+	session, _ := h.sessions.Get(r, "session")
 	employeeID, ok := session.Values["employee_id"].(uint)
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
 
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
 		return
 	}
-	config.Connect()
-	db := config.GetDB()
-	var booking models.Booking
-	if err := db.First(&booking, id).Error; err != nil {
-		http.Error(w, "Booking not found", http.StatusNotFound)
+
+	var input models.BookingTransitionDTO
+	if err := utils.ParseBody(r, &input); err != nil {
+		apierror.InvalidJSON(w, err)
 		return
 	}
+	booking, err := h.service.Cancel(employeeID, uint(id), input.Reason)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to cancel booking")
+		return
+	}
+
+	h.removeCalendarEvent(r.Context(), booking)
+	employee := booking.Employee
+	message := fmt.Sprintf("Hi %s,\n\nYour meeting room booking from %s to %s in Room ID %d has been cancelled.",
+		employee.Name, booking.StartTime, booking.EndTime, booking.RoomID)
+	h.sendEmail(r.Context(), employee.Email, "Meeting Room Booking Cancelled", message)
+
+	w.WriteHeader(http.StatusNoContent)
+}
 
-	if booking.EmployeeID != employeeID {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+// writeListError reports a failed list query: a bad filter or cursor is the
+// client's fault, anything else is reported with the fallback message.
+func writeListError(w http.ResponseWriter, err error, fallback string) {
+	if listing.IsQueryError(err) {
+		apierror.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	apierror.Error(w, fallback, http.StatusInternalServerError)
+}
+
+// announceBooking sends the notifications that match the booking's status: a
+// hold notice, an approval request, or a confirmation with a calendar event.
+func (h *Handler) announceBooking(ctx context.Context, booking *models.Booking, room models.Room, employee models.Employee, subject string) {
+	switch booking.Status {
+	case models.BookingTentative:
+		message := fmt.Sprintf("Hi %s,\n\nRoom ID %d is held for you from %s to %s until %s. Confirm the booking before then or the room will be released.",
+			employee.Name, booking.RoomID, booking.StartTime, booking.EndTime, booking.ExpiresAt.Format(time.RFC1123))
+		h.sendEmail(ctx, employee.Email, "Meeting Room Hold Placed", message)
+	case models.BookingPendingApproval:
+		h.requestApproval(ctx, *booking, room, employee)
+	default:
+		message := fmt.Sprintf("Hi %s,\n\nYour meeting room booking is confirmed from %s to %s in Room ID %d.",
+			employee.Name, booking.StartTime, booking.EndTime, booking.RoomID)
+		h.sendEmail(ctx, employee.Email, subject, message)
+		h.addCalendarEvent(ctx, booking, employee)
+	}
+}
 
-	if err := db.Delete(&booking).Error; err != nil {
-		http.Error(w, "Failed to delete booking", http.StatusInternalServerError)
+// addCalendarEvent creates a Google Calendar event for the booking when the
+// employee has linked their calendar, and stores the event ID on the booking.
+func (h *Handler) addCalendarEvent(ctx context.Context, booking *models.Booking, employee models.Employee) {
+	event := &calendar.Event{
+		Summary:     "Meeting Room Booking",
+		Location:    fmt.Sprintf("Room ID %d", booking.RoomID),
+		Description: fmt.Sprintf("Booked by %s", employee.Name),
+		Start: &calendar.EventDateTime{
+			DateTime: booking.StartTime.Format(time.RFC3339),
+			TimeZone: "Asia/Kolkata",
+		},
+		End: &calendar.EventDateTime{
+			DateTime: booking.EndTime.Format(time.RFC3339),
+			TimeZone: "Asia/Kolkata",
+		},
+		Reminders: h.calendarReminders(employee.ID),
+	}
+
+	eventID, ok := h.insertCalendarEvent(ctx, employee.ID, event)
+	if !ok {
 		return
 	}
+	booking.CalendarID = eventID
+	if err := h.repos.Bookings.SetCalendarID(booking.ID, eventID); err != nil {
+		logging.FromContext(ctx).Error("Failed to update calendar ID", "booking_id", booking.ID, "error", err)
+	}
+}
 
-	err = googleapi.DeleteCalendarEvent(booking.EmployeeID, booking.CalendarID)
+// insertCalendarEvent adds the event to the employee's primary Google Calendar
+// if they have linked it.
+func (h *Handler) insertCalendarEvent(ctx context.Context, employeeID uint, event *calendar.Event) (string, bool) {
+	eventID, err := h.calendar.InsertEvent(ctx, employeeID, event)
+	if errors.Is(err, googleapi.ErrNotLinked) {
+		logging.FromContext(ctx).Info("Google Calendar not linked", "employee_id", employeeID)
+		return "", false
+	}
 	if err != nil {
-		log.Println("Failed to delete calendar event:", err)
+		logging.FromContext(ctx).Error("Failed to create Google Calendar event", "employee_id", employeeID, "error", err)
+		return "", false
 	}
-	var employee models.Employee
-	db.First(&employee, employeeID)
-	message := fmt.Sprintf("Hi %s,\n\nYour meeting room booking which was confirmed from %s to %s in Room ID %d has been deleted.",
-		employee.Name, booking.StartTime, booking.EndTime, booking.RoomID)
-	go utils.SendEmail(employee.Email, "Meeting Room Booking Cancelled", message)
-
-	w.WriteHeader(http.StatusNoContent)
-}
\ No newline at end of file
+	return eventID, true
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"google.golang.org/api/calendar/v3"
+)
+
+// GetReminderSettings godoc
+// @Summary Get my reminder settings
+// @Description Returns when and how the logged-in employee is reminded of the bookings they organize. Employees who never chose get one email ten minutes before the start.
+// @Tags Reminders
+// @Produce json
+// @Success 200 {object} models.ReminderSettingsResponse
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /reminder-settings [get]
+func (h *Handler) GetReminderSettings(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := h.repos.Reminders.Settings(employeeID)
+	if err != nil {
+		apierror.Error(w, "Failed to fetch reminder settings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.NewReminderSettingsResponse(settings))
+}
+
+// UpdateReminderSettings godoc
+// @Summary Choose my reminders
+// @Description Replaces the reminder stages of the logged-in employee, e.g. a day, an hour and ten minutes before each booking, each by email and/or as a Google Calendar popup. An empty list turns reminders off. Calendar popups apply to events created from now on.
+// @Tags Reminders
+// @Accept json
+// @Produce json
+// @Param settings body models.ReminderSettingsDTO true "Reminder stages"
+// @Success 200 {object} models.ReminderSettingsResponse
+// @Failure 400 {object} apierror.Response "Invalid JSON or stages"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /reminder-settings [put]
+func (h *Handler) UpdateReminderSettings(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input models.ReminderSettingsDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	settings := models.ReminderSettings{EmployeeID: employeeID, Stages: input.Stages}
+	if err := h.repos.Reminders.SaveSettings(&settings); err != nil {
+		apierror.Error(w, "Could not save reminder settings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.NewReminderSettingsResponse(settings))
+}
+
+// calendarReminders returns the popups the employee chose for their Google
+// Calendar events, or nil to keep the calendar's defaults for employees who
+// never chose.
+func (h *Handler) calendarReminders(employeeID uint) *calendar.EventReminders {
+	settings, err := h.repos.Reminders.Settings(employeeID)
+	if err != nil || settings.UpdatedAt.IsZero() {
+		return nil
+	}
+	overrides := []*calendar.EventReminder{}
+	for _, minutes := range settings.StagesFor(models.ReminderCalendar) {
+		overrides = append(overrides, &calendar.EventReminder{Method: "popup", Minutes: int64(minutes)})
+	}
+	return &calendar.EventReminders{Overrides: overrides, ForceSendFields: []string{"UseDefault", "Overrides"}}
+}
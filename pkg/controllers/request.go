@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+)
+
+// decodeBody parses the JSON request body into dst and validates it if dst
+// knows how. On failure it writes the error response and returns false.
+func decodeBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := utils.ParseBody(r, dst); err != nil {
+		apierror.InvalidJSON(w, err)
+		return false
+	}
+	if v, ok := dst.(validation.Validable); ok {
+		if err := v.Validate(); err != nil {
+			apierror.Validation(w, err)
+			return false
+		}
+	}
+	return true
+}
@@ -2,15 +2,15 @@ package controllers
 
 import (
 	"encoding/json"
-	"fmt"
-	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
 	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/listing"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
-	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
 	"gorm.io/gorm"
 )
 
@@ -19,23 +19,23 @@ import (
 
 // 	body, err := io.ReadAll(r.Body)
 // 	if err != nil {
-// 		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+// 		apierror.Error(w, "Failed to read request body", http.StatusBadRequest)
 // 		return
 // 	}
 
 // 	if err := json.Unmarshal(body, &room); err != nil {
-// 		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+// 		apierror.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
 // 		return
 // 	}
 // 	config.Connect()
 // 	db := config.GetDB()
 // 	if err := db.Create(&room).Error; err != nil {
-// 		http.Error(w, "Could not create room: "+err.Error(), http.StatusInternalServerError)
+// 		apierror.Error(w, "Could not create room: "+err.Error(), http.StatusInternalServerError)
 // 		return
 // 	}
 // 	var createdRoom models.Room
 // 	if err := db.First(&createdRoom, room.ID).Error; err != nil {
-// 		http.Error(w, "Could not retrieve created room "+err.Error(), http.StatusInternalServerError)
+// 		apierror.Error(w, "Could not retrieve created room "+err.Error(), http.StatusInternalServerError)
 // 		return
 // 	}
 
@@ -54,118 +54,217 @@ import (
 //		w.Header().Set("Content-type", "application/json")
 //		w.Write(resp)
 //	}
+
+// GetRoomsWithDB serves GET /rooms from db with the default dependencies.
 func GetRoomsWithDB(db *gorm.DB) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var rooms []models.Room
-		db.Preload("Bookings.Room").Preload("Bookings.Employee").Find(&rooms)
+	return NewHandler(NewDependencies(db, config.Default())).GetRooms
+}
 
-		resp, _ := json.Marshal(rooms)
-		w.Header().Set("Content-type", "application/json")
-		w.Write(resp)
-	}
+var roomListSpec = listing.Spec{
+	Sorts: map[string]listing.SortKey{
+		"id":         {Column: "id", Field: "ID", Kind: listing.Number},
+		"name":       {Column: "name", Field: "Name", Kind: listing.String},
+		"location":   {Column: "location", Field: "Location", Kind: listing.String},
+		"floor":      {Column: "floor", Field: "Floor", Kind: listing.Number},
+		"capacity":   {Column: "COALESCE(capacity, 0)", Field: "Capacity", Kind: listing.Number},
+		"created_at": {Column: "created_at", Field: "CreatedAt", Kind: listing.Time},
+	},
+	DefaultSort: "id",
+	Filters: map[string]listing.Filter{
+		"id":                listing.IDs("id"),
+		"location":          listing.Text("location = ?"),
+		"floor":             listing.Int("floor = ?"),
+		"min_capacity":      listing.Int("capacity >= ?"),
+		"max_capacity":      listing.Int("capacity <= ?"),
+		"requires_approval": listing.Bool("requires_approval = ?"),
+	},
 }
 
 // GetRooms godoc
-// @Summary Get list of all rooms
-// @Description Retrieves all rooms along with their bookings and booked employees
+// @Summary Get list of rooms
+// @Description Retrieves a page of rooms. A room's bookings are listed by GET /bookings?room_id=. The next page's cursor is returned in the X-Next-Cursor and Link headers.
 // @Tags Rooms
 // @Produce json
-// @Success 200 {array} models.RoomDTO
-// @Failure 500 {string} string "Internal Server Error"
+// @Param id query string false "Comma-separated room IDs"
+// @Param location query string false "Location"
+// @Param floor query int false "Floor"
+// @Param min_capacity query int false "Minimum capacity"
+// @Param max_capacity query int false "Maximum capacity"
+// @Param requires_approval query bool false "Only rooms that do or do not require approval"
+// @Param sort query string false "id, name, location, floor, capacity or created_at; prefix with - for descending (default id)"
+// @Param fields query string false "Comma-separated JSON fields to return"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param cursor query string false "Cursor from X-Next-Cursor"
+// @Success 200 {array} models.RoomResponse
+// @Header 200 {string} X-Next-Cursor "Cursor of the next page, absent on the last page"
+// @Failure 400 {object} apierror.Response "Invalid filter, sort, limit or cursor"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
 // @Router /rooms [get]
-func GetRooms(w http.ResponseWriter, r *http.Request) {
-	//config.Connect()
-	db := config.GetDB()
-	GetRoomsWithDB(db)(w, r)
+func (h *Handler) GetRooms(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.currentEmployeeID(r); !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	list, err := listing.Parse(r.URL.Query(), roomListSpec)
+	if err != nil {
+		apierror.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rooms, err := h.repos.Rooms.List(list)
+	if err != nil {
+		writeListError(w, err, "Failed to fetch rooms")
+		return
+	}
+	list.Page(w, r, &rooms)
+	list.WriteJSON(w, models.NewRoomResponses(rooms))
 }
 
+// CreateRoomWithDB serves POST /rooms against db with the default
+// dependencies.
 func CreateRoomWithDB(db *gorm.DB) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var room models.Room
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, "Failed to read request body", http.StatusBadRequest)
-			return
-		}
-
-		if err := json.Unmarshal(body, &room); err != nil {
-			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
-			return
-		}
-		if err := db.Create(&room).Error; err != nil {
-			http.Error(w, "Could not create room: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-		var createdRoom models.Room
-		if err := db.First(&createdRoom, room.ID).Error; err != nil {
-			http.Error(w, "Could not retrieve created room "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(createdRoom)
+	return NewHandler(NewDependencies(db, config.Default())).CreateRoom
+}
 
+// approverGroupExists checks an optional approver group reference, writing an
+// error response if it points nowhere.
+func (h *Handler) approverGroupExists(w http.ResponseWriter, id *uint) bool {
+	if id == nil {
+		return true
+	}
+	exists, err := h.repos.Rooms.ApproverGroupExists(*id)
+	if err != nil {
+		apierror.Error(w, "Failed to look up approver group", http.StatusInternalServerError)
+		return false
 	}
+	if !exists {
+		apierror.Field(w, "approver_group_id", validation.CodeNotFound, "approver group does not exist")
+		return false
+	}
+	return true
 }
 
 // CreateRoom godoc
 // @Summary Create a new room
-// @Description Adds a new meeting room to the system
+// @Description Adds a new meeting room to the system. Administrators only.
 // @Tags Rooms
 // @Accept json
 // @Produce json
 // @Param room body models.RoomDTO true "Room details"
-// @Success 201 {object} models.RoomDTO
-// @Failure 400 {string} string "Invalid JSON or bad request"
-// @Failure 500 {string} string "Internal Server Error"
+// @Success 201 {object} models.RoomResponse
+// @Failure 400 {object} apierror.Response "Invalid JSON or bad request"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
 // @Router /rooms [post]
-func CreateRoom(w http.ResponseWriter, r *http.Request) {
-	//config.Connect()
-	db := config.GetDB()
-	CreateRoomWithDB(db)(w, r)
+func (h *Handler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+
+	var input models.RoomDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+	if !h.approverGroupExists(w, input.ApproverGroupID) {
+		return
+	}
+
+	room := models.Room{
+		Name:             input.Name,
+		Capacity:         input.Capacity,
+		Location:         input.Location,
+		Floor:            input.Floor,
+		Amenities:        input.Amenities,
+		RequiresApproval: input.RequiresApproval,
+		ApproverGroupID:  input.ApproverGroupID,
+	}
+	if err := h.repos.Rooms.Create(&room); err != nil {
+		apierror.Error(w, "Could not create room: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	createdRoom, err := h.repos.Rooms.Get(room.ID)
+	if err != nil {
+		apierror.Error(w, "Could not retrieve created room "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.NewRoomResponse(createdRoom))
 }
 
 // UpdateRoom godoc
 // @Summary Update room details
-// @Description Update details of existing room by ID
+// @Description Update details of existing room by ID, including whether bookings need approval and by whom. Administrators only.
 // @Tags Rooms
 // @Accept json
 // @Produce json
 // @Param id path int true "Room ID"
-// @Param room body models.RoomDTO true "Room details to update"
-// @Success 200 {object} models.RoomDTO
-// @Failure 400 {string} string "Invalid JSON or bad request"
-// @Failure 404 {string} string "Room not found"
-// @Failure 500 {string} string "Internal Server Error"
+// @Param room body models.RoomUpdateDTO true "Room details to update"
+// @Success 200 {object} models.RoomResponse
+// @Failure 400 {object} apierror.Response "Invalid JSON or bad request"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Room not found"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
 // @Router /rooms/{id} [put]
-func UpdateRoom(w http.ResponseWriter, r *http.Request) {
-	var updateRoom = &models.Room{}
-	utils.ParseBody(r, updateRoom)
-	vars := mux.Vars(r)
-	room_id := vars["id"]
-	ID, err := strconv.ParseInt(room_id, 0, 0)
+func (h *Handler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdmin(w, r) {
+		return
+	}
+	ID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 0)
+	if err != nil {
+		apierror.Error(w, "Invalid room ID", http.StatusBadRequest)
+		return
+	}
+	var updateRoom models.RoomUpdateDTO
+	if !decodeBody(w, r, &updateRoom) {
+		return
+	}
+
+	getRoom, err := h.repos.Rooms.Get(uint(ID))
 	if err != nil {
-		fmt.Println("Error while parsing")
+		apierror.Error(w, "Room not found", http.StatusNotFound)
+		return
 	}
-	config.Connect()
-	db := config.GetDB()
-	var getRoom models.Room
-	db = db.Where("ID=?", ID).Find(&getRoom)
-	if updateRoom.Name != "" {
-		getRoom.Name = updateRoom.Name
+	if updateRoom.Name != nil {
+		getRoom.Name = *updateRoom.Name
 	}
-	if updateRoom.Location != "" {
-		getRoom.Location = updateRoom.Location
+	if updateRoom.Location != nil {
+		getRoom.Location = *updateRoom.Location
 	}
 	if updateRoom.Capacity != nil {
 		getRoom.Capacity = updateRoom.Capacity
 	}
+	if updateRoom.Floor != nil {
+		getRoom.Floor = *updateRoom.Floor
+	}
+	if updateRoom.Amenities != nil {
+		getRoom.Amenities = *updateRoom.Amenities
+	}
+	if updateRoom.RequiresApproval != nil {
+		getRoom.RequiresApproval = *updateRoom.RequiresApproval
+	}
+	if updateRoom.ApproverGroupID != nil {
+		getRoom.ApproverGroupID = updateRoom.ApproverGroupID
+	}
+	if getRoom.RequiresApproval && getRoom.ApproverGroupID == nil {
+		apierror.Field(w, "approver_group_id", validation.CodeRequired, "is required for rooms that require approval")
+		return
+	}
+	if !h.approverGroupExists(w, updateRoom.ApproverGroupID) {
+		return
+	}
 
-	db.Save(&getRoom)
-	res, _ := json.Marshal(&getRoom)
+	if err := h.repos.Rooms.Save(&getRoom); err != nil {
+		apierror.Error(w, "Failed to update room", http.StatusInternalServerError)
+		return
+	}
+	res, _ := json.Marshal(models.NewRoomResponse(getRoom))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	w.Write(res)
-
-}
\ No newline at end of file
+}
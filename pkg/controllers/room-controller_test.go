@@ -7,12 +7,16 @@ import (
 	"testing"
 
 	"bytes"
+	"fmt"
 	"io"
 
 	// "log"
 	// "os"
 
 	// "github.com/joho/godotenv"
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
@@ -24,7 +28,7 @@ func setupTestDBforGet() *gorm.DB {
 	if err != nil {
 		panic("failed to connect test database")
 	}
-	db.AutoMigrate(&models.Room{}, &models.Booking{}, &models.Employee{})
+	db.AutoMigrate(&models.Room{}, &models.Booking{}, &models.Employee{}, &models.Session{})
 	cap := 10
 	room := models.Room{
 		//ID:       1,
@@ -38,19 +42,18 @@ func setupTestDBforGet() *gorm.DB {
 
 func TestGetRoomsWithDB(t *testing.T) {
 	db := setupTestDBforGet()
-	req, err := http.NewRequest("GET", "/rooms", nil)
-	assert.NoError(t, err)
+	employee := models.Employee{Name: "Ann", Email: "ann@example.com"}
+	db.Create(&employee)
+	h := newTestHandler(db)
 
 	rr := httptest.NewRecorder()
-	handler := GetRoomsWithDB(db)
-
-	handler.ServeHTTP(rr, req)
+	h.GetRooms(rr, loggedIn(t, h, "GET", "/rooms", nil, employee.ID))
 
 	assert.Equal(t, http.StatusOK, rr.Code)
 	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
 
 	var response []models.Room
-	err = json.Unmarshal(rr.Body.Bytes(), &response)
+	err := json.Unmarshal(rr.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Len(t, response, 1)
 	assert.Equal(t, "Test Room", response[0].Name)
@@ -71,10 +74,17 @@ func setupTestDBforCreate() *gorm.DB {
 	if err != nil {
 		panic("failed to connect test database")
 	}
-	db.AutoMigrate(&models.Room{}, &models.Booking{}, &models.Employee{})
+	db.AutoMigrate(&models.Room{}, &models.Booking{}, &models.Employee{}, &models.Session{})
 	return db
 }
 
+// createAdmin stores an administrator for the room handlers to accept.
+func createAdmin(t *testing.T, db *gorm.DB) models.Employee {
+	admin := models.Employee{Name: "Admin", Email: "admin@example.com", Role: models.RoleAdmin}
+	assert.NoError(t, db.Create(&admin).Error)
+	return admin
+}
+
 func TestCreateRoomWithDB(t *testing.T) {
 	db := setupTestDBforCreate()
 	roomData := models.Room{
@@ -83,16 +93,12 @@ func TestCreateRoomWithDB(t *testing.T) {
 	}
 	capacity := 50
 	roomData.Capacity = &capacity
-	roomJSON, err := json.Marshal(roomData)
-	assert.NoError(t, err)
-
-	req, err := http.NewRequest("POST", "/rooms", bytes.NewBuffer(roomJSON))
-	assert.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	h := newTestHandler(db)
+	req := loggedIn(t, h, "POST", "/rooms", roomData, createAdmin(t, db).ID)
 
 	rr := httptest.NewRecorder()
-	handler := CreateRoomWithDB(db)
-	handler.ServeHTTP(rr, req)
+	h.CreateRoom(rr, req)
+	assert.Equal(t, http.StatusCreated, rr.Code)
 
 	var createdRoom models.Room
 	body, err := io.ReadAll(rr.Body)
@@ -106,4 +112,76 @@ func TestCreateRoomWithDB(t *testing.T) {
 	assert.NotNil(t, createdRoom.Capacity)
 	assert.Equal(t, *roomData.Capacity, *createdRoom.Capacity)
 
-}
\ No newline at end of file
+}
+func TestCreateRoomValidation(t *testing.T) {
+	db := setupTestDBforGet()
+	h := newTestHandler(db)
+	admin := createAdmin(t, db)
+
+	req := loggedIn(t, h, "POST", "/rooms", map[string]interface{}{"name": "", "capacity": 0}, admin.ID)
+	rr := httptest.NewRecorder()
+	h.CreateRoom(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var response apierror.Response
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, apierror.CodeValidation, response.Error.Code)
+	fields := map[string]string{}
+	for _, d := range response.Error.Details {
+		fields[d.Field] = d.Code
+	}
+	assert.Equal(t, map[string]string{"name": "required", "capacity": "out_of_range"}, fields)
+
+	req = loggedIn(t, h, "POST", "/rooms", nil, admin.ID)
+	req.Body = io.NopCloser(bytes.NewBufferString(`{"name":`))
+	rr = httptest.NewRecorder()
+	h.CreateRoom(rr, req)
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, apierror.CodeInvalidJSON, response.Error.Code)
+}
+
+func TestRoomsAndApproverGroupsAreAdminOnly(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	h := newTestHandler(db)
+	admin := createAdmin(t, db)
+	employee := models.Employee{Name: "Ann", Email: "ann@example.com"}
+	db.Create(&employee)
+	room := models.Room{Name: "Boardroom"}
+	db.Create(&room)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/rooms", h.CreateRoom).Methods("POST")
+	router.HandleFunc("/rooms/{id}", h.UpdateRoom).Methods("PUT")
+	router.HandleFunc("/approver-groups", h.CreateApproverGroup).Methods("POST")
+
+	capacity := 8
+	requiresApproval := false
+	requests := []struct {
+		method, url string
+		body        interface{}
+	}{
+		{"POST", "/rooms", models.RoomDTO{Name: "Focus", Capacity: &capacity}},
+		{"PUT", fmt.Sprintf("/rooms/%d", room.ID), models.RoomUpdateDTO{RequiresApproval: &requiresApproval}},
+		{"POST", "/approver-groups", models.ApproverGroupDTO{Name: "Mine", MemberIDs: []uint{employee.ID}}},
+	}
+	for _, tt := range requests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			payload, _ := json.Marshal(tt.body)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.url, bytes.NewReader(payload)))
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+
+			rr = httptest.NewRecorder()
+			router.ServeHTTP(rr, loggedIn(t, h, tt.method, tt.url, tt.body, employee.ID))
+			assert.Equal(t, http.StatusForbidden, rr.Code)
+
+			rr = httptest.NewRecorder()
+			router.ServeHTTP(rr, loggedIn(t, h, tt.method, tt.url, tt.body, admin.ID))
+			assert.Less(t, rr.Code, 300, rr.Body.String())
+		})
+	}
+}
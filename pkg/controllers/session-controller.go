@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/csrf"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	session "github.com/koushikidey/go-meetingroombook/pkg/sessions"
+)
+
+// GetMe godoc
+// @Summary Who is logged in
+// @Description Returns the employee the session belongs to: their profile, their roles, whether their Google Calendar is linked and when the session expires. Clients call it on start-up to find out whether they are signed in; /session-status is an alias.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} models.MeResponse
+// @Failure 401 {object} apierror.Response "Not logged in"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /me [get]
+// @Router /session-status [get]
+func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
+	sess, _ := h.sessions.Get(r, "session")
+	employeeID, ok := sess.Values[session.EmployeeKey].(uint)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	employee, err := h.repos.Employees.Get(employeeID)
+	if err != nil || !employee.IsActive() {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	stored, err := h.sessions.Lookup(r.Context(), sess.ID)
+	if err != nil {
+		apierror.Error(w, "Failed to fetch session", http.StatusInternalServerError)
+		return
+	}
+
+	groups, err := h.repos.Employees.ApproverGroupIDs(employeeID)
+	if err != nil {
+		apierror.Error(w, "Failed to fetch roles", http.StatusInternalServerError)
+		return
+	}
+	_, err = h.repos.Tokens.Get(employeeID)
+	if err != nil && !repository.IsNotFound(err) {
+		apierror.Error(w, "Failed to fetch calendar link", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.NewMeResponse(employee, len(groups) > 0, err == nil, stored))
+}
+
+// GetCSRFToken godoc
+// @Summary Get the CSRF token of my session
+// @Description Returns the token clients signed in with the session cookie must send in the X-CSRF-Token header of every POST, PUT and DELETE request; without it such requests are rejected with 403. The token lasts as long as the session; logging in replaces it, so fetch it again afterwards. Clients that send no session cookie need no token.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} map[string]string "csrf_token"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /csrf-token [get]
+func (h *Handler) GetCSRFToken(w http.ResponseWriter, r *http.Request) {
+	token, err := csrf.Token(w, r, h.sessions)
+	if err != nil {
+		apierror.Error(w, "Failed to create CSRF token", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{"csrf_token": token})
+}
+
+// GetSessions godoc
+// @Summary List my sessions
+// @Description Returns the logged-in employee's active sessions, one per device or browser they signed in with, most recently used first. The session of this request is marked as current.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {array} models.SessionResponse
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /sessions [get]
+func (h *Handler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	sess, _ := h.sessions.Get(r, "session")
+	employeeID, ok := sess.Values[session.EmployeeKey].(uint)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	active, err := h.sessions.List(r.Context(), employeeID)
+	if err != nil {
+		apierror.Error(w, "Failed to fetch sessions", http.StatusInternalServerError)
+		return
+	}
+	responses := make([]models.SessionResponse, len(active))
+	for i, s := range active {
+		responses[i] = models.NewSessionResponse(s, s.ID == sess.ID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(responses)
+}
+
+// DeleteSession godoc
+// @Summary Revoke one of my sessions
+// @Description Signs the logged-in employee out of the session, e.g. on a lost device. Revoking the current session is the same as logging out.
+// @Tags Authentication
+// @Param id path string true "Session ID"
+// @Success 204 "Session revoked"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 404 {object} apierror.Response "Session not found"
+// @Failure 500 {object} apierror.Response "Internal Server Error"
+// @Router /sessions/{id} [delete]
+func (h *Handler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	sess, _ := h.sessions.Get(r, "session")
+	employeeID, ok := sess.Values[session.EmployeeKey].(uint)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if id == sess.ID {
+		sess.Options.MaxAge = -1
+		if err := sess.Save(r, w); err != nil {
+			apierror.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	err := h.sessions.Revoke(r.Context(), employeeID, id)
+	if errors.Is(err, session.ErrNotFound) {
+		apierror.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		apierror.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// revokeSessions signs the employee out everywhere but in the session with
+// the ID except. Failures are only logged.
+func (h *Handler) revokeSessions(ctx context.Context, employeeID uint, except string) {
+	revoked, err := h.sessions.RevokeAll(ctx, employeeID, except)
+	if err != nil {
+		logging.FromContext(ctx).Error("Failed to revoke sessions", "employee_id", employeeID, "error", err)
+		return
+	}
+	if revoked > 0 {
+		logging.FromContext(ctx).Info("Revoked sessions", "employee_id", employeeID, "count", revoked)
+	}
+}
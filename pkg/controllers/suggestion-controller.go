@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/suggest"
+)
+
+// SuggestRooms godoc
+// @Summary Suggest rooms and times for a meeting
+// @Description Ranks room and time combinations within a window by capacity fit, attendee availability (bookings and optionally Google Calendar free/busy) and distance from the organizer's home floor
+// @Tags Rooms
+// @Accept json
+// @Produce json
+// @Param request body models.RoomSuggestionDTO true "Attendees, duration, window and required amenities"
+// @Success 200 {array} suggest.Option
+// @Failure 400 {object} apierror.Response "Invalid JSON or window"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Router /rooms/suggestions [post]
+func (h *Handler) SuggestRooms(w http.ResponseWriter, r *http.Request) {
+	organizerID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input models.RoomSuggestionDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+	duration := time.Duration(input.DurationMinutes) * time.Minute
+
+	organizer, err := h.repos.Employees.Get(organizerID)
+	if err != nil {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	people := map[uint]bool{organizerID: true}
+	for _, id := range input.AttendeeIDs {
+		people[id] = true
+	}
+	headCount := input.NumAttendees
+	if headCount == 0 {
+		headCount = len(people)
+	}
+
+	rooms, err := h.repos.Rooms.All()
+	if err != nil {
+		apierror.Error(w, "Failed to fetch rooms", http.StatusInternalServerError)
+		return
+	}
+	bookings, err := h.repos.Bookings.During(input.WindowStart, input.WindowEnd)
+	if err != nil {
+		apierror.Error(w, "Failed to fetch bookings", http.StatusInternalServerError)
+		return
+	}
+
+	roomBusy := map[uint][]suggest.Interval{}
+	attendeeBusy := map[uint][]suggest.Interval{}
+	for _, b := range bookings {
+		period := suggest.Interval{Start: b.StartTime, End: b.EndTime}
+		roomBusy[b.RoomID] = append(roomBusy[b.RoomID], period)
+		if people[b.EmployeeID] {
+			attendeeBusy[b.EmployeeID] = append(attendeeBusy[b.EmployeeID], period)
+		}
+		for _, a := range b.Attendees {
+			if people[a.ID] && a.ID != b.EmployeeID {
+				attendeeBusy[a.ID] = append(attendeeBusy[a.ID], period)
+			}
+		}
+	}
+
+	if input.UseGoogleCalendar {
+		for id := range people {
+			periods, err := h.calendar.FreeBusy(r.Context(), id, input.WindowStart, input.WindowEnd)
+			if err != nil {
+				logging.FromContext(r.Context()).Warn("Skipping Google free/busy", "employee_id", id, "error", err)
+				continue
+			}
+			for _, p := range periods {
+				attendeeBusy[id] = append(attendeeBusy[id], suggest.Interval{Start: p.Start, End: p.End})
+			}
+		}
+	}
+
+	options := suggest.Rank(suggest.Request{
+		Attendees:      headCount,
+		Duration:       duration,
+		WindowStart:    input.WindowStart,
+		WindowEnd:      input.WindowEnd,
+		Amenities:      input.Amenities,
+		OrganizerFloor: organizer.HomeFloor,
+		RoomBusy:       roomBusy,
+		AttendeeBusy:   attendeeBusy,
+		Limit:          input.Limit,
+	}, rooms)
+	if options == nil {
+		options = []suggest.Option{}
+	}
+
+	resp, _ := json.Marshal(options)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
@@ -0,0 +1,400 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+)
+
+// transferReason describes a change of organizer for the booking history.
+func transferReason(from, to models.Employee, reason string) string {
+	message := fmt.Sprintf("organizer changed from %s to %s", from.Name, to.Name)
+	if reason != "" {
+		message += ": " + reason
+	}
+	return message
+}
+
+// transferBooking hands a single booking over to a new organizer, moving its
+// calendar event and telling the new organizer and the attendees.
+func (h *Handler) transferBooking(ctx context.Context, booking *models.Booking, from, to models.Employee, actorID *uint, reason string) error {
+	reason = transferReason(from, to, reason)
+	if err := h.repos.Bookings.Reassign(booking, from.ID, to, actorID, reason); err != nil {
+		return err
+	}
+
+	if booking.CalendarID != "" {
+		h.removeCalendarEvent(ctx, models.Booking{EmployeeID: from.ID, CalendarID: booking.CalendarID})
+		booking.CalendarID = ""
+		if err := h.repos.Bookings.SetCalendarID(booking.ID, ""); err != nil {
+			logging.FromContext(ctx).Error("Failed to clear calendar ID of booking", "booking_id", booking.ID, "error", err)
+		}
+	}
+	if booking.Status == models.BookingConfirmed {
+		h.addCalendarEvent(ctx, booking, to)
+	}
+
+	details := fmt.Sprintf("the booking of %s from %s to %s", booking.Room.Name, booking.StartTime, booking.EndTime)
+	message := fmt.Sprintf("Hi %s,\n\nYou are now the organizer of %s (%s).", to.Name, details, reason)
+	h.sendEmail(ctx, to.Email, "Meeting Room Booking Transferred", message)
+	h.notifyAttendees(ctx, booking.Attendees, to.ID, "Meeting Organizer Changed",
+		fmt.Sprintf("%s is now the organizer of %s.", to.Name, details))
+	return nil
+}
+
+// transferBookingGroup hands every active booking of a group over to a new
+// organizer and moves the shared calendar event.
+func (h *Handler) transferBookingGroup(ctx context.Context, group *models.BookingGroup, from, to models.Employee, actorID *uint, reason string) error {
+	reason = transferReason(from, to, reason)
+	previous := *group
+	if err := h.repos.BookingGroups.Reassign(group, from.ID, to, actorID, reason); err != nil {
+		return err
+	}
+	h.removeGroupCalendarEvent(ctx, &previous)
+	group.CalendarID = ""
+	confirmed := len(group.Bookings) > 0
+	for _, b := range group.Bookings {
+		if b.Status != models.BookingConfirmed {
+			confirmed = false
+		}
+	}
+	if confirmed {
+		h.addGroupCalendarEvent(ctx, group, to)
+	}
+
+	details := fmt.Sprintf("the booking of %s from %s to %s", roomNames(group.Bookings), group.StartTime, group.EndTime)
+	message := fmt.Sprintf("Hi %s,\n\nYou are now the organizer of %s (%s).", to.Name, details, reason)
+	h.sendEmail(ctx, to.Email, "Meeting Room Booking Transferred", message)
+	h.notifyAttendees(ctx, groupAttendees(group), to.ID, "Meeting Organizer Changed",
+		fmt.Sprintf("%s is now the organizer of %s.", to.Name, details))
+	return nil
+}
+
+// groupAttendees returns the employees invited to any room of the group.
+func groupAttendees(group *models.BookingGroup) []models.Employee {
+	seen := map[uint]bool{}
+	var attendees []models.Employee
+	for _, b := range group.Bookings {
+		for _, a := range b.Attendees {
+			if !seen[a.ID] {
+				seen[a.ID] = true
+				attendees = append(attendees, a)
+			}
+		}
+	}
+	return attendees
+}
+
+// notifyAttendees emails every active attendee except the one skipped.
+func (h *Handler) notifyAttendees(ctx context.Context, attendees []models.Employee, skipID uint, subject, message string) {
+	for _, a := range attendees {
+		if a.ID == skipID || !a.IsActive() {
+			continue
+		}
+		h.sendEmail(ctx, a.Email, subject, fmt.Sprintf("Hi %s,\n\n%s", a.Name, message))
+	}
+}
+
+// transferFutureBookings hands all of an employee's future bookings over to
+// another employee. Bookings that fail to transfer are logged and skipped.
+func (h *Handler) transferFutureBookings(ctx context.Context, from, to models.Employee, actorID *uint, reason string) (models.TransferResultDTO, error) {
+	var result models.TransferResultDTO
+	bookings, groups, err := h.service.Upcoming(from.ID)
+	if err != nil {
+		return result, err
+	}
+	for i := range bookings {
+		if err := h.transferBooking(ctx, &bookings[i], from, to, actorID, reason); err != nil {
+			logging.FromContext(ctx).Error("Failed to transfer booking", "booking_id", bookings[i].ID, "error", err)
+			continue
+		}
+		result.Bookings++
+	}
+	for i := range groups {
+		if err := h.transferBookingGroup(ctx, &groups[i], from, to, actorID, reason); err != nil {
+			logging.FromContext(ctx).Error("Failed to transfer booking group", "group_id", groups[i].ID, "error", err)
+			continue
+		}
+		result.BookingGroups++
+	}
+	return result, nil
+}
+
+// cancelFutureBookings cancels all of an employee's future bookings and lets
+// the attendees know.
+func (h *Handler) cancelFutureBookings(ctx context.Context, employee models.Employee, actorID *uint, reason string) (models.TransferResultDTO, error) {
+	result := models.TransferResultDTO{Cancelled: true}
+	bookings, groups, err := h.service.Upcoming(employee.ID)
+	if err != nil {
+		return result, err
+	}
+	for i := range bookings {
+		b := &bookings[i]
+		if err := h.service.Transition(b, models.BookingCancelled, actorID, reason); err != nil {
+			logging.FromContext(ctx).Error("Failed to cancel booking", "booking_id", b.ID, "error", err)
+			continue
+		}
+		h.removeCalendarEvent(ctx, *b)
+		h.notifyAttendees(ctx, b.Attendees, employee.ID, "Meeting Cancelled",
+			fmt.Sprintf("The meeting organized by %s in %s from %s to %s has been cancelled (%s).",
+				employee.Name, b.Room.Name, b.StartTime, b.EndTime, reason))
+		result.Bookings++
+	}
+	for i := range groups {
+		g := &groups[i]
+		if err := h.cancelBookingGroup(ctx, g.ID, actorID, reason); err != nil {
+			continue
+		}
+		h.notifyAttendees(ctx, groupAttendees(g), employee.ID, "Meeting Cancelled",
+			fmt.Sprintf("The meeting organized by %s in %s from %s to %s has been cancelled (%s).",
+				employee.Name, roomNames(g.Bookings), g.StartTime, g.EndTime, reason))
+		result.BookingGroups++
+	}
+	return result, nil
+}
+
+// TransferBooking godoc
+// @Summary Transfer a booking to another organizer
+// @Description Makes another employee the organizer of a booking. The organizer, a delegate with edit permission or an administrator may transfer it. Bookings that are part of a multi-room group are transferred together with the rest of the group.
+// @Tags Bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param transfer body models.BookingTransferDTO true "New organizer and optional reason"
+// @Success 200 {object} models.BookingResponse
+// @Failure 400 {object} apierror.Response "Invalid ID, JSON or new organizer"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Booking not found"
+// @Failure 409 {object} apierror.Response "Booking is no longer active or was changed concurrently"
+// @Router /bookings/{id}/transfer [post]
+func (h *Handler) TransferBooking(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var input models.BookingTransferDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	booking, err := h.repos.Bookings.Get(uint(id), "Room", "Employee", "Attendees")
+	if err != nil {
+		apierror.Error(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	if !h.service.CanActFor(employeeID, booking.EmployeeID, models.DelegateEdit) && !h.service.IsAdmin(employeeID) {
+		apierror.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if !booking.Status.IsActive() {
+		apierror.Error(w, "Only active bookings can be transferred", http.StatusConflict)
+		return
+	}
+	if input.NewOrganizerID == booking.EmployeeID {
+		apierror.Field(w, "new_organizer_id", validation.CodeInvalid, "the employee already organizes this booking")
+		return
+	}
+	to, err := h.service.NewOrganizer(input.NewOrganizerID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to load new organizer")
+		return
+	}
+
+	if booking.GroupID != nil {
+		group, err := h.repos.BookingGroups.GetActive(*booking.GroupID)
+		if err != nil {
+			apierror.Error(w, "Booking group not found", http.StatusNotFound)
+			return
+		}
+		err = h.transferBookingGroup(r.Context(), &group, booking.Employee, to, &employeeID, input.Reason)
+	} else {
+		err = h.transferBooking(r.Context(), &booking, booking.Employee, to, &employeeID, input.Reason)
+	}
+	if err != nil {
+		writeTransitionError(w, err)
+		return
+	}
+
+	booking, _ = h.repos.Bookings.Get(uint(id), "Room", "Employee", "Attendees")
+	resp, _ := json.Marshal(models.NewBookingResponse(booking))
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// TransferEmployeeBookings godoc
+// @Summary Transfer all future bookings of an employee
+// @Description Makes another employee the organizer of every booking of the employee that has not started yet. Employees may transfer their own bookings; administrators may transfer anyone's.
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param transfer body models.BookingTransferDTO true "New organizer and optional reason"
+// @Success 200 {object} models.TransferResultDTO
+// @Failure 400 {object} apierror.Response "Invalid ID, JSON or new organizer"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Employee not found"
+// @Failure 500 {object} apierror.Response "Failed to load bookings"
+// @Router /employees/{id}/bookings/transfer [post]
+func (h *Handler) TransferEmployeeBookings(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid Employee ID", http.StatusBadRequest)
+		return
+	}
+
+	var input models.BookingTransferDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	if uint(id) != employeeID && !h.service.IsAdmin(employeeID) {
+		apierror.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	from, err := h.repos.Employees.Get(uint(id))
+	if err != nil {
+		apierror.Error(w, "Employee not found", http.StatusNotFound)
+		return
+	}
+	if input.NewOrganizerID == from.ID {
+		apierror.Field(w, "new_organizer_id", validation.CodeInvalid, "cannot transfer bookings to the same employee")
+		return
+	}
+	to, err := h.service.NewOrganizer(input.NewOrganizerID)
+	if err != nil {
+		writeServiceError(w, r, err, "Failed to load new organizer")
+		return
+	}
+
+	result, err := h.transferFutureBookings(r.Context(), from, to, &employeeID, input.Reason)
+	if err != nil {
+		apierror.Error(w, "Failed to load bookings", http.StatusInternalServerError)
+		return
+	}
+
+	resp, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// DeactivateEmployee godoc
+// @Summary Offboard an employee
+// @Description Deactivates an employee (administrators only). Their future bookings are either transferred to another employee or cancelled, their delegations are removed, their Google token is revoked and affected attendees are notified. Deactivated employees are signed out and can no longer log in.
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Param id path int true "Employee ID"
+// @Param offboarding body models.OffboardingDTO true "What to do with future bookings"
+// @Success 200 {object} models.TransferResultDTO
+// @Failure 400 {object} apierror.Response "Invalid ID, JSON, mode or new organizer"
+// @Failure 401 {object} apierror.Response "Unauthorized"
+// @Failure 403 {object} apierror.Response "Forbidden"
+// @Failure 404 {object} apierror.Response "Employee not found"
+// @Failure 409 {object} apierror.Response "Employee is already deactivated"
+// @Failure 500 {object} apierror.Response "Failed to deactivate employee"
+// @Router /employees/{id}/deactivate [post]
+func (h *Handler) DeactivateEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID, ok := h.currentEmployeeID(r)
+	if !ok {
+		apierror.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		apierror.Error(w, "Invalid Employee ID", http.StatusBadRequest)
+		return
+	}
+
+	var input models.OffboardingDTO
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	if !h.service.IsAdmin(employeeID) {
+		apierror.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if uint(id) == employeeID {
+		apierror.Error(w, "You cannot deactivate yourself", http.StatusBadRequest)
+		return
+	}
+	employee, err := h.repos.Employees.Get(uint(id))
+	if err != nil {
+		apierror.Error(w, "Employee not found", http.StatusNotFound)
+		return
+	}
+	if !employee.IsActive() {
+		apierror.Error(w, "Employee is already deactivated", http.StatusConflict)
+		return
+	}
+
+	var to models.Employee
+	if input.Mode == models.OffboardTransfer {
+		if input.NewOrganizerID == employee.ID {
+			apierror.Field(w, "new_organizer_id", validation.CodeInvalid, "cannot transfer bookings to the employee being deactivated")
+			return
+		}
+		if to, err = h.service.NewOrganizer(input.NewOrganizerID); err != nil {
+			writeServiceError(w, r, err, "Failed to load new organizer")
+			return
+		}
+	}
+
+	if err := h.repos.Employees.Deactivate(&employee, time.Now()); err != nil {
+		apierror.Error(w, "Failed to deactivate employee", http.StatusInternalServerError)
+		return
+	}
+	h.revokeSessions(r.Context(), employee.ID, "")
+	if h.cache != nil {
+		h.cache.Delete(employee.ID)
+	}
+
+	reason := input.Reason
+	if reason == "" {
+		reason = "organizer left the company"
+	}
+	var result models.TransferResultDTO
+	if input.Mode == models.OffboardTransfer {
+		result, err = h.transferFutureBookings(r.Context(), employee, to, &employeeID, reason)
+	} else {
+		result, err = h.cancelFutureBookings(r.Context(), employee, &employeeID, reason)
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("Failed to offboard bookings", "employee_id", employee.ID, "error", err)
+	}
+
+	// Calendar events have been moved or removed, so the token can go now.
+	if err := h.calendar.RevokeToken(r.Context(), employee.ID); err != nil {
+		logging.FromContext(r.Context()).Error("Failed to revoke Google token", "employee_id", employee.ID, "error", err)
+	}
+
+	resp, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTransferDB(t *testing.T) (*gorm.DB, models.Employee, models.Employee, models.Employee) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	leaver := models.Employee{Name: "Leaver", Email: "leaver@example.com"}
+	heir := models.Employee{Name: "Heir", Email: "heir@example.com"}
+	guest := models.Employee{Name: "Guest", Email: "guest@example.com"}
+	db.Create(&leaver)
+	db.Create(&heir)
+	db.Create(&guest)
+
+	capacity := 8
+	room := models.Room{Name: "Focus", Capacity: &capacity}
+	db.Create(&room)
+
+	start := time.Now().Add(24 * time.Hour)
+	bookings := []models.Booking{
+		// future bookings, one of which invites the heir
+		{RoomID: room.ID, EmployeeID: leaver.ID, StartTime: start, EndTime: start.Add(time.Hour), Attendees: []models.Employee{heir, guest}},
+		{RoomID: room.ID, EmployeeID: leaver.ID, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour)},
+		// already over, and already cancelled: both left alone
+		{RoomID: room.ID, EmployeeID: leaver.ID, StartTime: start.Add(-48 * time.Hour), EndTime: start.Add(-47 * time.Hour)},
+		{RoomID: room.ID, EmployeeID: leaver.ID, StartTime: start.Add(4 * time.Hour), EndTime: start.Add(5 * time.Hour), Status: models.BookingCancelled},
+	}
+	for i := range bookings {
+		bookings[i].CreatedByID = leaver.ID
+		assert.NoError(t, db.Create(&bookings[i]).Error)
+	}
+	return db, leaver, heir, guest
+}
+
+func TestTransferFutureBookings(t *testing.T) {
+	db, leaver, heir, guest := setupTransferDB(t)
+
+	result, err := newTestHandler(db).transferFutureBookings(context.Background(), leaver, heir, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Bookings)
+	assert.False(t, result.Cancelled)
+
+	var transferred []models.Booking
+	db.Preload("Attendees").Preload("Transitions").Where("employee_id = ?", heir.ID).Order("start_time").Find(&transferred)
+	assert.Len(t, transferred, 2)
+	// The heir is no longer invited to a meeting they now organize.
+	assert.Len(t, transferred[0].Attendees, 1)
+	assert.Equal(t, guest.ID, transferred[0].Attendees[0].ID)
+	assert.Len(t, transferred[0].Transitions, 1)
+	assert.Contains(t, transferred[0].Transitions[0].Reason, "organizer changed from Leaver to Heir")
+
+	var remaining int64
+	db.Model(&models.Booking{}).Where("employee_id = ?", leaver.ID).Count(&remaining)
+	assert.Equal(t, int64(2), remaining)
+}
+
+func TestCancelFutureBookings(t *testing.T) {
+	db, leaver, _, _ := setupTransferDB(t)
+
+	result, err := newTestHandler(db).cancelFutureBookings(context.Background(), leaver, nil, "organizer left the company")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Bookings)
+	assert.True(t, result.Cancelled)
+
+	var active int64
+	db.Model(&models.Booking{}).Where("employee_id = ? AND status IN ?", leaver.ID, models.ActiveBookingStatuses).Count(&active)
+	// Only the past booking is still confirmed.
+	assert.Equal(t, int64(1), active)
+}
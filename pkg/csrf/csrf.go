@@ -0,0 +1,94 @@
+// Package csrf protects the endpoints authenticated by the session cookie
+// from cross-site request forgery. Every session gets a random token, which
+// the SPA fetches from /csrf-token and sends back in the X-CSRF-Token header
+// of each state-changing request; HTML forms send it in the csrf_token
+// field. Another site can make the browser send the cookie, but cannot read
+// the token.
+package csrf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+)
+
+const (
+	// HeaderName is the request header carrying the token.
+	HeaderName = "X-CSRF-Token"
+	// FieldName is the form field carrying the token.
+	FieldName = "csrf_token"
+	// sessionKey is the session value holding the token.
+	sessionKey = "csrf_token"
+)
+
+// Token returns the token of the request's session, creating and saving one
+// if the session has none yet.
+func Token(w http.ResponseWriter, r *http.Request, store sessions.Store) (string, error) {
+	sess, _ := store.Get(r, "session")
+	if token, ok := sess.Values[sessionKey].(string); ok && token != "" {
+		return token, nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	sess.Values[sessionKey] = token
+	if err := sess.Save(r, w); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Reset drops the token of the session, so that a token handed out before
+// the session changed hands, such as before logging in, stops working. The
+// next call to Token creates a new one. The caller saves the session.
+func Reset(sess *sessions.Session) {
+	delete(sess.Values, sessionKey)
+}
+
+// Middleware rejects state-changing requests made with a session that come
+// without its token. Safe methods are let through, as are requests without
+// a session: API clients authenticating with a token instead of the cookie
+// carry nothing a forged request could make use of.
+func Middleware(store sessions.Store) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if safe(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			sess, _ := store.Get(r, "session")
+			if sess.IsNew {
+				next.ServeHTTP(w, r)
+				return
+			}
+			expected, _ := sess.Values[sessionKey].(string)
+			sent := r.Header.Get(HeaderName)
+			if sent == "" {
+				sent = r.PostFormValue(FieldName)
+			}
+			if expected == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(expected)) != 1 {
+				logging.FromContext(r.Context()).Warn("Rejected request without a valid CSRF token",
+					"origin", r.Header.Get("Origin"))
+				apierror.Error(w, "Missing or invalid CSRF token", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func safe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
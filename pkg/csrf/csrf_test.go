@@ -0,0 +1,97 @@
+package csrf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+// signIn returns the cookie of a new session holding a CSRF token, and the
+// token.
+func signIn(t *testing.T, store sessions.Store) (*http.Cookie, string) {
+	req := httptest.NewRequest("GET", "/csrf-token", nil)
+	rr := httptest.NewRecorder()
+	token, err := Token(rr, req, store)
+	assert.NoError(t, err)
+	cookies := rr.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	return cookies[0], token
+}
+
+func serve(store sessions.Store, req *http.Request) int {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	rr := httptest.NewRecorder()
+	Middleware(store)(ok).ServeHTTP(rr, req)
+	return rr.Code
+}
+
+func TestTokenIsKeptInTheSession(t *testing.T) {
+	store := sessions.NewCookieStore([]byte("test-session-key-of-at-least-32-bytes"))
+	cookie, token := signIn(t, store)
+	assert.NotEmpty(t, token)
+
+	req := httptest.NewRequest("GET", "/csrf-token", nil)
+	req.AddCookie(cookie)
+	again, err := Token(httptest.NewRecorder(), req, store)
+	assert.NoError(t, err)
+	assert.Equal(t, token, again)
+}
+
+func TestMiddleware(t *testing.T) {
+	store := sessions.NewCookieStore([]byte("test-session-key-of-at-least-32-bytes"))
+	cookie, token := signIn(t, store)
+
+	request := func(method string, withCookie bool, header, field string) *http.Request {
+		form := url.Values{}
+		if field != "" {
+			form.Set(FieldName, field)
+		}
+		req := httptest.NewRequest(method, "/bookings/1", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if withCookie {
+			req.AddCookie(cookie)
+		}
+		if header != "" {
+			req.Header.Set(HeaderName, header)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"safe method", request("GET", true, "", ""), http.StatusNoContent},
+		{"cross-site post", request("POST", true, "", ""), http.StatusForbidden},
+		{"forged token", request("DELETE", true, "forged", ""), http.StatusForbidden},
+		{"token in header", request("PUT", true, token, ""), http.StatusNoContent},
+		{"token in form", request("POST", true, "", token), http.StatusNoContent},
+		{"no session cookie", request("POST", false, "", ""), http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.status, serve(store, tt.req))
+		})
+	}
+}
+
+func TestSessionWithoutTokenIsRejected(t *testing.T) {
+	store := sessions.NewCookieStore([]byte("test-session-key-of-at-least-32-bytes"))
+	req := httptest.NewRequest("POST", "/login", nil)
+	rr := httptest.NewRecorder()
+	sess, _ := store.Get(req, "session")
+	sess.Values["employee_id"] = uint(7)
+	assert.NoError(t, sess.Save(req, rr))
+
+	req = httptest.NewRequest("POST", "/bookings", nil)
+	req.AddCookie(rr.Result().Cookies()[0])
+	assert.Equal(t, http.StatusForbidden, serve(store, req))
+}
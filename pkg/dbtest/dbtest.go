@@ -0,0 +1,99 @@
+// Package dbtest opens throwaway databases for tests. SQLite is always
+// available; PostgreSQL is used as well when TEST_POSTGRES_DSN points at a
+// server the tests may create schemas on.
+package dbtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Drivers lists the databases the tests run against.
+func Drivers() []string {
+	drivers := []string{"sqlite"}
+	if os.Getenv("TEST_POSTGRES_DSN") != "" {
+		drivers = append(drivers, "postgres")
+	}
+	return drivers
+}
+
+// Open returns an empty database for the driver, dropped when the test ends.
+func Open(t *testing.T, driver string) *gorm.DB {
+	t.Helper()
+	config := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+	switch driver {
+	case "sqlite":
+		db, err := gorm.Open(sqlite.Open(":memory:"), config)
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		return db
+	case "postgres":
+		return openPostgres(t, config)
+	}
+	t.Fatalf("unknown test driver %q", driver)
+	return nil
+}
+
+// Each runs fn once per driver with an empty database.
+func Each(t *testing.T, fn func(t *testing.T, db *gorm.DB)) {
+	for _, driver := range Drivers() {
+		t.Run(driver, func(t *testing.T) {
+			fn(t, Open(t, driver))
+		})
+	}
+}
+
+// openPostgres isolates the test in a schema of its own.
+func openPostgres(t *testing.T, config *gorm.Config) *gorm.DB {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	admin, err := gorm.Open(postgres.Open(dsn), config)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		t.Fatal(err)
+	}
+	schema := "test_" + hex.EncodeToString(suffix)
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), config)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// withSearchPath adds search_path to a URL or key=value DSN.
+func withSearchPath(dsn, schema string) string {
+	if strings.Contains(dsn, "://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		return dsn + separator + "search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
@@ -2,16 +2,22 @@ package googleapi
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"net/url"
 
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/hex"
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/metrics"
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
@@ -29,15 +35,10 @@ func InitOAuth(clientID, clientSecret, redirectURL string) {
 	}
 }
 
-//	func GetAuthURL() string {
-//		return config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-//	}
-func GetAuthURLWithUser(userID uint) (string, error) {
-	state, err := CreateState(userID)
-	if err != nil {
-		return "", err
-	}
-	return con.AuthCodeURL(state, oauth2.AccessTypeOffline), nil
+// GetAuthURL returns the URL of the consent screen. Google passes state back
+// to the callback unchanged.
+func GetAuthURL(state string) string {
+	return con.AuthCodeURL(state, oauth2.AccessTypeOffline)
 }
 
 func ExchangeCode(code string) (*oauth2.Token, error) {
@@ -48,47 +49,58 @@ func GetClient(token *oauth2.Token) *http.Client {
 	return con.Client(context.Background(), token)
 }
 
-func generateNonce(n int) (string, error) {
-	bytes := make([]byte, n)
-	if _, err := rand.Read(bytes); err != nil {
+// NewState returns a random state for a new authorization. The caller keeps
+// it and accepts only the callback that brings it back, so that nobody can
+// link their own Google account to another employee's session.
+func NewState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
-	return hex.EncodeToString(bytes), nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-func CreateState(userID uint) (string, error) {
-	nonce, err := generateNonce(8)
-	if err != nil {
-		return "", err
-	}
-	raw := fmt.Sprintf("%d:%s", userID, nonce)
-	return base64.URLEncoding.EncodeToString([]byte(raw)), nil
+// ErrNotLinked is returned for employees who have not linked their Google
+// Calendar.
+var ErrNotLinked = errors.New("google calendar not linked")
+
+// Client works with the Google Calendar of employees, using the tokens
+// stored when they linked it.
+type Client struct {
+	tokens repository.TokenRepository
 }
 
-func ParseState(state string) (uint, error) {
-	decoded, err := base64.URLEncoding.DecodeString(state)
-	if err != nil {
-		return 0, err
-	}
-	parts := strings.SplitN(string(decoded), ":", 2)
-	if len(parts) != 2 {
-		return 0, fmt.Errorf("invalid state format")
-	}
-	var userID uint
-	_, err = fmt.Sscanf(parts[0], "%d", &userID)
-	if err != nil {
-		return 0, err
+func NewClient(tokens repository.TokenRepository) *Client {
+	return &Client{tokens: tokens}
+}
+
+// traced passes the request ID of ctx on with a Calendar API call, so that
+// the call can be matched with the request that caused it.
+func traced(ctx context.Context, call interface{ Header() http.Header }) {
+	if id := logging.RequestID(ctx); id != "" {
+		call.Header().Set(logging.HeaderRequestID, id)
 	}
-	return userID, nil
 }
 
-func DeleteCalendarEvent(employeeID uint, calendarEventID string) error {
-	config.Connect()
-	db := config.GetDB()
+// observe starts a span for a call to Google; the returned function ends it
+// and records the call in the metrics.
+func observe(ctx context.Context, operation string, employeeID uint) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "google."+operation, attribute.Int("employee.id", int(employeeID)))
+	return ctx, func(err error) {
+		metrics.ObserveCalendar(operation, start, err)
+		tracing.End(span, err)
+	}
+}
 
-	var token models.GoogleToken
-	if err := db.Where("employee_id = ?", employeeID).First(&token).Error; err != nil {
-		return fmt.Errorf("failed to find Google token: %w", err)
+// service returns a Calendar API client acting as the employee.
+func (c *Client) service(employeeID uint) (*calendar.Service, error) {
+	token, err := c.tokens.Get(employeeID)
+	if repository.IsNotFound(err) {
+		return nil, ErrNotLinked
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Google token: %w", err)
 	}
 
 	oauthToken := &oauth2.Token{
@@ -96,17 +108,182 @@ func DeleteCalendarEvent(employeeID uint, calendarEventID string) error {
 		RefreshToken: token.RefreshToken,
 		Expiry:       token.Expiry,
 	}
-	client := GetClient(oauthToken)
+	srv, err := calendar.New(GetClient(oauthToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create calendar client: %w", err)
+	}
+	return srv, nil
+}
 
-	srv, err := calendar.New(client)
+// InsertEvent adds the event to the employee's primary calendar and returns
+// its ID.
+func (c *Client) InsertEvent(ctx context.Context, employeeID uint, event *calendar.Event) (string, error) {
+	srv, err := c.service(employeeID)
 	if err != nil {
-		return fmt.Errorf("failed to create calendar client: %w", err)
+		return "", err
+	}
+	ctx, done := observe(ctx, "insert_event", employeeID)
+	call := srv.Events.Insert("primary", event).Context(ctx)
+	traced(ctx, call)
+	created, err := call.Do()
+	done(err)
+	if err != nil {
+		return "", fmt.Errorf("failed to create calendar event: %w", err)
 	}
+	return created.Id, nil
+}
 
-	err = srv.Events.Delete("primary", calendarEventID).Do()
+func (c *Client) DeleteEvent(ctx context.Context, employeeID uint, calendarEventID string) error {
+	srv, err := c.service(employeeID)
+	if err != nil {
+		return err
+	}
+	ctx, done := observe(ctx, "delete_event", employeeID)
+	call := srv.Events.Delete("primary", calendarEventID).Context(ctx)
+	traced(ctx, call)
+	err = call.Do()
+	done(err)
 	if err != nil {
 		return fmt.Errorf("failed to delete calendar event: %w", err)
 	}
+	return nil
+}
+
+type BusyPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// FreeBusy returns the busy periods in the employee's primary Google
+// Calendar between start and end.
+func (c *Client) FreeBusy(ctx context.Context, employeeID uint, start, end time.Time) ([]BusyPeriod, error) {
+	srv, err := c.service(employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, done := observe(ctx, "freebusy", employeeID)
+	call := srv.Freebusy.Query(&calendar.FreeBusyRequest{
+		TimeMin: start.Format(time.RFC3339),
+		TimeMax: end.Format(time.RFC3339),
+		Items:   []*calendar.FreeBusyRequestItem{{Id: "primary"}},
+	}).Context(ctx)
+	traced(ctx, call)
+	resp, err := call.Do()
+	done(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query free/busy: %w", err)
+	}
+
+	var periods []BusyPeriod
+	if primary, ok := resp.Calendars["primary"]; ok {
+		for _, p := range primary.Busy {
+			s, err := time.Parse(time.RFC3339, p.Start)
+			if err != nil {
+				continue
+			}
+			e, err := time.Parse(time.RFC3339, p.End)
+			if err != nil {
+				continue
+			}
+			periods = append(periods, BusyPeriod{Start: s, End: e})
+		}
+	}
+	return periods, nil
+}
+
+// RevokeToken revokes the employee's Google token and forgets it, so the app
+// can no longer reach their calendar. The stored token is removed even if
+// Google cannot be reached.
+func (c *Client) RevokeToken(ctx context.Context, employeeID uint) error {
+	token, err := c.tokens.Get(employeeID)
+	if err != nil {
+		return nil
+	}
+
+	revoke := token.RefreshToken
+	if revoke == "" {
+		revoke = token.AccessToken
+	}
+	ctx, done := observe(ctx, "revoke_token", employeeID)
+	resp, err := postForm(ctx, "https://oauth2.googleapis.com/revoke", url.Values{"token": {revoke}})
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("google returned %s", resp.Status)
+		}
+	}
+	done(err)
 
+	if dbErr := c.tokens.Delete(employeeID); dbErr != nil {
+		return fmt.Errorf("failed to delete Google token: %w", dbErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke Google token: %w", err)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// RefreshTokens renews the stored tokens that expire before the deadline,
+// so calendar calls find a valid access token, and returns how many were
+// renewed. Tokens Google no longer accepts are forgotten; those employees
+// have to link their calendar again.
+func (c *Client) RefreshTokens(ctx context.Context, before time.Time) (int, error) {
+	if con == nil {
+		return 0, nil
+	}
+	tokens, err := c.tokens.Expiring(before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expiring Google tokens: %w", err)
+	}
+
+	refreshed := 0
+	var errs []error
+	for _, token := range tokens {
+		// Without an access token the source has to ask for a new one.
+		callCtx, done := observe(ctx, "refresh_token", token.EmployeeID)
+		fresh, err := con.TokenSource(callCtx, &oauth2.Token{RefreshToken: token.RefreshToken}).Token()
+		done(err)
+		var retrieve *oauth2.RetrieveError
+		if errors.As(err, &retrieve) && retrieve.ErrorCode == "invalid_grant" {
+			logging.FromContext(ctx).Warn("Google token was revoked; forgetting it", "employee_id", token.EmployeeID)
+			if err := c.tokens.Delete(token.EmployeeID); err != nil {
+				errs = append(errs, fmt.Errorf("employee %d: %w", token.EmployeeID, err))
+			}
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("employee %d: %w", token.EmployeeID, err))
+			continue
+		}
+
+		renewed := models.GoogleToken{
+			EmployeeID:   token.EmployeeID,
+			AccessToken:  fresh.AccessToken,
+			RefreshToken: fresh.RefreshToken,
+			Expiry:       fresh.Expiry,
+		}
+		if renewed.RefreshToken == "" {
+			renewed.RefreshToken = token.RefreshToken
+		}
+		if err := c.tokens.Save(&renewed); err != nil {
+			errs = append(errs, fmt.Errorf("employee %d: %w", token.EmployeeID, err))
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, errors.Join(errs...)
+}
+
+// postForm is http.PostForm bound to ctx and tagged with its request ID.
+func postForm(ctx context.Context, target string, values url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.HeaderRequestID, id)
+	}
+	return http.DefaultClient.Do(req)
+}
@@ -0,0 +1,161 @@
+// Package health answers the probes of the orchestrator: /healthz says the
+// process is alive, /readyz whether it should get traffic and /version which
+// build it runs.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/version"
+	"gorm.io/gorm"
+)
+
+// checkTimeout bounds each readiness check, so a hanging dependency fails
+// the probe instead of stalling it.
+const checkTimeout = 2 * time.Second
+
+// ErrShuttingDown is reported by /readyz once a graceful shutdown started.
+var ErrShuttingDown = errors.New("shutting down")
+
+// Check is one condition the server needs to serve requests.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Database checks that the database answers.
+func Database(db *gorm.DB) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}}
+}
+
+// Migrations checks that the schema is at the version this build expects.
+func Migrations(db *gorm.DB) Check {
+	return Check{Name: "migrations", Run: func(ctx context.Context) error {
+		return migrations.Check(db.WithContext(ctx))
+	}}
+}
+
+// Scheduler checks that the background jobs are being scheduled.
+func Scheduler(scheduler interface{ Running() bool }) Check {
+	return Check{Name: "scheduler", Run: func(context.Context) error {
+		if !scheduler.Running() {
+			return errors.New("not running")
+		}
+		return nil
+	}}
+}
+
+// SMTP checks that the mail server accepts connections.
+func SMTP(host string, port int) Check {
+	return Check{Name: "smtp", Run: func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}}
+}
+
+// Report is the answer of /readyz: "ok" or the error of every check.
+type Report struct {
+	Status string            `json:"status" example:"ok"`
+	Checks map[string]string `json:"checks"`
+}
+
+// Checker serves the probes.
+type Checker struct {
+	checks   []Check
+	draining atomic.Bool
+}
+
+func New(checks ...Check) *Checker {
+	return &Checker{checks: checks}
+}
+
+// Drain makes the server report itself not ready from now on, so the
+// orchestrator stops sending traffic while it shuts down.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Ready runs every check and reports whether all of them passed.
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+	report := Report{Status: "ok", Checks: map[string]string{}}
+	fail := func(name string, err error) {
+		report.Status = "unavailable"
+		report.Checks[name] = err.Error()
+	}
+	if c.draining.Load() {
+		fail("shutdown", ErrShuttingDown)
+	}
+	for _, check := range c.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := check.Run(checkCtx)
+		cancel()
+		if err != nil {
+			fail(check.Name, err)
+			continue
+		}
+		report.Checks[check.Name] = "ok"
+	}
+	return report, report.Status == "ok"
+}
+
+// Healthz godoc
+// @Summary Liveness probe
+// @Description Answers as long as the process is able to serve HTTP. It does not look at the database or any other dependency; see /readyz for that.
+// @Tags Health
+// @Produce plain
+// @Success 200 {string} string "ok"
+// @Router /healthz [get]
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, "ok")
+}
+
+// Readyz godoc
+// @Summary Readiness probe
+// @Description Reports whether the server should get traffic: the database answers, its schema is up to date, the job scheduler runs and, when configured, the SMTP server is reachable. Fails as soon as a graceful shutdown starts.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report "Not ready; checks lists what failed"
+// @Router /readyz [get]
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	report, ok := c.Ready(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		logging.FromContext(r.Context()).Warn("Not ready", "checks", report.Checks)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// Version godoc
+// @Summary Build information
+// @Description Returns the version, git commit and build date of the running server.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} version.Info
+// @Router /version [get]
+func (c *Checker) Version(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version.Get())
+}
@@ -0,0 +1,96 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type scheduler bool
+
+func (s scheduler) Running() bool { return bool(s) }
+
+func openDB(t *testing.T, migrated bool) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	// Every connection to :memory: opens a database of its own.
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	if migrated {
+		_, err = migrations.Up(db)
+		assert.NoError(t, err)
+	}
+	return db
+}
+
+func readyz(t *testing.T, c *Checker) (int, Report) {
+	rr := httptest.NewRecorder()
+	c.Readyz(rr, httptest.NewRequest("GET", "/readyz", nil))
+	var report Report
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	return rr.Code, report
+}
+
+func TestReadyWhenEveryCheckPasses(t *testing.T) {
+	db := openDB(t, true)
+	c := New(Database(db), Migrations(db), Scheduler(scheduler(true)))
+
+	status, report := readyz(t, c)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, Report{Status: "ok", Checks: map[string]string{
+		"database": "ok", "migrations": "ok", "scheduler": "ok",
+	}}, report)
+
+	c.Drain()
+	status, report = readyz(t, c)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "unavailable", report.Status)
+	assert.Equal(t, "shutting down", report.Checks["shutdown"])
+	assert.Equal(t, "ok", report.Checks["database"])
+}
+
+func TestNotReadyNamesFailedChecks(t *testing.T) {
+	db := openDB(t, false)
+	c := New(Database(db), Migrations(db), Scheduler(scheduler(false)))
+
+	status, report := readyz(t, c)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "ok", report.Checks["database"])
+	assert.Contains(t, report.Checks["migrations"], "run the migrate command")
+	assert.Equal(t, "not running", report.Checks["scheduler"])
+}
+
+func TestSMTPCheckDialsTheServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	addr := listener.Addr().(*net.TCPAddr)
+	check := SMTP("127.0.0.1", addr.Port)
+	assert.NoError(t, check.Run(context.Background()))
+
+	listener.Close()
+	assert.Error(t, check.Run(context.Background()))
+}
+
+func TestLivenessAndVersion(t *testing.T) {
+	c := New()
+	c.Drain()
+	rr := httptest.NewRecorder()
+	c.Healthz(rr, httptest.NewRequest("GET", "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, "liveness does not depend on readiness")
+
+	rr = httptest.NewRecorder()
+	c.Version(rr, httptest.NewRequest("GET", "/version", nil))
+	var info map[string]string
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &info))
+	assert.Equal(t, "dev", info["version"])
+	assert.NotEmpty(t, info["go_version"])
+}
@@ -0,0 +1,329 @@
+// Package jobs runs the named background jobs of the server on their cron
+// schedules. The state of every job, whether it is paused, how its last run
+// went and when it runs next, is kept in the database, so it is shared by
+// all instances and survives restarts. Only the leader runs scheduled jobs;
+// any instance can run one on demand.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/metrics"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracing"
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// staleAfter is how long a run may take before it is assumed to have died
+// with its instance and the job may start again.
+const staleAfter = time.Hour
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job is already running")
+	// ErrStopped is returned for runs requested once Stop was called.
+	ErrStopped = errors.New("scheduler is stopped")
+)
+
+// Job is a unit of background work.
+type Job struct {
+	// Name identifies the job in the database and the admin API.
+	Name        string
+	Description string
+	// Schedule is a cron expression such as "@every 1m" or "0 7 * * *".
+	Schedule string
+	// Run does the work due at now and returns how many items it handled.
+	Run func(ctx context.Context, now time.Time) (int, error)
+}
+
+// Leader reports whether this instance runs the scheduled jobs, e.g.
+// *leader.Elector.
+type Leader interface {
+	IsLeader() bool
+}
+
+type entry struct {
+	job      Job
+	schedule cron.Schedule
+}
+
+// Scheduler runs registered jobs on their schedules.
+type Scheduler struct {
+	repo   repository.JobRepository
+	leader Leader
+	holder string
+	cron   *cron.Cron
+	now    func() time.Time
+
+	// mu guards entries, and orders counting a run in running against
+	// Stop cancelling ctx, so that Stop waits for every run it let start.
+	mu      sync.Mutex
+	entries map[string]*entry
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+	started atomic.Bool
+}
+
+// New returns a scheduler storing job state in repo. Scheduled runs are
+// skipped unless leader leads; a nil leader always does. holder names this
+// instance in the job state.
+func New(repo repository.JobRepository, leader Leader, holder string) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		repo:    repo,
+		leader:  leader,
+		holder:  holder,
+		cron:    cron.New(),
+		now:     time.Now,
+		entries: map[string]*entry{},
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Register adds the job to the scheduler and to the database, keeping the
+// state stored for a job of the same name.
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: invalid schedule %q: %w", job.Name, job.Schedule, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[job.Name]; ok {
+		return fmt.Errorf("job %s is registered twice", job.Name)
+	}
+	if err := s.repo.Register(job.Name, job.Schedule); err != nil {
+		return fmt.Errorf("failed to register job %s: %w", job.Name, err)
+	}
+	e := &entry{job: job, schedule: schedule}
+	s.entries[job.Name] = e
+	s.cron.Schedule(schedule, cron.FuncJob(func() { s.scheduled(e) }))
+	return s.updateNextRun(e)
+}
+
+// Start runs the jobs on their schedules until Stop.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+	s.started.Store(true)
+}
+
+// Running reports whether the jobs are being scheduled: Start was called
+// and Stop was not.
+func (s *Scheduler) Running() bool {
+	return s.started.Load()
+}
+
+// Stop stops scheduling jobs, asks running jobs to stop and waits for them
+// until ctx expires.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.started.Store(false)
+	stopped := s.cron.Stop()
+	s.mu.Lock()
+	s.cancel()
+	s.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		<-stopped.Done()
+		s.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// List returns the registered jobs with their state and descriptions.
+func (s *Scheduler) List() ([]models.Job, map[string]string, error) {
+	stored, err := s.repo.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	descriptions := map[string]string{}
+	var jobs []models.Job
+	for _, job := range stored {
+		if e, ok := s.entries[job.Name]; ok {
+			jobs = append(jobs, job)
+			descriptions[job.Name] = e.job.Description
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs, descriptions, nil
+}
+
+// Get returns the state and description of a registered job.
+func (s *Scheduler) Get(name string) (models.Job, string, error) {
+	e, err := s.entry(name)
+	if err != nil {
+		return models.Job{}, "", err
+	}
+	job, err := s.repo.Get(name)
+	return job, e.job.Description, err
+}
+
+// Trigger runs the job now in the background, even if it is paused or this
+// instance does not lead. It fails with ErrJobRunning if the job is running
+// anywhere and with ErrStopped once Stop was called.
+func (s *Scheduler) Trigger(name string) error {
+	e, err := s.entry(name)
+	if err != nil {
+		return err
+	}
+	if !s.track() {
+		return ErrStopped
+	}
+	started := s.now()
+	ok, err := s.repo.Begin(name, s.holder, started, started.Add(-staleAfter))
+	if err != nil {
+		s.running.Done()
+		return fmt.Errorf("failed to start job %s: %w", name, err)
+	}
+	if !ok {
+		s.running.Done()
+		return ErrJobRunning
+	}
+	go func() {
+		defer s.running.Done()
+		s.execute(e, started)
+	}()
+	return nil
+}
+
+// SetPaused pauses or resumes the scheduled runs of the job on every
+// instance.
+func (s *Scheduler) SetPaused(name string, paused bool) error {
+	e, err := s.entry(name)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.SetPaused(name, paused); err != nil {
+		return err
+	}
+	if paused {
+		return s.repo.SetNextRun(name, nil)
+	}
+	return s.updateNextRun(e)
+}
+
+// track counts a run for Stop to wait for, unless Stop was called already.
+func (s *Scheduler) track() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return false
+	}
+	s.running.Add(1)
+	return true
+}
+
+func (s *Scheduler) entry(name string) (*entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[name]
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+	return e, nil
+}
+
+// scheduled is called by cron on every instance.
+func (s *Scheduler) scheduled(e *entry) {
+	if s.leader != nil && !s.leader.IsLeader() {
+		return
+	}
+	state, err := s.repo.Get(e.job.Name)
+	if err != nil {
+		slog.Error("Failed to load job", "job", e.job.Name, "error", err)
+		return
+	}
+	if state.Paused {
+		return
+	}
+	if !s.track() {
+		return
+	}
+	defer s.running.Done()
+	started := s.now()
+	ok, err := s.repo.Begin(e.job.Name, s.holder, started, started.Add(-staleAfter))
+	if err != nil {
+		slog.Error("Failed to start job", "job", e.job.Name, "error", err)
+		return
+	}
+	if !ok {
+		slog.Warn("Skipping job: still running", "job", e.job.Name)
+		return
+	}
+	s.execute(e, started)
+}
+
+// execute runs a job marked as running and records the outcome. Every run
+// gets an ID that is carried like a request ID, so the emails and calendar
+// calls of the run can be traced back to it, and its own trace.
+func (s *Scheduler) execute(e *entry, started time.Time) {
+	id := logging.NewRequestID()
+	logger := slog.Default().With("job", e.job.Name, "request_id", id)
+	ctx := logging.WithLogger(logging.WithRequestID(s.ctx, id), logger)
+	ctx, span := tracing.Start(ctx, "job."+e.job.Name, attribute.String("request_id", id))
+
+	processed, err := s.safeRun(ctx, e, started)
+	span.SetAttributes(attribute.Int("job.processed", processed))
+	tracing.End(span, err)
+	finished := s.now()
+	result := models.JobSucceeded
+	if err != nil {
+		result = models.JobFailed
+		logger.Error("Job failed", "duration_ms", finished.Sub(started).Milliseconds(), "error", err)
+	} else if processed > 0 {
+		logger.Info("Job finished", "processed", processed, "duration_ms", finished.Sub(started).Milliseconds())
+	}
+	metrics.JobDuration.WithLabelValues(e.job.Name, string(result)).Observe(finished.Sub(started).Seconds())
+
+	run := models.JobRun{StartedAt: started, FinishedAt: finished, Processed: processed, Err: err}
+	if state, getErr := s.repo.Get(e.job.Name); getErr == nil && !state.Paused {
+		next := e.schedule.Next(finished)
+		run.NextRunAt = &next
+	}
+	if err := s.repo.Finish(e.job.Name, run); err != nil {
+		logger.Error("Failed to record job run", "error", err)
+	}
+}
+
+// safeRun turns a panic in the job into an error, so that a failing job
+// neither stays marked as running nor takes the server down.
+func (s *Scheduler) safeRun(ctx context.Context, e *entry, now time.Time) (processed int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return e.job.Run(ctx, now)
+}
+
+func (s *Scheduler) updateNextRun(e *entry) error {
+	state, err := s.repo.Get(e.job.Name)
+	if err != nil {
+		return err
+	}
+	if state.Paused {
+		return nil
+	}
+	next := e.schedule.Next(s.now())
+	return s.repo.SetNextRun(e.job.Name, &next)
+}
@@ -0,0 +1,182 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type follower struct{}
+
+func (follower) IsLeader() bool { return false }
+
+func newTestScheduler(t *testing.T, leader Leader) (*Scheduler, repository.JobRepository) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	// Every connection to :memory: opens a database of its own.
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	repo := repository.NewJobRepository(db)
+	s := New(repo, leader, "test")
+	t.Cleanup(func() { s.Stop(context.Background()) })
+	return s, repo
+}
+
+// wait stops the scheduler, which waits for triggered runs to finish.
+func wait(t *testing.T, s *Scheduler) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, s.Stop(ctx))
+}
+
+func TestTriggeredRunsAreRecorded(t *testing.T) {
+	s, repo := newTestScheduler(t, nil)
+	calls := 0
+	assert.NoError(t, s.Register(Job{Name: "count", Schedule: "@every 1h",
+		Run: func(context.Context, time.Time) (int, error) {
+			calls++
+			if calls == 2 {
+				return 0, errors.New("database unavailable")
+			}
+			return 3, nil
+		}}))
+
+	job, err := repo.Get("count")
+	assert.NoError(t, err)
+	assert.Equal(t, models.JobIdle, job.Status)
+	assert.NotNil(t, job.NextRunAt)
+
+	assert.NoError(t, s.Trigger("count"))
+	s.running.Wait()
+	job, _ = repo.Get("count")
+	assert.Equal(t, models.JobSucceeded, job.Status)
+	assert.Equal(t, 3, job.LastProcessed)
+	assert.NotNil(t, job.LastFinishedAt)
+
+	assert.NoError(t, s.Trigger("count"))
+	wait(t, s)
+	job, _ = repo.Get("count")
+	assert.Equal(t, models.JobFailed, job.Status)
+	assert.Equal(t, "database unavailable", job.LastError)
+	assert.EqualValues(t, 2, job.Runs)
+	assert.EqualValues(t, 1, job.Failures)
+	assert.Empty(t, job.RunBy)
+}
+
+func TestPanicsFailTheRun(t *testing.T) {
+	s, repo := newTestScheduler(t, nil)
+	assert.NoError(t, s.Register(Job{Name: "broken", Schedule: "@every 1h",
+		Run: func(context.Context, time.Time) (int, error) { panic("nil map") }}))
+
+	assert.NoError(t, s.Trigger("broken"))
+	wait(t, s)
+	job, _ := repo.Get("broken")
+	assert.Equal(t, models.JobFailed, job.Status)
+	assert.Equal(t, "panic: nil map", job.LastError)
+}
+
+func TestJobsDoNotRunTwiceAtOnce(t *testing.T) {
+	s, _ := newTestScheduler(t, nil)
+	release := make(chan struct{})
+	assert.NoError(t, s.Register(Job{Name: "slow", Schedule: "@every 1h",
+		Run: func(context.Context, time.Time) (int, error) {
+			<-release
+			return 0, nil
+		}}))
+
+	assert.NoError(t, s.Trigger("slow"))
+	assert.ErrorIs(t, s.Trigger("slow"), ErrJobRunning)
+	close(release)
+	wait(t, s)
+
+	assert.ErrorIs(t, s.Trigger("missing"), ErrUnknownJob)
+}
+
+func TestNoRunsStartOnceStopped(t *testing.T) {
+	s, repo := newTestScheduler(t, nil)
+	calls := 0
+	assert.NoError(t, s.Register(Job{Name: "count", Schedule: "@every 1h",
+		Run: func(context.Context, time.Time) (int, error) {
+			calls++
+			return 0, nil
+		}}))
+	e, _ := s.entry("count")
+
+	wait(t, s)
+	assert.ErrorIs(t, s.Trigger("count"), ErrStopped)
+	s.scheduled(e)
+	assert.Zero(t, calls)
+	job, _ := repo.Get("count")
+	assert.Zero(t, job.Runs)
+	assert.NotEqual(t, models.JobRunning, job.Status)
+}
+
+func TestScheduledRunsHonourPauseAndLeadership(t *testing.T) {
+	s, repo := newTestScheduler(t, nil)
+	calls := 0
+	assert.NoError(t, s.Register(Job{Name: "tick", Schedule: "@every 1m",
+		Run: func(context.Context, time.Time) (int, error) {
+			calls++
+			return 0, nil
+		}}))
+	e, _ := s.entry("tick")
+
+	assert.NoError(t, s.SetPaused("tick", true))
+	s.scheduled(e)
+	assert.Zero(t, calls)
+	job, _ := repo.Get("tick")
+	assert.True(t, job.Paused)
+	assert.Nil(t, job.NextRunAt)
+
+	// A manual run works while paused.
+	assert.NoError(t, s.Trigger("tick"))
+	s.running.Wait()
+	assert.Equal(t, 1, calls)
+
+	assert.NoError(t, s.SetPaused("tick", false))
+	s.scheduled(e)
+	assert.Equal(t, 2, calls)
+	job, _ = repo.Get("tick")
+	assert.NotNil(t, job.NextRunAt)
+
+	s.leader = follower{}
+	s.scheduled(e)
+	assert.Equal(t, 2, calls, "only the leader runs scheduled jobs")
+}
+
+func TestRegisterKeepsStoredState(t *testing.T) {
+	s, repo := newTestScheduler(t, nil)
+	noop := func(context.Context, time.Time) (int, error) { return 0, nil }
+	assert.NoError(t, s.Register(Job{Name: "digest", Schedule: "0 7 * * *", Run: noop}))
+	assert.NoError(t, s.SetPaused("digest", true))
+	assert.Error(t, s.Register(Job{Name: "digest", Schedule: "0 7 * * *", Run: noop}))
+
+	// As after a restart with a new schedule.
+	restarted := New(repo, nil, "test")
+	assert.NoError(t, restarted.Register(Job{Name: "digest", Schedule: "0 8 * * *", Run: noop}))
+	job, _ := repo.Get("digest")
+	assert.True(t, job.Paused)
+	assert.Equal(t, "0 8 * * *", job.Schedule)
+
+	assert.Error(t, restarted.Register(Job{Name: "bad", Schedule: "every day", Run: noop}))
+}
+
+func TestRunningBetweenStartAndStop(t *testing.T) {
+	s, _ := newTestScheduler(t, nil)
+	assert.False(t, s.Running())
+	s.Start()
+	assert.True(t, s.Running())
+	wait(t, s)
+	assert.False(t, s.Running())
+}
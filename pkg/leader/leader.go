@@ -0,0 +1,147 @@
+// Package leader elects one instance among the replicas of the server to
+// run the scheduled jobs. Leadership is a lease in the database that the
+// leader keeps renewing; when it stops, because the instance crashed or shut
+// down, another instance takes the lease over once it expires.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+)
+
+// DefaultTTL is how long a lease lasts without renewal, and so how long the
+// jobs may pause when the leader dies.
+const DefaultTTL = 30 * time.Second
+
+// Elector campaigns for one named lease on behalf of this instance.
+type Elector struct {
+	leases repository.LeaseRepository
+	name   string
+	holder string
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	leading bool
+	expires time.Time
+
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+func New(leases repository.LeaseRepository, name, holder string, ttl time.Duration) *Elector {
+	return &Elector{leases: leases, name: name, holder: holder, ttl: ttl, now: time.Now}
+}
+
+// Holder names this instance: host name and process ID.
+func Holder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Campaign tries once to take or renew the lease and reports whether this
+// instance leads.
+func (e *Elector) Campaign() bool {
+	now := e.now()
+	expires := now.Add(e.ttl)
+	acquired, err := e.leases.Acquire(e.name, e.holder, now, expires)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err != nil {
+		// Keep leading on a failed renewal until the lease runs out; nobody
+		// else can take it before then.
+		slog.Error("Failed to renew lease", "lease", e.name, "error", err)
+		return e.leading && now.Before(e.expires)
+	}
+	if acquired != e.leading {
+		if acquired {
+			slog.Info("Became the leader", "lease", e.name, "holder", e.holder)
+		} else {
+			slog.Warn("Lost the lead", "lease", e.name, "holder", e.holder)
+		}
+	}
+	e.leading = acquired
+	if acquired {
+		e.expires = expires
+	}
+	return acquired
+}
+
+// IsLeader reports whether this instance holds an unexpired lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leading && e.now().Before(e.expires)
+}
+
+// Start campaigns in the background every third of the TTL, so a lease is
+// renewed well before it expires, until Stop.
+func (e *Elector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.stopped = make(chan struct{})
+	go func() {
+		defer close(e.stopped)
+		e.run(ctx)
+	}()
+}
+
+// Stop ends the campaign and releases the lease so another instance can
+// take over straight away.
+func (e *Elector) Stop(ctx context.Context) {
+	if e.cancel == nil {
+		return
+	}
+	e.cancel()
+	select {
+	case <-e.stopped:
+	case <-ctx.Done():
+	}
+}
+
+func (e *Elector) run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+	for {
+		e.Campaign()
+		select {
+		case <-ctx.Done():
+			e.Resign()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Resign gives up the lease if this instance holds it.
+func (e *Elector) Resign() {
+	e.mu.Lock()
+	leading := e.leading
+	e.leading = false
+	e.mu.Unlock()
+	if !leading {
+		return
+	}
+	if err := e.leases.Release(e.name, e.holder); err != nil {
+		slog.Error("Failed to release lease", "lease", e.name, "error", err)
+	}
+}
+
+// Guard wraps a job so that it only runs while this instance leads.
+func (e *Elector) Guard(job func()) func() {
+	return func() {
+		if e.IsLeader() {
+			job()
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package leader
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openShared opens n connections to one SQLite file, like n replicas
+// sharing a database.
+func openShared(t *testing.T, n int) []*gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "leases.db") + "?_busy_timeout=5000"
+	dbs := make([]*gorm.DB, n)
+	for i := range dbs {
+		db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		assert.NoError(t, err)
+		dbs[i] = db
+	}
+	_, err := migrations.Up(dbs[0])
+	assert.NoError(t, err)
+	return dbs
+}
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestLeaseFailsOverWhenNotRenewed(t *testing.T) {
+	dbs := openShared(t, 2)
+	c := &clock{now: time.Now()}
+	a := New(repository.NewLeaseRepository(dbs[0]), "scheduler", "a", 30*time.Second)
+	b := New(repository.NewLeaseRepository(dbs[1]), "scheduler", "b", 30*time.Second)
+	a.now, b.now = c.Now, c.Now
+
+	assert.True(t, a.Campaign())
+	assert.False(t, b.Campaign())
+
+	// Renewing keeps the lead past the original expiry.
+	c.Advance(20 * time.Second)
+	assert.True(t, a.Campaign())
+	c.Advance(20 * time.Second)
+	assert.False(t, b.Campaign())
+	assert.True(t, a.IsLeader())
+
+	// a stops renewing, e.g. because it crashed.
+	c.Advance(31 * time.Second)
+	assert.False(t, a.IsLeader())
+	assert.True(t, b.Campaign())
+	assert.False(t, a.Campaign())
+
+	b.Resign()
+	assert.False(t, b.IsLeader())
+	assert.True(t, a.Campaign(), "a resigned lease is free at once")
+}
+
+func TestTwoSchedulersRunEachJobOnce(t *testing.T) {
+	dbs := openShared(t, 2)
+	var mu sync.Mutex
+	runs := map[string]int{}
+
+	type instance struct {
+		elector   *Elector
+		scheduler *cron.Cron
+	}
+	start := func(name string, db *gorm.DB) instance {
+		elector := New(repository.NewLeaseRepository(db), "scheduler", name, 1500*time.Millisecond)
+		elector.Start()
+		scheduler := cron.New()
+		_, err := scheduler.AddFunc("@every 1s", elector.Guard(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			runs[name]++
+		}))
+		assert.NoError(t, err)
+		scheduler.Start()
+		return instance{elector, scheduler}
+	}
+	snapshot := func() map[string]int {
+		mu.Lock()
+		defer mu.Unlock()
+		copied := map[string]int{}
+		for k, v := range runs {
+			copied[k] = v
+		}
+		return copied
+	}
+
+	first := start("first", dbs[0])
+	time.Sleep(100 * time.Millisecond) // let first win the initial election
+	second := start("second", dbs[1])
+	defer second.scheduler.Stop()
+	defer second.elector.Stop(context.Background())
+
+	time.Sleep(2500 * time.Millisecond)
+	before := snapshot()
+	assert.GreaterOrEqual(t, before["first"], 2)
+	assert.Zero(t, before["second"], "only the leader runs jobs")
+
+	// The leader shuts down; the other instance takes over.
+	<-first.scheduler.Stop().Done()
+	first.elector.Stop(context.Background())
+	time.Sleep(2500 * time.Millisecond)
+	after := snapshot()
+	assert.Equal(t, before["first"], after["first"])
+	assert.GreaterOrEqual(t, after["second"], 1)
+}
@@ -0,0 +1,354 @@
+// Package listing implements the query parameters shared by the list
+// endpoints: cursor pagination, filters, sorting and sparse field selection.
+//
+//	?limit=20&sort=-start_time&status=confirmed&fields=id,start_time,room_id&cursor=...
+//
+// Results are ordered by the sort key and then by ID, and a page is fetched
+// with a keyset condition on both, so pages stay stable while rows are added.
+// The cursor of the next page is returned in the X-Next-Cursor header and in a
+// Link header with rel="next"; it is absent on the last page.
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// Kind is the type of a sort key, used to decode cursor values.
+type Kind int
+
+const (
+	String Kind = iota
+	Number
+	Time
+)
+
+// SortKey is a column that results can be ordered by. Field names the struct
+// field holding the value, which is read from the last row to build the
+// next cursor.
+type SortKey struct {
+	Column string
+	Field  string
+	Kind   Kind
+}
+
+// Filter narrows the query using the value of a query parameter.
+type Filter func(db *gorm.DB, value string) (*gorm.DB, error)
+
+// Spec describes what a list endpoint supports.
+type Spec struct {
+	Sorts map[string]SortKey
+	// DefaultSort is a key of Sorts, prefixed with "-" for descending order.
+	DefaultSort string
+	Filters     map[string]Filter
+}
+
+// Query is a parsed list request.
+type Query struct {
+	spec   Spec
+	values url.Values
+	limit  int
+	sort   string
+	key    SortKey
+	desc   bool
+	after  *cursor
+	fields []string
+}
+
+type cursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// Parse reads limit, sort, cursor and fields from the query string. Filters
+// are checked when the query is applied.
+func Parse(values url.Values, spec Spec) (*Query, error) {
+	q := &Query{spec: spec, values: values, limit: DefaultLimit}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", MaxLimit)
+		}
+		q.limit = limit
+	}
+
+	q.sort = values.Get("sort")
+	if q.sort == "" {
+		q.sort = spec.DefaultSort
+	}
+	name := strings.TrimPrefix(q.sort, "-")
+	q.desc = name != q.sort
+	key, ok := spec.Sorts[name]
+	if !ok {
+		return nil, fmt.Errorf("cannot sort by %q; use one of %s", name, strings.Join(keys(spec.Sorts), ", "))
+	}
+	q.key = key
+
+	if v := values.Get("cursor"); v != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		var c cursor
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+		if c.Sort != q.sort {
+			return nil, errors.New("cursor was issued for a different sort order")
+		}
+		q.after = &c
+	}
+
+	for _, field := range strings.Split(values.Get("fields"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			q.fields = append(q.fields, field)
+		}
+	}
+	return q, nil
+}
+
+// QueryError is returned by Apply when a filter value or the cursor cannot
+// be used. It is the client's fault, unlike errors from running the query.
+type QueryError struct {
+	Err error
+}
+
+func (e *QueryError) Error() string { return e.Err.Error() }
+
+func (e *QueryError) Unwrap() error { return e.Err }
+
+// IsQueryError reports whether err was caused by an invalid list request.
+func IsQueryError(err error) bool {
+	var qe *QueryError
+	return errors.As(err, &qe)
+}
+
+// Apply adds the filters, the keyset condition, the ordering and the limit to
+// the query. One row more than the page size is fetched to tell whether
+// another page follows.
+func (q *Query) Apply(db *gorm.DB) (*gorm.DB, error) {
+	for _, name := range keys(q.spec.Filters) {
+		value := q.values.Get(name)
+		if value == "" {
+			continue
+		}
+		var err error
+		if db, err = q.spec.Filters[name](db, value); err != nil {
+			return nil, &QueryError{fmt.Errorf("invalid %s: %w", name, err)}
+		}
+	}
+
+	op, dir := ">", "ASC"
+	if q.desc {
+		op, dir = "<", "DESC"
+	}
+	if q.after != nil {
+		value, err := decodeValue(q.after.Value, q.key.Kind)
+		if err != nil {
+			return nil, &QueryError{errors.New("invalid cursor")}
+		}
+		if q.key.Column == "id" {
+			db = db.Where("id "+op+" ?", q.after.ID)
+		} else {
+			db = db.Where(fmt.Sprintf("(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))", q.key.Column, op),
+				value, value, q.after.ID)
+		}
+	}
+	if q.key.Column != "id" {
+		db = db.Order(q.key.Column + " " + dir)
+	}
+	return db.Order("id " + dir).Limit(q.limit + 1), nil
+}
+
+// Page trims rows (a pointer to a slice of models) to the page size and sets
+// the next-cursor headers. The cursor is read from the models, so Page must
+// run before they are mapped to response types.
+func (q *Query) Page(w http.ResponseWriter, r *http.Request, rows interface{}) {
+	slice := reflect.ValueOf(rows).Elem()
+	if slice.Len() <= q.limit {
+		return
+	}
+	slice.SetLen(q.limit)
+	next, err := q.nextCursor(slice.Index(q.limit - 1))
+	if err == nil {
+		w.Header().Set("X-Next-Cursor", next)
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, nextURL(r, next)))
+	}
+}
+
+// WriteJSON writes a page of responses as JSON, keeping only the requested
+// fields.
+func (q *Query) WriteJSON(w http.ResponseWriter, page interface{}) {
+	resp, err := json.Marshal(page)
+	if err == nil && len(q.fields) > 0 {
+		resp, err = selectFields(resp, q.fields)
+	}
+	if err != nil {
+		apierror.Error(w, "Error marshalling data", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+func (q *Query) nextCursor(row reflect.Value) (string, error) {
+	id := row.FieldByName("ID")
+	value := row.FieldByName(q.key.Field)
+	if !id.IsValid() || !value.IsValid() {
+		return "", fmt.Errorf("row has no field %s", q.key.Field)
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			value = reflect.Zero(value.Type().Elem())
+		} else {
+			value = value.Elem()
+		}
+	}
+	encoded, err := json.Marshal(value.Interface())
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(cursor{Sort: q.sort, Value: encoded, ID: uint(id.Uint())})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeValue(raw json.RawMessage, kind Kind) (interface{}, error) {
+	switch kind {
+	case Time:
+		var t time.Time
+		err := json.Unmarshal(raw, &t)
+		return t.UTC(), err
+	case Number:
+		var n float64
+		err := json.Unmarshal(raw, &n)
+		return n, err
+	default:
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	}
+}
+
+func nextURL(r *http.Request, next string) string {
+	values := r.URL.Query()
+	values.Set("cursor", next)
+	u := *r.URL
+	u.RawQuery = values.Encode()
+	return u.RequestURI()
+}
+
+// selectFields keeps only the given keys of each object in a JSON array.
+func selectFields(data []byte, fields []string) ([]byte, error) {
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	selected := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		selected[i] = map[string]json.RawMessage{}
+		for _, field := range fields {
+			if value, ok := item[field]; ok {
+				selected[i][field] = value
+			}
+		}
+	}
+	return json.Marshal(selected)
+}
+
+func keys[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Text filters with a condition that takes the raw value, e.g. Text("location = ?").
+func Text(condition string) Filter {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		return db.Where(condition, value), nil
+	}
+}
+
+// Contains filters on a column containing the value, ignoring case.
+func Contains(column string) Filter {
+	escape := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		pattern := "%" + escape.Replace(strings.ToLower(value)) + "%"
+		return db.Where("LOWER("+column+") LIKE ? ESCAPE '!'", pattern), nil
+	}
+}
+
+// IDs filters on a column matching one of a comma-separated list of IDs.
+func IDs(column string) Filter {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		var ids []uint64
+		for _, s := range strings.Split(value, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not an ID", s)
+			}
+			ids = append(ids, id)
+		}
+		return db.Where(column+" IN ?", ids), nil
+	}
+}
+
+// Int filters with a numeric comparison, e.g. Int("capacity >= ?").
+func Int(condition string) Filter {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return db.Where(condition, n), nil
+	}
+}
+
+// Bool filters with a condition that takes a boolean, e.g. Bool("requires_approval = ?").
+func Bool(condition string) Filter {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", value)
+		}
+		return db.Where(condition, b), nil
+	}
+}
+
+// Timestamp filters with a time comparison, e.g. Timestamp("end_time > ?").
+// Values are RFC 3339 timestamps or dates (YYYY-MM-DD, midnight UTC).
+func Timestamp(condition string) Filter {
+	return func(db *gorm.DB, value string) (*gorm.DB, error) {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return nil, fmt.Errorf("%q is not a RFC 3339 time or a date", value)
+			}
+		}
+		return db.Where(condition, t.UTC()), nil
+	}
+}
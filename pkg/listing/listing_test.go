@@ -0,0 +1,132 @@
+package listing
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var testSpec = Spec{
+	Sorts: map[string]SortKey{
+		"id":       {Column: "id", Field: "ID", Kind: Number},
+		"location": {Column: "location", Field: "Location", Kind: String},
+		"capacity": {Column: "COALESCE(capacity, 0)", Field: "Capacity", Kind: Number},
+	},
+	DefaultSort: "id",
+	Filters: map[string]Filter{
+		"min_capacity": Int("capacity >= ?"),
+		"name":         Contains("name"),
+	},
+}
+
+func setupRooms(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&models.Room{}))
+	for _, r := range []struct {
+		name     string
+		location string
+		capacity int
+	}{
+		{"Alpha", "East", 4}, {"Beta", "West", 8}, {"Gamma", "East", 8},
+		{"Delta", "North", 12}, {"Epsilon_1", "West", 2},
+	} {
+		capacity := r.capacity
+		db.Create(&models.Room{Name: r.name, Location: r.location, Capacity: &capacity})
+	}
+	return db
+}
+
+// fetch runs one list request and returns the room names and next cursor.
+func fetch(t *testing.T, db *gorm.DB, query string) ([]string, string) {
+	values, _ := url.ParseQuery(query)
+	q, err := Parse(values, testSpec)
+	assert.NoError(t, err)
+	tx, err := q.Apply(db)
+	assert.NoError(t, err)
+	var rooms []models.Room
+	assert.NoError(t, tx.Find(&rooms).Error)
+
+	rec := httptest.NewRecorder()
+	q.Page(rec, httptest.NewRequest("GET", "/rooms?"+query, nil), &rooms)
+	q.WriteJSON(rec, models.NewRoomResponses(rooms))
+	var page []models.RoomResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	var names []string
+	for _, r := range page {
+		names = append(names, r.Name)
+	}
+	return names, rec.Header().Get("X-Next-Cursor")
+}
+
+func TestPagination(t *testing.T) {
+	db := setupRooms(t)
+
+	tests := []struct {
+		name  string
+		query string
+		pages [][]string
+	}{
+		{"by id", "limit=2", [][]string{{"Alpha", "Beta"}, {"Gamma", "Delta"}, {"Epsilon_1"}}},
+		{"descending with ties", "limit=2&sort=-capacity", [][]string{{"Delta", "Gamma"}, {"Beta", "Alpha"}, {"Epsilon_1"}}},
+		{"by text", "limit=3&sort=location", [][]string{{"Alpha", "Gamma", "Delta"}, {"Beta", "Epsilon_1"}}},
+		{"filtered", "limit=2&min_capacity=8", [][]string{{"Beta", "Gamma"}, {"Delta"}}},
+		{"escaped wildcard", "name=n_", [][]string{{"Epsilon_1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			for i, want := range tt.pages {
+				names, next := fetch(t, db, query)
+				assert.Equal(t, want, names)
+				if i == len(tt.pages)-1 {
+					assert.Empty(t, next)
+				} else {
+					assert.NotEmpty(t, next)
+				}
+				query = tt.query + "&cursor=" + next
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{"limit=0", "limit=1000", "sort=password", "cursor=!!"} {
+		values, _ := url.ParseQuery(query)
+		_, err := Parse(values, testSpec)
+		assert.Error(t, err, query)
+	}
+
+	db := setupRooms(t)
+	_, next := fetch(t, db, "limit=1&sort=location")
+	values, _ := url.ParseQuery("sort=id&cursor=" + next)
+	_, err := Parse(values, testSpec)
+	assert.Error(t, err)
+
+	values, _ = url.ParseQuery("min_capacity=lots")
+	q, err := Parse(values, testSpec)
+	assert.NoError(t, err)
+	_, err = q.Apply(db)
+	assert.True(t, IsQueryError(err))
+}
+
+func TestSparseFields(t *testing.T) {
+	db := setupRooms(t)
+	values, _ := url.ParseQuery("limit=1&fields=id,name,capacity")
+	q, _ := Parse(values, testSpec)
+	tx, _ := q.Apply(db)
+	var rooms []models.Room
+	tx.Find(&rooms)
+
+	rec := httptest.NewRecorder()
+	q.Page(rec, httptest.NewRequest("GET", "/rooms", nil), &rooms)
+	q.WriteJSON(rec, models.NewRoomResponses(rooms))
+	assert.JSONEq(t, `[{"id":1,"name":"Alpha","capacity":4}]`, rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Link"), `rel="next"`)
+}
@@ -0,0 +1,146 @@
+// Package logging sets up structured logging with log/slog. Every request
+// gets an ID, and a logger carrying it, the route and the employee travels
+// in the request context, down to the emails and calendar calls the request
+// causes.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/metrics"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderRequestID is read from requests and set on responses and on the
+// calls made to other services.
+const HeaderRequestID = "X-Request-ID"
+
+// New returns a logger writing to w at the level and in the format of cfg.
+func New(w io.Writer, cfg config.LogConfig) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", cfg.Level)
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(cfg.Format) {
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text", "":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+	}
+}
+
+// Setup makes a logger for cfg writing to stderr the default, which the
+// standard log package then writes through as well.
+func Setup(cfg config.LogConfig) error {
+	logger, err := New(os.Stderr, cfg)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	loggerKey
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithLogger returns a copy of ctx carrying the logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// NewRequestID returns a random ID of 16 hex digits.
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID limits the IDs accepted from clients, so that they cannot
+// put arbitrary text into the logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// Middleware gives every request an ID, the one in the X-Request-ID header
+// if the client sent a valid one, and returns it in the same header. The
+// request context gets a logger with the ID, the method, the route and the
+// employee reported by employeeID, and the trace ID when the request is
+// traced. Every request is logged once served. It has to run inside the mux
+// router, which sets the matched route.
+func Middleware(employeeID func(*http.Request) (uint, bool)) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := r.Header.Get(HeaderRequestID)
+			if !validRequestID.MatchString(id) {
+				id = NewRequestID()
+			}
+			w.Header().Set(HeaderRequestID, id)
+
+			logger := slog.Default().With("request_id", id, "method", r.Method, "route", metrics.Route(r))
+			if employee, ok := employeeID(r); ok {
+				logger = logger.With("employee_id", employee)
+			}
+			if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+				logger = logger.With("trace_id", span.TraceID().String())
+			}
+			ctx := WithLogger(WithRequestID(r.Context(), id), logger)
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			level := slog.LevelInfo
+			if rec.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.Log(ctx, level, "Request served", "path", r.URL.Path, "status", rec.status,
+				"duration_ms", time.Since(start).Milliseconds())
+		})
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
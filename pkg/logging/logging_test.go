@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHonoursLevelAndFormat(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := New(&out, config.LogConfig{Level: "warn", Format: "json"})
+	assert.NoError(t, err)
+	logger.Info("hidden")
+	logger.Warn("shown", "booking_id", 7)
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "shown", entry["msg"])
+	assert.Equal(t, 7.0, entry["booking_id"])
+
+	_, err = New(&out, config.LogConfig{Level: "loud", Format: "text"})
+	assert.Error(t, err)
+	_, err = New(&out, config.LogConfig{Level: "info", Format: "xml"})
+	assert.Error(t, err)
+}
+
+// serve runs a request through the middleware, logging as JSON into out, and
+// returns the response and the request ID the handler saw.
+func serve(t *testing.T, out *bytes.Buffer, req *http.Request) (*httptest.ResponseRecorder, string) {
+	logger, err := New(out, config.LogConfig{Level: "info", Format: "json"})
+	assert.NoError(t, err)
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	var seen string
+	router := mux.NewRouter()
+	router.Use(Middleware(func(*http.Request) (uint, bool) { return 42, true }))
+	router.HandleFunc("/bookings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		FromContext(r.Context()).Info("Booking loaded")
+		w.WriteHeader(http.StatusTeapot)
+	})
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr, seen
+}
+
+func TestMiddlewareLogsWithRequestContext(t *testing.T) {
+	var out bytes.Buffer
+	rr, id := serve(t, &out, httptest.NewRequest("GET", "/bookings/5", nil))
+
+	assert.Len(t, id, 16)
+	assert.Equal(t, id, rr.Header().Get(HeaderRequestID))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	for _, line := range lines {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, id, entry["request_id"])
+		assert.Equal(t, "/bookings/{id}", entry["route"])
+		assert.Equal(t, 42.0, entry["employee_id"])
+	}
+	var served map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &served))
+	assert.Equal(t, "Request served", served["msg"])
+	assert.Equal(t, 418.0, served["status"])
+}
+
+func TestMiddlewareKeepsValidClientRequestIDs(t *testing.T) {
+	var out bytes.Buffer
+	req := httptest.NewRequest("GET", "/bookings/5", nil)
+	req.Header.Set(HeaderRequestID, "edge-7f3a")
+	_, id := serve(t, &out, req)
+	assert.Equal(t, "edge-7f3a", id)
+
+	req = httptest.NewRequest("GET", "/bookings/5", nil)
+	req.Header.Set(HeaderRequestID, "bad id\nwith newline")
+	_, id = serve(t, &out, req)
+	assert.NotEqual(t, "bad id\nwith newline", id)
+	assert.Len(t, id, 16)
+}
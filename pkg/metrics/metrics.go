@@ -0,0 +1,146 @@
+// Package metrics exposes the Prometheus metrics of the server on /metrics:
+// HTTP traffic per route, bookings, emails, Google Calendar calls,
+// background jobs and the employee cache.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "meetingroombook"
+
+// Registry holds every metric of the server, along with the Go runtime and
+// process metrics.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	// HTTPRequests counts requests by method, mux route template and status.
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// BookingsCreated counts new bookings; kind is "single" or "group".
+	BookingsCreated = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bookings_created_total",
+		Help:      "Bookings created, by kind (single or group).",
+	}, []string{"kind"})
+
+	// BookingRejections counts booking requests refused because the room
+	// was taken ("conflict") or too small ("capacity").
+	BookingRejections = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "booking_rejections_total",
+		Help:      "Booking requests refused, by kind and reason (conflict or capacity).",
+	}, []string{"kind", "reason"})
+
+	// EmailsSent counts delivery attempts by result, "success" or "failure".
+	EmailsSent = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "emails_sent_total",
+		Help:      "Attempts to deliver an email, by result (success or failure).",
+	}, []string{"result"})
+
+	CalendarDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "google_calendar_request_duration_seconds",
+		Help:      "Latency of Google Calendar API calls by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	CalendarErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "google_calendar_errors_total",
+		Help:      "Failed Google Calendar API calls by operation.",
+	}, []string{"operation"})
+
+	// JobDuration observes every run of a background job, by job and result.
+	JobDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_run_duration_seconds",
+		Help:      "Duration of background job runs by job and result (succeeded or failed).",
+		Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+	}, []string{"job", "result"})
+
+	// CacheRequests counts cache lookups by cache and result, "hit" or "miss".
+	CacheRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the count and latency of requests, labelled with the
+// route template, e.g. /bookings/{id}, so that IDs do not become labels.
+// It has to run inside the mux router, which sets the matched route.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		route := Route(r)
+		HTTPRequests.WithLabelValues(r.Method, route, strconv.Itoa(rec.status)).Inc()
+		HTTPDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Route returns the template of the mux route that matched the request, or
+// "unmatched".
+func Route(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
+
+// ObserveCalendar records a Google Calendar API call that started at start.
+func ObserveCalendar(operation string, start time.Time, err error) {
+	CalendarDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		CalendarErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareLabelsRequestsWithRouteTemplates(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(Middleware)
+	router.HandleFunc("/bookings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["id"] == "0" {
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}).Methods("GET")
+	router.Handle("/metrics", Handler())
+
+	for _, path := range []string{"/bookings/1", "/bookings/2", "/bookings/0"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(HTTPRequests.WithLabelValues("GET", "/bookings/{id}", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(HTTPRequests.WithLabelValues("GET", "/bookings/{id}", "404")))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	assert.Contains(t, body, `meetingroombook_http_request_duration_seconds_count{method="GET",route="/bookings/{id}"} 3`)
+	assert.Contains(t, body, "go_goroutines")
+	assert.False(t, strings.Contains(body, `route="/bookings/1"`), "IDs must not become labels")
+}
+
+func TestObserveCalendarCountsErrors(t *testing.T) {
+	ObserveCalendar("insert_event", time.Now(), nil)
+	ObserveCalendar("insert_event", time.Now(), errors.New("quota exceeded"))
+
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rr.Body.String(), `meetingroombook_google_calendar_request_duration_seconds_count{operation="insert_event"} 2`)
+	assert.Equal(t, 1.0, testutil.ToFloat64(CalendarErrors.WithLabelValues("insert_event")))
+}
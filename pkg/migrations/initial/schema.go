@@ -0,0 +1,104 @@
+// Package initial freezes the models as they were when migration 1 created
+// the schema, so that the migration creates the same tables however the
+// models change later. The types keep the names of the models because gorm
+// derives the columns and constraints of join tables from them.
+package initial
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ApproverGroup struct {
+	gorm.Model
+	Name    string
+	Members []Employee `gorm:"many2many:approver_group_members"`
+}
+
+type Room struct {
+	gorm.Model
+	Name             string
+	Capacity         *int
+	Location         string
+	Floor            int
+	Amenities        []string `gorm:"serializer:json"`
+	RequiresApproval bool
+	ApproverGroupID  *uint
+	ApproverGroup    *ApproverGroup
+	Bookings         []Booking
+}
+
+type Employee struct {
+	gorm.Model
+	Name          string
+	Email         string
+	Password      string
+	HomeFloor     *int
+	Role          string `gorm:"size:16;default:employee"`
+	DeactivatedAt *time.Time
+	Bookings      []Booking
+}
+
+type BookingGroup struct {
+	gorm.Model
+	Title       string
+	EmployeeID  uint
+	CreatedByID uint
+	StartTime   time.Time
+	EndTime     time.Time
+	CalendarID  string
+
+	Employee Employee
+	Bookings []Booking `gorm:"foreignKey:GroupID"`
+}
+
+type Booking struct {
+	gorm.Model
+	RoomID       uint
+	EmployeeID   uint
+	CreatedByID  uint
+	StartTime    time.Time
+	EndTime      time.Time
+	NumAttendees int
+	Status       string `gorm:"size:32;index;default:confirmed"`
+	GroupID      *uint  `gorm:"index"`
+	ExpiresAt    *time.Time
+	ReminderSent bool
+	CalendarID   string
+
+	Room        Room
+	Employee    Employee
+	Attendees   []Employee `gorm:"many2many:booking_attendees"`
+	Transitions []BookingTransition
+}
+
+type BookingTransition struct {
+	gorm.Model
+	BookingID  uint   `gorm:"index"`
+	FromStatus string `gorm:"size:32"`
+	ToStatus   string `gorm:"size:32"`
+	Reason     string
+	ActorID    *uint
+}
+
+type Delegation struct {
+	gorm.Model
+	PrincipalID uint `gorm:"uniqueIndex:idx_delegation_pair"`
+	DelegateID  uint `gorm:"uniqueIndex:idx_delegation_pair"`
+	CanBook     bool
+	CanEdit     bool
+	CanCancel   bool
+
+	Principal Employee
+	Delegate  Employee
+}
+
+type GoogleToken struct {
+	gorm.Model
+	ID           uint      `gorm:"primaryKey"`
+	EmployeeID   uint      `gorm:"not null"`
+	AccessToken  string    `gorm:"type:text;not null"`
+	RefreshToken string    `gorm:"type:text;not null"`
+	Expiry       time.Time `gorm:"not null"`
+}
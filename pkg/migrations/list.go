@@ -0,0 +1,296 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations/initial"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// migrations is the schema history. Append new migrations with the next
+// version; never edit or renumber one that has been released. Migrations
+// work on their own copies of the models, frozen as they were when the
+// migration was written, so that replaying the history always builds the
+// same schema.
+var migrations = []Migration{
+	{
+		// Databases created before migrations were versioned already have
+		// these tables. AutoMigrate leaves them as they are, so running this
+		// migration on them only records the version.
+		Version: 1,
+		Name:    "initial_schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&initial.ApproverGroup{}, &initial.Room{}, &initial.Employee{},
+				&initial.BookingGroup{}, &initial.Booking{}, &initial.BookingTransition{},
+				&initial.Delegation{}, &initial.GoogleToken{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("booking_attendees", "approver_group_members",
+				&initial.BookingTransition{}, &initial.Booking{}, &initial.BookingGroup{},
+				&initial.Delegation{}, &initial.GoogleToken{}, &initial.Room{},
+				&initial.ApproverGroup{}, &initial.Employee{})
+		},
+	},
+	{
+		// Bookings made before delegation existed were created by their
+		// organizer.
+		Version: 2,
+		Name:    "backfill_created_by",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Model(&initial.Booking{}).Where("created_by_id = ?", 0).
+				Update("created_by_id", gorm.Expr("employee_id")).Error; err != nil {
+				return err
+			}
+			return tx.Model(&initial.BookingGroup{}).Where("created_by_id = ?", 0).
+				Update("created_by_id", gorm.Expr("employee_id")).Error
+		},
+		// The backfilled values are what the rows would hold today, so
+		// there is nothing to undo.
+		Down: func(tx *gorm.DB) error { return nil },
+	},
+	{
+		Version: 3,
+		Name:    "create_email_outbox",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&outboxEmail{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&outboxEmail{})
+		},
+	},
+	{
+		Version: 4,
+		Name:    "create_leases",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&lease{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&lease{})
+		},
+	},
+	{
+		// Reminders used to be a single email ten minutes before the start,
+		// tracked by bookings.reminder_sent. Those already sent are recorded
+		// as the default stage so they do not go out again.
+		Version: 5,
+		Name:    "create_reminders",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&reminderSettings{}, &reminder{}); err != nil {
+				return err
+			}
+			if !tx.Migrator().HasColumn(&legacyBooking{}, "reminder_sent") {
+				return nil
+			}
+			var reminded []legacyBooking
+			if err := tx.Where("reminder_sent = ? AND start_time > ?", true, time.Now().UTC()).
+				Find(&reminded).Error; err != nil {
+				return err
+			}
+			for _, b := range reminded {
+				sent := reminder{EmployeeID: b.EmployeeID, Channel: "email",
+					MinutesBefore: 10, StartTime: b.StartTime, SentAt: time.Now()}
+				// Groups were reminded once for all their rooms.
+				if b.GroupID != nil {
+					sent.GroupID = *b.GroupID
+				} else {
+					sent.BookingID = b.ID
+				}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&sent).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropColumn(&legacyBooking{}, "reminder_sent")
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AddColumn(&legacyBooking{}, "ReminderSent"); err != nil {
+				return err
+			}
+			if err := tx.Model(&legacyBooking{}).
+				Where("id IN (?) OR group_id IN (?)",
+					tx.Model(&reminder{}).Select("booking_id"),
+					tx.Model(&reminder{}).Select("group_id")).
+				Update("reminder_sent", true).Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropTable(&reminder{}, &reminderSettings{})
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create_jobs",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&job{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&job{})
+		},
+	},
+	{
+		// Until migrations kept their own models, migration 3 created the
+		// column along with the table.
+		Version: 7,
+		Name:    "add_outbox_request_id",
+		Up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&outboxRequestID{}, "RequestID") {
+				return nil
+			}
+			return tx.Migrator().AddColumn(&outboxRequestID{}, "RequestID")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&outboxRequestID{}, "RequestID")
+		},
+	},
+	{
+		Version: 8,
+		Name:    "create_sessions",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&session{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&session{})
+		},
+	},
+	{
+		Version: 9,
+		Name:    "add_booking_pending_since",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AddColumn(&bookingPendingSince{}, "PendingSince")
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&bookingPendingSince{}, "PendingSince")
+		},
+	},
+	{
+		// On SQLite, dropping a column rebuilds the table without its
+		// indexes, which migration 5 did to bookings. Migration 7 added the
+		// request ID without the index that migration 3 created along with
+		// the column before migrations kept their own models.
+		Version: 10,
+		Name:    "restore_missing_indexes",
+		Up: func(tx *gorm.DB) error {
+			indexes := []struct {
+				model interface{}
+				field string
+			}{
+				{&initial.Booking{}, "DeletedAt"},
+				{&initial.Booking{}, "Status"},
+				{&initial.Booking{}, "GroupID"},
+				{&outboxRequestID{}, "RequestID"},
+			}
+			for _, index := range indexes {
+				if tx.Migrator().HasIndex(index.model, index.field) {
+					continue
+				}
+				if err := tx.Migrator().CreateIndex(index.model, index.field); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		// The indexes belong to the schema of the earlier migrations, so
+		// they stay.
+		Down: func(tx *gorm.DB) error { return nil },
+	},
+}
+
+// outboxEmail is the table created by migration 3.
+type outboxEmail struct {
+	ID            uint   `gorm:"primaryKey"`
+	To            string `gorm:"column:recipient;size:255;not null"`
+	Subject       string `gorm:"size:255;not null"`
+	Body          string `gorm:"type:text;not null"`
+	Status        string `gorm:"size:16;index;not null"`
+	Attempts      int
+	LastError     string    `gorm:"type:text"`
+	NextAttemptAt time.Time `gorm:"index"`
+	ClaimedAt     *time.Time
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (outboxEmail) TableName() string { return "email_outbox" }
+
+// lease is the table created by migration 4.
+type lease struct {
+	Name      string    `gorm:"primaryKey;size:64"`
+	Holder    string    `gorm:"size:255;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// reminderSettings and reminder are the tables created by migration 5.
+type reminderSettings struct {
+	EmployeeID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Stages     string `gorm:"type:text"`
+	UpdatedAt  time.Time
+}
+
+type reminder struct {
+	ID            uint      `gorm:"primaryKey"`
+	BookingID     uint      `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	GroupID       uint      `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	EmployeeID    uint      `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	Channel       string    `gorm:"size:16;not null;uniqueIndex:idx_reminder_once"`
+	MinutesBefore int       `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	StartTime     time.Time `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	SentAt        time.Time
+}
+
+// job is the table created by migration 6.
+type job struct {
+	Name            string `gorm:"primaryKey;size:64"`
+	Schedule        string `gorm:"size:64;not null"`
+	Paused          bool   `gorm:"not null;default:false"`
+	Status          string `gorm:"size:16;not null;default:idle"`
+	RunBy           string `gorm:"size:255"`
+	LastStartedAt   *time.Time
+	LastFinishedAt  *time.Time
+	LastDurationMs  int64
+	LastProcessed   int
+	LastError       string `gorm:"type:text"`
+	NextRunAt       *time.Time
+	Runs            int64 `gorm:"not null;default:0"`
+	Failures        int64 `gorm:"not null;default:0"`
+	TotalDurationMs int64 `gorm:"not null;default:0"`
+	UpdatedAt       time.Time
+}
+
+// outboxRequestID is the column of email_outbox added by migration 7.
+type outboxRequestID struct {
+	RequestID string `gorm:"size:64;index"`
+}
+
+func (outboxRequestID) TableName() string { return "email_outbox" }
+
+// session is the table created by migration 8.
+type session struct {
+	ID         string `gorm:"primaryKey;size:64"`
+	EmployeeID uint   `gorm:"index"`
+	Data       []byte
+	UserAgent  string `gorm:"size:255"`
+	IPAddress  string `gorm:"size:64"`
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time `gorm:"index;not null"`
+}
+
+// bookingPendingSince is the column of bookings added by migration 9.
+type bookingPendingSince struct {
+	PendingSince *time.Time
+}
+
+func (bookingPendingSince) TableName() string { return "bookings" }
+
+// legacyBooking has the columns of bookings that migration 5 reads and
+// drops.
+type legacyBooking struct {
+	ID           uint
+	EmployeeID   uint
+	GroupID      *uint
+	StartTime    time.Time
+	ReminderSent bool
+}
+
+func (legacyBooking) TableName() string { return "bookings" }
@@ -0,0 +1,155 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/dbtest"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations/initial"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestUpDownRoundTrip(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db *gorm.DB) {
+		assert.ErrorIs(t, Check(db), ErrSchemaOutdated)
+
+		ran, err := Up(db)
+		assert.NoError(t, err)
+		assert.Len(t, ran, len(All()))
+		assert.NoError(t, Check(db))
+		assert.True(t, db.Migrator().HasTable(&models.Booking{}))
+
+		// Running again is a no-op.
+		ran, err = Up(db)
+		assert.NoError(t, err)
+		assert.Empty(t, ran)
+
+		reverted, err := Down(db, 1)
+		assert.NoError(t, err)
+		assert.Len(t, reverted, 1)
+		assert.Equal(t, Latest(), reverted[0].Version)
+		assert.ErrorIs(t, Check(db), ErrSchemaOutdated)
+
+		statuses, err := List(db)
+		assert.NoError(t, err)
+		assert.NotNil(t, statuses[0].AppliedAt)
+		assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+
+		_, err = Down(db, len(All()))
+		assert.NoError(t, err)
+		current, err := Current(db)
+		assert.NoError(t, err)
+		assert.Zero(t, current)
+		assert.False(t, db.Migrator().HasTable(&models.Booking{}))
+		assert.False(t, db.Migrator().HasTable("booking_attendees"))
+	})
+}
+
+func TestCheckRejectsNewerSchema(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db *gorm.DB) {
+		_, err := Up(db)
+		assert.NoError(t, err)
+		db.Create(&SchemaMigration{Version: Latest() + 1, Name: "from_the_future"})
+
+		assert.ErrorIs(t, Check(db), ErrSchemaTooNew)
+	})
+}
+
+func TestInitialSchemaAdoptsExistingDatabase(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db *gorm.DB) {
+		// A database created by the old AutoMigrate-on-start code.
+		assert.NoError(t, db.AutoMigrate(&initial.ApproverGroup{}, &initial.Room{}, &initial.Employee{}, &initial.BookingGroup{}, &initial.Booking{}))
+		employee := models.Employee{Name: "Ann", Email: "ann@example.com"}
+		room := models.Room{Name: "Focus"}
+		db.Create(&employee)
+		db.Create(&room)
+		db.Exec("INSERT INTO bookings (employee_id, created_by_id, room_id, status) VALUES (?, 0, ?, 'confirmed')", employee.ID, room.ID)
+
+		_, err := Up(db)
+		assert.NoError(t, err)
+
+		var booking models.Booking
+		db.First(&booking)
+		assert.Equal(t, employee.ID, booking.CreatedByID)
+	})
+}
+
+func TestSentRemindersAreCarriedOver(t *testing.T) {
+	dbtest.Each(t, func(t *testing.T, db *gorm.DB) {
+		_, err := Up(db)
+		assert.NoError(t, err)
+		// Back to version 4, when bookings tracked a single reminder
+		// themselves.
+		_, err = Down(db, int(Latest())-4)
+		assert.NoError(t, err)
+		assert.True(t, db.Migrator().HasColumn(&legacyBooking{}, "reminder_sent"))
+
+		start := time.Now().Add(5 * time.Minute).UTC().Truncate(time.Second)
+		reminded := legacyBooking{EmployeeID: 1, StartTime: start, ReminderSent: true}
+		pending := legacyBooking{EmployeeID: 1, StartTime: start}
+		assert.NoError(t, db.Create(&reminded).Error)
+		assert.NoError(t, db.Create(&pending).Error)
+
+		_, err = Up(db)
+		assert.NoError(t, err)
+		assert.False(t, db.Migrator().HasColumn(&legacyBooking{}, "reminder_sent"))
+
+		var reminders []models.Reminder
+		db.Find(&reminders)
+		assert.Len(t, reminders, 1)
+		assert.Equal(t, reminded.ID, reminders[0].BookingID)
+		assert.Equal(t, 10, reminders[0].MinutesBefore)
+		assert.True(t, start.Equal(reminders[0].StartTime))
+	})
+}
+
+// schemaOf describes the columns and indexes of every table of db.
+func schemaOf(t *testing.T, db *gorm.DB) map[string][]string {
+	tables, err := db.Migrator().GetTables()
+	assert.NoError(t, err)
+	schema := map[string][]string{}
+	for _, table := range tables {
+		var described []string
+		columns, err := db.Migrator().ColumnTypes(table)
+		assert.NoError(t, err)
+		for _, column := range columns {
+			nullable, _ := column.Nullable()
+			described = append(described, fmt.Sprintf("column %s %s null=%t",
+				column.Name(), strings.ToLower(column.DatabaseTypeName()), nullable))
+		}
+		indexes, err := db.Migrator().GetIndexes(table)
+		assert.NoError(t, err)
+		for _, index := range indexes {
+			unique, _ := index.Unique()
+			described = append(described, fmt.Sprintf("index %s %v unique=%t",
+				index.Name(), index.Columns(), unique))
+		}
+		sort.Strings(described)
+		schema[table] = described
+	}
+	return schema
+}
+
+func TestMigrationsMatchTheModels(t *testing.T) {
+	for _, driver := range dbtest.Drivers() {
+		t.Run(driver, func(t *testing.T) {
+			migrated := dbtest.Open(t, driver)
+			_, err := Up(migrated)
+			assert.NoError(t, err)
+
+			modelled := dbtest.Open(t, driver)
+			assert.NoError(t, modelled.AutoMigrate(&SchemaMigration{}, &models.ApproverGroup{}, &models.Room{},
+				&models.Employee{}, &models.BookingGroup{}, &models.Booking{}, &models.BookingTransition{},
+				&models.Delegation{}, &models.GoogleToken{}, &models.OutboxEmail{}, &models.Lease{},
+				&models.ReminderSettings{}, &models.Reminder{}, &models.Job{}, &models.Session{}))
+
+			assert.Equal(t, schemaOf(t, modelled), schemaOf(t, migrated),
+				"a model changed without a migration")
+		})
+	}
+}
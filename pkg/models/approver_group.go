@@ -0,0 +1,47 @@
+package models
+
+import (
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+	"gorm.io/gorm"
+)
+
+type ApproverGroup struct {
+	gorm.Model
+	Name    string     `json:"name"`
+	Members []Employee `json:"members,omitempty" gorm:"many2many:approver_group_members"`
+}
+
+// ApproverGroupDTO represents an approver group for Swagger
+// swagger:model ApproverGroup
+type ApproverGroupDTO struct {
+	Name      string `json:"name"`
+	MemberIDs []uint `json:"member_ids"`
+}
+
+func (d ApproverGroupDTO) Validate() error {
+	var v validation.Validator
+	v.Required("name", d.Name)
+	v.Check(len(d.MemberIDs) > 0, "member_ids", validation.CodeRequired, "at least one member is required")
+	v.IDs("member_ids", d.MemberIDs)
+	return v.Err()
+}
+
+// ApproverGroupResponse is what the API returns for an approver group.
+// swagger:model ApproverGroupResponse
+type ApproverGroupResponse struct {
+	ID      uint              `json:"id"`
+	Name    string            `json:"name"`
+	Members []EmployeeSummary `json:"members"`
+}
+
+func NewApproverGroupResponse(g ApproverGroup) ApproverGroupResponse {
+	return ApproverGroupResponse{ID: g.ID, Name: g.Name, Members: NewEmployeeSummaries(g.Members)}
+}
+
+func NewApproverGroupResponses(groups []ApproverGroup) []ApproverGroupResponse {
+	responses := make([]ApproverGroupResponse, len(groups))
+	for i, g := range groups {
+		responses[i] = NewApproverGroupResponse(g)
+	}
+	return responses
+}
@@ -3,29 +3,172 @@ package models
 import (
 	"time"
 
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
 	"gorm.io/gorm"
 )
 
 type Booking struct {
 	gorm.Model
-	RoomID       uint      `json:"room_id"`
+	RoomID     uint `json:"room_id"`
+	EmployeeID uint `json:"employee_id"`
+	// CreatedByID is the employee who made the booking, which differs from
+	// EmployeeID (the organizer) when a delegate books on their behalf.
+	CreatedByID  uint          `json:"created_by_id"`
+	StartTime    time.Time     `json:"start_time"`
+	EndTime      time.Time     `json:"end_time"`
+	NumAttendees int           `json:"num_attendees"`
+	Status       BookingStatus `json:"status" gorm:"size:32;index;default:confirmed"`
+	// GroupID links the rooms of a multi-room booking.
+	GroupID *uint `json:"group_id,omitempty" gorm:"index"`
+	// ExpiresAt is the deadline after which a pending booking is released.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// PendingSince is when the current approval request was made. Approval
+	// links are only valid for the request they were sent for.
+	PendingSince *time.Time `json:"-"`
+	CalendarID   string     `json:"-"`
+
+	Room        Room
+	Employee    Employee
+	Attendees   []Employee          `json:"attendees,omitempty" gorm:"many2many:booking_attendees"`
+	Transitions []BookingTransition `json:"transitions,omitempty"`
+}
+
+// BeforeSave stores times in UTC. SQLite compares times as text, so
+// offsets must not vary between rows and query arguments.
+func (b *Booking) BeforeSave(tx *gorm.DB) error {
+	b.StartTime = b.StartTime.UTC()
+	b.EndTime = b.EndTime.UTC()
+	if b.ExpiresAt != nil {
+		expiresAt := b.ExpiresAt.UTC()
+		b.ExpiresAt = &expiresAt
+	}
+	if b.PendingSince != nil {
+		pendingSince := b.PendingSince.UTC()
+		b.PendingSince = &pendingSince
+	}
+	return nil
+}
+
+// ApprovalRequest identifies the approval request the booking is waiting on;
+// it is the zero time for bookings that never waited on one.
+func (b Booking) ApprovalRequest() time.Time {
+	if b.PendingSince == nil {
+		return time.Time{}
+	}
+	return *b.PendingSince
+}
+
+// BookingDTO represents a booking for Swagger
+// swagger:model Booking
+type BookingDTO struct {
+	RoomID uint `json:"room_id"`
+	// EmployeeID is the organizer. Delegates may book for the employees who
+	// granted them permission; it defaults to the logged-in employee.
 	EmployeeID   uint      `json:"employee_id"`
 	StartTime    time.Time `json:"start_time"`
 	EndTime      time.Time `json:"end_time"`
 	NumAttendees int       `json:"num_attendees"`
-	ReminderSent bool
-	CalendarID   string
+	AttendeeIDs  []uint    `json:"attendee_ids,omitempty"`
+	Hold         bool      `json:"hold,omitempty"`
+	HoldMinutes  int       `json:"hold_minutes,omitempty"`
+}
 
-	Room     Room
-	Employee Employee
+func (d BookingDTO) Validate() error {
+	var v validation.Validator
+	v.RequiredID("room_id", d.RoomID)
+	v.RequiredTime("start_time", d.StartTime)
+	v.RequiredTime("end_time", d.EndTime)
+	v.After("end_time", d.EndTime, "start_time", d.StartTime)
+	v.NonNegative("num_attendees", d.NumAttendees)
+	v.IDs("attendee_ids", d.AttendeeIDs)
+	if d.Hold {
+		v.Range("hold_minutes", d.HoldMinutes, 0, 60)
+	}
+	return v.Err()
 }
 
-// BookingDTO represents a booking for Swagger
-// swagger:model Booking
-type BookingDTO struct {
+// BookingUpdateDTO represents changes to a booking for Swagger
+// swagger:model BookingUpdate
+type BookingUpdateDTO struct {
 	RoomID       uint      `json:"room_id"`
-	EmployeeID   uint      `json:"employee_id"`
 	StartTime    time.Time `json:"start_time"`
 	EndTime      time.Time `json:"end_time"`
 	NumAttendees int       `json:"num_attendees"`
-}
\ No newline at end of file
+	// AttendeeIDs replaces the attendees when present.
+	AttendeeIDs *[]uint `json:"attendee_ids,omitempty"`
+}
+
+func (d BookingUpdateDTO) Validate() error {
+	var v validation.Validator
+	v.RequiredID("room_id", d.RoomID)
+	v.RequiredTime("start_time", d.StartTime)
+	v.RequiredTime("end_time", d.EndTime)
+	v.After("end_time", d.EndTime, "start_time", d.StartTime)
+	v.NonNegative("num_attendees", d.NumAttendees)
+	if d.AttendeeIDs != nil {
+		v.IDs("attendee_ids", *d.AttendeeIDs)
+	}
+	return v.Err()
+}
+
+// BookingResponse is what the API returns for a booking. The room, organizer
+// and attendees are only included when they were loaded.
+// swagger:model BookingResponse
+type BookingResponse struct {
+	ID           uint              `json:"id"`
+	RoomID       uint              `json:"room_id"`
+	EmployeeID   uint              `json:"employee_id"`
+	CreatedByID  uint              `json:"created_by_id"`
+	StartTime    time.Time         `json:"start_time"`
+	EndTime      time.Time         `json:"end_time"`
+	NumAttendees int               `json:"num_attendees"`
+	Status       BookingStatus     `json:"status"`
+	GroupID      *uint             `json:"group_id,omitempty"`
+	ExpiresAt    *time.Time        `json:"expires_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	Room         *RoomSummary      `json:"room,omitempty"`
+	Employee     *EmployeeSummary  `json:"employee,omitempty"`
+	Attendees    []EmployeeSummary `json:"attendees,omitempty"`
+}
+
+func NewBookingResponse(b Booking) BookingResponse {
+	resp := BookingResponse{
+		ID:           b.ID,
+		RoomID:       b.RoomID,
+		EmployeeID:   b.EmployeeID,
+		CreatedByID:  b.CreatedByID,
+		StartTime:    b.StartTime,
+		EndTime:      b.EndTime,
+		NumAttendees: b.NumAttendees,
+		Status:       b.Status,
+		GroupID:      b.GroupID,
+		ExpiresAt:    b.ExpiresAt,
+		CreatedAt:    b.CreatedAt,
+		UpdatedAt:    b.UpdatedAt,
+	}
+	if b.Room.ID != 0 {
+		resp.Room = &RoomSummary{ID: b.Room.ID, Name: b.Room.Name, Location: b.Room.Location, Floor: b.Room.Floor}
+	}
+	if b.Employee.ID != 0 {
+		employee := NewEmployeeSummary(b.Employee)
+		resp.Employee = &employee
+	}
+	if len(b.Attendees) > 0 {
+		resp.Attendees = NewEmployeeSummaries(b.Attendees)
+	}
+	return resp
+}
+
+// NewBookingResponses maps a list of bookings, returning nil for an empty
+// list so nested lists can be omitted.
+func NewBookingResponses(bookings []Booking) []BookingResponse {
+	if len(bookings) == 0 {
+		return nil
+	}
+	responses := make([]BookingResponse, len(bookings))
+	for i, b := range bookings {
+		responses[i] = NewBookingResponse(b)
+	}
+	return responses
+}
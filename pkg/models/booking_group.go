@@ -0,0 +1,101 @@
+package models
+
+import (
+	"fmt"
+
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+	"gorm.io/gorm"
+)
+
+// BookingGroup ties together the bookings of a meeting that spans several
+// rooms. The rooms are reserved, updated and cancelled as a unit and share a
+// single calendar event.
+type BookingGroup struct {
+	gorm.Model
+	Title       string    `json:"title"`
+	EmployeeID  uint      `json:"employee_id"`
+	CreatedByID uint      `json:"created_by_id"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	CalendarID  string    `json:"-"`
+
+	Employee Employee  `json:"-"`
+	Bookings []Booking `json:"bookings" gorm:"foreignKey:GroupID"`
+}
+
+// BeforeSave stores times in UTC, like Booking.BeforeSave.
+func (g *BookingGroup) BeforeSave(tx *gorm.DB) error {
+	g.StartTime = g.StartTime.UTC()
+	g.EndTime = g.EndTime.UTC()
+	return nil
+}
+
+type BookingGroupRoomDTO struct {
+	RoomID       uint `json:"room_id"`
+	NumAttendees int  `json:"num_attendees"`
+}
+
+// BookingGroupDTO represents a multi-room booking for Swagger
+// swagger:model BookingGroup
+type BookingGroupDTO struct {
+	Title string `json:"title"`
+	// EmployeeID is the organizer, defaulting to the logged-in employee.
+	EmployeeID  uint                  `json:"employee_id,omitempty"`
+	StartTime   time.Time             `json:"start_time"`
+	EndTime     time.Time             `json:"end_time"`
+	Rooms       []BookingGroupRoomDTO `json:"rooms"`
+	AttendeeIDs []uint                `json:"attendee_ids,omitempty"`
+}
+
+func (d BookingGroupDTO) Validate() error {
+	var v validation.Validator
+	v.RequiredTime("start_time", d.StartTime)
+	v.RequiredTime("end_time", d.EndTime)
+	v.After("end_time", d.EndTime, "start_time", d.StartTime)
+	v.Check(len(d.Rooms) > 0, "rooms", validation.CodeRequired, "at least one room is required")
+	seen := map[uint]bool{}
+	for i, r := range d.Rooms {
+		field := fmt.Sprintf("rooms[%d]", i)
+		v.RequiredID(field+".room_id", r.RoomID)
+		v.NonNegative(field+".num_attendees", r.NumAttendees)
+		v.Check(r.RoomID == 0 || !seen[r.RoomID], field+".room_id", validation.CodeInvalid,
+			fmt.Sprintf("room %d is listed more than once", r.RoomID))
+		seen[r.RoomID] = true
+	}
+	v.IDs("attendee_ids", d.AttendeeIDs)
+	return v.Err()
+}
+
+// BookingGroupResponse is what the API returns for a multi-room booking.
+// swagger:model BookingGroupResponse
+type BookingGroupResponse struct {
+	ID          uint              `json:"id"`
+	Title       string            `json:"title"`
+	EmployeeID  uint              `json:"employee_id"`
+	CreatedByID uint              `json:"created_by_id"`
+	StartTime   time.Time         `json:"start_time"`
+	EndTime     time.Time         `json:"end_time"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+	Bookings    []BookingResponse `json:"bookings"`
+}
+
+func NewBookingGroupResponse(g BookingGroup) BookingGroupResponse {
+	bookings := NewBookingResponses(g.Bookings)
+	if bookings == nil {
+		bookings = []BookingResponse{}
+	}
+	return BookingGroupResponse{
+		ID:          g.ID,
+		Title:       g.Title,
+		EmployeeID:  g.EmployeeID,
+		CreatedByID: g.CreatedByID,
+		StartTime:   g.StartTime,
+		EndTime:     g.EndTime,
+		CreatedAt:   g.CreatedAt,
+		UpdatedAt:   g.UpdatedAt,
+		Bookings:    bookings,
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookingGroupValidation(t *testing.T) {
+	start := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		rooms  []BookingGroupRoomDTO
+		end    time.Time
+		fields []string
+	}{
+		{name: "Two rooms", rooms: []BookingGroupRoomDTO{{RoomID: 1, NumAttendees: 4}, {RoomID: 2}}, end: start.Add(time.Hour)},
+		{name: "No rooms", rooms: nil, end: start.Add(time.Hour), fields: []string{"rooms"}},
+		{name: "Same room twice", rooms: []BookingGroupRoomDTO{{RoomID: 1}, {RoomID: 2}, {RoomID: 1}}, end: start.Add(time.Hour), fields: []string{"rooms[2].room_id"}},
+		{name: "Missing room", rooms: []BookingGroupRoomDTO{{RoomID: 0}, {RoomID: 0}}, end: start.Add(time.Hour), fields: []string{"rooms[0].room_id", "rooms[1].room_id"}},
+		{name: "Negative attendees", rooms: []BookingGroupRoomDTO{{RoomID: 1, NumAttendees: -1}}, end: start.Add(time.Hour), fields: []string{"rooms[0].num_attendees"}},
+		{name: "Ends before it starts", rooms: []BookingGroupRoomDTO{{RoomID: 1}}, end: start.Add(-time.Hour), fields: []string{"end_time"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BookingGroupDTO{StartTime: start, EndTime: tt.end, Rooms: tt.rooms}.Validate()
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var errs validation.Errors
+			assert.ErrorAs(t, err, &errs)
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
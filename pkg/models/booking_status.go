@@ -0,0 +1,128 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+	"gorm.io/gorm"
+)
+
+type BookingStatus string
+
+const (
+	BookingTentative       BookingStatus = "tentative"
+	BookingPendingApproval BookingStatus = "pending_approval"
+	BookingConfirmed       BookingStatus = "confirmed"
+	BookingCheckedIn       BookingStatus = "checked_in"
+	BookingCompleted       BookingStatus = "completed"
+	BookingCancelled       BookingStatus = "cancelled"
+	BookingNoShow          BookingStatus = "no_show"
+	BookingReleased        BookingStatus = "released"
+	BookingRejected        BookingStatus = "rejected"
+)
+
+// bookingTransitions lists, for every status, the statuses it may move to.
+// Statuses without an entry are terminal.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingTentative:       {BookingPendingApproval, BookingConfirmed, BookingReleased, BookingCancelled},
+	BookingPendingApproval: {BookingConfirmed, BookingRejected, BookingCancelled},
+	BookingConfirmed:       {BookingPendingApproval, BookingCheckedIn, BookingCompleted, BookingCancelled, BookingNoShow, BookingReleased},
+	BookingCheckedIn:       {BookingCompleted, BookingReleased},
+}
+
+// ActiveBookingStatuses are the statuses in which a booking holds its slot.
+var ActiveBookingStatuses = []BookingStatus{BookingTentative, BookingPendingApproval, BookingConfirmed, BookingCheckedIn}
+
+func (s BookingStatus) IsValid() bool {
+	switch s {
+	case BookingTentative, BookingPendingApproval, BookingConfirmed, BookingCheckedIn,
+		BookingCompleted, BookingCancelled, BookingNoShow, BookingReleased, BookingRejected:
+		return true
+	}
+	return false
+}
+
+func (s BookingStatus) IsActive() bool {
+	for _, active := range ActiveBookingStatuses {
+		if s == active {
+			return true
+		}
+	}
+	return false
+}
+
+type InvalidTransitionError struct {
+	From BookingStatus
+	To   BookingStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot move booking from %s to %s", e.From, e.To)
+}
+
+// ValidateTransition reports whether a booking may move from one status to
+// another.
+func ValidateTransition(from, to BookingStatus) error {
+	if !to.IsValid() {
+		return fmt.Errorf("unknown booking status %q", to)
+	}
+	for _, allowed := range bookingTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &InvalidTransitionError{From: from, To: to}
+}
+
+// BookingTransition records a single status change. ActorID is nil when the
+// change was made by the system, e.g. an expiry job.
+type BookingTransition struct {
+	gorm.Model
+	BookingID  uint          `json:"booking_id" gorm:"index"`
+	FromStatus BookingStatus `json:"from_status" gorm:"size:32"`
+	ToStatus   BookingStatus `json:"to_status" gorm:"size:32"`
+	Reason     string        `json:"reason"`
+	ActorID    *uint         `json:"actor_id"`
+}
+
+// BookingTransitionDTO represents a status change request for Swagger
+// swagger:model BookingTransition
+type BookingTransitionDTO struct {
+	Status BookingStatus `json:"status"`
+	Reason string        `json:"reason"`
+}
+
+func (d BookingTransitionDTO) Validate() error {
+	var v validation.Validator
+	v.Required("status", string(d.Status))
+	v.Check(d.Status == "" || d.Status.IsValid(), "status", validation.CodeInvalid,
+		fmt.Sprintf("unknown status %q", d.Status))
+	return v.Err()
+}
+
+// BookingHistoryResponse is what the API returns for a status history entry.
+// swagger:model BookingHistoryResponse
+type BookingHistoryResponse struct {
+	ID         uint          `json:"id"`
+	FromStatus BookingStatus `json:"from_status"`
+	ToStatus   BookingStatus `json:"to_status"`
+	Reason     string        `json:"reason"`
+	ActorID    *uint         `json:"actor_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+func NewBookingHistoryResponses(transitions []BookingTransition) []BookingHistoryResponse {
+	responses := make([]BookingHistoryResponse, len(transitions))
+	for i, t := range transitions {
+		responses[i] = BookingHistoryResponse{
+			ID:         t.ID,
+			FromStatus: t.FromStatus,
+			ToStatus:   t.ToStatus,
+			Reason:     t.Reason,
+			ActorID:    t.ActorID,
+			CreatedAt:  t.CreatedAt,
+		}
+	}
+	return responses
+}
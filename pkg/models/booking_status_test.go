@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    BookingStatus
+		to      BookingStatus
+		allowed bool
+	}{
+		{name: "Approve pending booking", from: BookingPendingApproval, to: BookingConfirmed, allowed: true},
+		{name: "Reject pending booking", from: BookingPendingApproval, to: BookingRejected, allowed: true},
+		{name: "Check in confirmed booking", from: BookingConfirmed, to: BookingCheckedIn, allowed: true},
+		{name: "Complete checked in booking", from: BookingCheckedIn, to: BookingCompleted, allowed: true},
+		{name: "Release expired hold", from: BookingTentative, to: BookingReleased, allowed: true},
+		{name: "Check in pending booking", from: BookingPendingApproval, to: BookingCheckedIn, allowed: false},
+		{name: "Cancel checked in booking", from: BookingCheckedIn, to: BookingCancelled, allowed: false},
+		{name: "Reopen cancelled booking", from: BookingCancelled, to: BookingConfirmed, allowed: false},
+		{name: "Unknown status", from: BookingConfirmed, to: BookingStatus("archived"), allowed: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateTransition(test.from, test.to)
+			if test.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestBookingStatusIsActive(t *testing.T) {
+	assert.True(t, BookingTentative.IsActive())
+	assert.True(t, BookingCheckedIn.IsActive())
+	assert.False(t, BookingCancelled.IsActive())
+	assert.False(t, BookingRejected.IsActive())
+}
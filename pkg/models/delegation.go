@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+	"gorm.io/gorm"
+)
+
+type DelegateAction string
+
+const (
+	DelegateBook   DelegateAction = "book"
+	DelegateEdit   DelegateAction = "edit"
+	DelegateCancel DelegateAction = "cancel"
+)
+
+// Delegation lets the delegate book, edit and/or cancel bookings on behalf
+// of the principal, e.g. an assistant acting for their manager.
+type Delegation struct {
+	gorm.Model
+	PrincipalID uint `json:"principal_id" gorm:"uniqueIndex:idx_delegation_pair"`
+	DelegateID  uint `json:"delegate_id" gorm:"uniqueIndex:idx_delegation_pair"`
+	CanBook     bool `json:"can_book"`
+	CanEdit     bool `json:"can_edit"`
+	CanCancel   bool `json:"can_cancel"`
+
+	Principal Employee `json:"-"`
+	Delegate  Employee `json:"-"`
+}
+
+func (d Delegation) Allows(action DelegateAction) bool {
+	switch action {
+	case DelegateBook:
+		return d.CanBook
+	case DelegateEdit:
+		return d.CanEdit
+	case DelegateCancel:
+		return d.CanCancel
+	}
+	return false
+}
+
+// DelegationDTO represents a delegation grant for Swagger
+// swagger:model Delegation
+type DelegationDTO struct {
+	DelegateID uint `json:"delegate_id"`
+	CanBook    bool `json:"can_book"`
+	CanEdit    bool `json:"can_edit"`
+	CanCancel  bool `json:"can_cancel"`
+}
+
+func (d DelegationDTO) Validate() error {
+	var v validation.Validator
+	v.RequiredID("delegate_id", d.DelegateID)
+	v.Check(d.CanBook || d.CanEdit || d.CanCancel, "can_book", validation.CodeRequired,
+		"at least one of can_book, can_edit and can_cancel must be granted")
+	return v.Err()
+}
+
+// DelegationResponse is what the API returns for a delegation.
+// swagger:model DelegationResponse
+type DelegationResponse struct {
+	ID          uint      `json:"id"`
+	PrincipalID uint      `json:"principal_id"`
+	DelegateID  uint      `json:"delegate_id"`
+	CanBook     bool      `json:"can_book"`
+	CanEdit     bool      `json:"can_edit"`
+	CanCancel   bool      `json:"can_cancel"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewDelegationResponse(d Delegation) DelegationResponse {
+	return DelegationResponse{
+		ID:          d.ID,
+		PrincipalID: d.PrincipalID,
+		DelegateID:  d.DelegateID,
+		CanBook:     d.CanBook,
+		CanEdit:     d.CanEdit,
+		CanCancel:   d.CanCancel,
+		CreatedAt:   d.CreatedAt,
+		UpdatedAt:   d.UpdatedAt,
+	}
+}
+
+func NewDelegationResponses(delegations []Delegation) []DelegationResponse {
+	responses := make([]DelegationResponse, len(delegations))
+	for i, d := range delegations {
+		responses[i] = NewDelegationResponse(d)
+	}
+	return responses
+}
+
+// DelegationsResponse lists the delegations granted by and to an employee.
+// swagger:model DelegationsResponse
+type DelegationsResponse struct {
+	Granted  []DelegationResponse `json:"granted"`
+	Received []DelegationResponse `json:"received"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+type EmailStatus string
+
+const (
+	EmailPending EmailStatus = "pending"
+	EmailSending EmailStatus = "sending"
+	EmailSent    EmailStatus = "sent"
+	EmailFailed  EmailStatus = "failed"
+)
+
+// OutboxEmail is an email waiting to be sent, or the record of one that was.
+// Emails are written to the outbox first so none is lost when the server
+// stops or the mail server is unreachable.
+type OutboxEmail struct {
+	ID        uint        `gorm:"primaryKey"`
+	To        string      `gorm:"column:recipient;size:255;not null"`
+	Subject   string      `gorm:"size:255;not null"`
+	Body      string      `gorm:"type:text;not null"`
+	Status    EmailStatus `gorm:"size:16;index;not null"`
+	Attempts  int
+	LastError string `gorm:"type:text"`
+	// RequestID is the ID of the request or job run that sent the email.
+	RequestID string `gorm:"size:64;index"`
+	// NextAttemptAt is when a pending email is due to be (re)tried.
+	NextAttemptAt time.Time `gorm:"index"`
+	// ClaimedAt is when a dispatcher started sending the email.
+	ClaimedAt *time.Time
+	SentAt    *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (OutboxEmail) TableName() string {
+	return "email_outbox"
+}
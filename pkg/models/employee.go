@@ -1,21 +1,130 @@
 package models
 
 import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
 	"gorm.io/gorm"
 )
 
+type EmployeeRole string
+
+const (
+	RoleEmployee EmployeeRole = "employee"
+	RoleAdmin    EmployeeRole = "admin"
+)
+
 type Employee struct {
 	gorm.Model
-	Name     string    `json:"name"`
-	Email    string    `json:"email"`
-	Password string    `json:"password"`
-	Bookings []Booking `json:"bookings,omitempty"`
+	Name      string       `json:"name"`
+	Email     string       `json:"email"`
+	Password  string       `json:"-"`
+	HomeFloor *int         `json:"home_floor"`
+	Role      EmployeeRole `gorm:"size:16;default:employee" json:"role"`
+	// DeactivatedAt is set when the employee is offboarded. Deactivated
+	// employees cannot log in or organize bookings.
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	Bookings      []Booking  `json:"bookings,omitempty"`
+}
+
+func (e Employee) IsActive() bool {
+	return e.DeactivatedAt == nil
+}
+
+func (e Employee) IsAdmin() bool {
+	return e.Role == RoleAdmin
 }
 
 // EmployeeDTO represents an employee for Swagger
 // swagger:model Employee
 type EmployeeDTO struct {
-	Name     string `json:"name"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	HomeFloor *int   `json:"home_floor"`
+}
+
+// MinPasswordLength is the shortest password accepted for new passwords.
+const MinPasswordLength = 8
+
+func (d EmployeeDTO) Validate() error {
+	var v validation.Validator
+	v.Required("name", d.Name)
+	v.Required("email", d.Email)
+	v.Email("email", d.Email)
+	v.MinLength("password", d.Password, MinPasswordLength)
+	return v.Err()
+}
+
+// CredentialsDTO represents login credentials for Swagger
+// swagger:model Credentials
+type CredentialsDTO struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
-}
\ No newline at end of file
+}
+
+func (d CredentialsDTO) Validate() error {
+	var v validation.Validator
+	v.Required("email", d.Email)
+	v.Required("password", d.Password)
+	return v.Err()
+}
+
+// EmployeeSummary identifies an employee inside other responses.
+// swagger:model EmployeeSummary
+type EmployeeSummary struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func NewEmployeeSummary(e Employee) EmployeeSummary {
+	return EmployeeSummary{ID: e.ID, Name: e.Name, Email: e.Email}
+}
+
+func NewEmployeeSummaries(employees []Employee) []EmployeeSummary {
+	summaries := make([]EmployeeSummary, len(employees))
+	for i, e := range employees {
+		summaries[i] = NewEmployeeSummary(e)
+	}
+	return summaries
+}
+
+// EmployeeResponse is what the API returns for an employee; the password hash
+// is never included.
+// swagger:model EmployeeResponse
+type EmployeeResponse struct {
+	ID            uint              `json:"id"`
+	Name          string            `json:"name"`
+	Email         string            `json:"email"`
+	HomeFloor     *int              `json:"home_floor"`
+	Role          EmployeeRole      `json:"role"`
+	Active        bool              `json:"active"`
+	DeactivatedAt *time.Time        `json:"deactivated_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	Bookings      []BookingResponse `json:"bookings,omitempty"`
+}
+
+func NewEmployeeResponse(e Employee) EmployeeResponse {
+	return EmployeeResponse{
+		ID:            e.ID,
+		Name:          e.Name,
+		Email:         e.Email,
+		HomeFloor:     e.HomeFloor,
+		Role:          e.Role,
+		Active:        e.IsActive(),
+		DeactivatedAt: e.DeactivatedAt,
+		CreatedAt:     e.CreatedAt,
+		UpdatedAt:     e.UpdatedAt,
+		Bookings:      NewBookingResponses(e.Bookings),
+	}
+}
+
+func NewEmployeeResponses(employees []Employee) []EmployeeResponse {
+	responses := make([]EmployeeResponse, len(employees))
+	for i, e := range employees {
+		responses[i] = NewEmployeeResponse(e)
+	}
+	return responses
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmployeeResponseHidesInternalFields(t *testing.T) {
+	employee := Employee{Name: "Ada", Email: "ada@example.com", Password: "$2a$10$hash"}
+	employee.ID = 7
+	employee.Bookings = []Booking{{
+		RoomID:     3,
+		EmployeeID: 7,
+		Status:     BookingConfirmed,
+		CalendarID: "event-1",
+		Room:       Room{Name: "Focus"},
+		Employee:   employee,
+	}}
+	employee.Bookings[0].Room.ID = 3
+
+	data, err := json.Marshal(NewEmployeeResponse(employee))
+	assert.NoError(t, err)
+	body := string(data)
+	for _, leaked := range []string{"password", "$2a$10$hash", "ReminderSent", "CalendarID", "event-1", "DeletedAt", "deleted_at"} {
+		assert.NotContains(t, body, leaked)
+	}
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "ada@example.com", decoded["email"])
+	assert.Equal(t, true, decoded["active"])
+	booking := decoded["bookings"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"id": 3.0, "name": "Focus", "location": "", "floor": 0.0}, booking["room"])
+	assert.Equal(t, "Ada", booking["employee"].(map[string]interface{})["name"])
+
+	// Even marshalled directly, the model does not expose the hash.
+	data, _ = json.Marshal(employee)
+	assert.NotContains(t, string(data), "$2a$10$hash")
+}
@@ -13,4 +13,10 @@ type GoogleToken struct {
 	AccessToken  string    `gorm:"type:text;not null"`
 	RefreshToken string    `gorm:"type:text;not null"`
 	Expiry       time.Time `gorm:"not null"`
-}
\ No newline at end of file
+}
+
+// BeforeSave stores the expiry in UTC, as for bookings.
+func (t *GoogleToken) BeforeSave(tx *gorm.DB) error {
+	t.Expiry = t.Expiry.UTC()
+	return nil
+}
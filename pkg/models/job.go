@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+type JobStatus string
+
+const (
+	JobIdle      JobStatus = "idle"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is the persisted state of a background job: whether it is paused, how
+// its last run went and when it runs next. Runs, Failures and
+// TotalDurationMs accumulate over every run.
+type Job struct {
+	Name     string    `gorm:"primaryKey;size:64"`
+	Schedule string    `gorm:"size:64;not null"`
+	Paused   bool      `gorm:"not null;default:false"`
+	Status   JobStatus `gorm:"size:16;not null;default:idle"`
+	// RunBy names the instance running the job while Status is running.
+	RunBy string `gorm:"size:255"`
+
+	LastStartedAt  *time.Time
+	LastFinishedAt *time.Time
+	LastDurationMs int64
+	// LastProcessed is how many items, e.g. reminders or bookings, the last
+	// run handled.
+	LastProcessed int
+	LastError     string `gorm:"type:text"`
+	NextRunAt     *time.Time
+
+	Runs            int64 `gorm:"not null;default:0"`
+	Failures        int64 `gorm:"not null;default:0"`
+	TotalDurationMs int64 `gorm:"not null;default:0"`
+	UpdatedAt       time.Time
+}
+
+// JobRun is the outcome of one run of a job.
+type JobRun struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Processed  int
+	Err        error
+	NextRunAt  *time.Time
+}
+
+// JobResponse is what the API returns for a background job.
+// swagger:model JobResponse
+type JobResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Schedule    string    `json:"schedule"`
+	Paused      bool      `json:"paused"`
+	Status      JobStatus `json:"status"`
+	RunBy       string    `json:"run_by,omitempty"`
+
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastProcessed  int        `json:"last_processed"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty"`
+
+	Runs              int64 `json:"runs"`
+	Failures          int64 `json:"failures"`
+	AverageDurationMs int64 `json:"average_duration_ms"`
+}
+
+func NewJobResponse(j Job, description string) JobResponse {
+	resp := JobResponse{
+		Name:           j.Name,
+		Description:    description,
+		Schedule:       j.Schedule,
+		Paused:         j.Paused,
+		Status:         j.Status,
+		RunBy:          j.RunBy,
+		LastStartedAt:  j.LastStartedAt,
+		LastFinishedAt: j.LastFinishedAt,
+		LastDurationMs: j.LastDurationMs,
+		LastProcessed:  j.LastProcessed,
+		LastError:      j.LastError,
+		NextRunAt:      j.NextRunAt,
+		Runs:           j.Runs,
+		Failures:       j.Failures,
+	}
+	if j.Runs > 0 {
+		resp.AverageDurationMs = j.TotalDurationMs / j.Runs
+	}
+	return resp
+}
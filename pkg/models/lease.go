@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Lease grants one holder, e.g. a server instance, exclusive use of a named
+// resource until ExpiresAt. Holders renew it before it expires; once it has
+// expired anyone may take it over.
+type Lease struct {
+	Name      string    `gorm:"primaryKey;size:64"`
+	Holder    string    `gorm:"size:255;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time
+}
@@ -0,0 +1,52 @@
+package models
+
+import "github.com/koushikidey/go-meetingroombook/pkg/validation"
+
+// OffboardingMode says what happens to a deactivated employee's future bookings.
+type OffboardingMode string
+
+const (
+	OffboardTransfer OffboardingMode = "transfer"
+	OffboardCancel   OffboardingMode = "cancel"
+)
+
+// BookingTransferDTO represents a change of organizer for Swagger
+// swagger:model BookingTransfer
+type BookingTransferDTO struct {
+	NewOrganizerID uint   `json:"new_organizer_id"`
+	Reason         string `json:"reason"`
+}
+
+// OffboardingDTO represents an employee deactivation request for Swagger
+// swagger:model Offboarding
+type OffboardingDTO struct {
+	// Mode is "transfer" or "cancel".
+	Mode OffboardingMode `json:"mode"`
+	// NewOrganizerID receives the future bookings when Mode is "transfer".
+	NewOrganizerID uint   `json:"new_organizer_id"`
+	Reason         string `json:"reason"`
+}
+
+// TransferResultDTO reports how many future bookings were handed over or cancelled
+// swagger:model TransferResult
+type TransferResultDTO struct {
+	Bookings      int  `json:"bookings"`
+	BookingGroups int  `json:"booking_groups"`
+	Cancelled     bool `json:"cancelled"`
+}
+
+func (d BookingTransferDTO) Validate() error {
+	var v validation.Validator
+	v.RequiredID("new_organizer_id", d.NewOrganizerID)
+	return v.Err()
+}
+
+func (d OffboardingDTO) Validate() error {
+	var v validation.Validator
+	v.Check(d.Mode == OffboardTransfer || d.Mode == OffboardCancel, "mode", validation.CodeInvalid,
+		`must be "transfer" or "cancel"`)
+	if d.Mode == OffboardTransfer {
+		v.RequiredID("new_organizer_id", d.NewOrganizerID)
+	}
+	return v.Err()
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+	"gorm.io/gorm"
+)
+
+type ReminderChannel string
+
+const (
+	// ReminderEmail is an email sent by the reminder job.
+	ReminderEmail ReminderChannel = "email"
+	// ReminderCalendar is a popup set on the Google Calendar event, for
+	// employees who linked their calendar.
+	ReminderCalendar ReminderChannel = "calendar"
+)
+
+var ReminderChannels = []ReminderChannel{ReminderEmail, ReminderCalendar}
+
+const (
+	// MaxReminderStages is as many reminders as Google Calendar accepts per
+	// event.
+	MaxReminderStages = 5
+	// MaxMinutesBefore is the earliest a reminder can be sent: one week.
+	MaxMinutesBefore = 7 * 24 * 60
+)
+
+// ReminderStage is one reminder, sent MinutesBefore a booking starts over
+// each of its channels.
+type ReminderStage struct {
+	MinutesBefore int               `json:"minutes_before" example:"60"`
+	Channels      []ReminderChannel `json:"channels"`
+}
+
+// Has reports whether the stage is sent over the channel.
+func (s ReminderStage) Has(channel ReminderChannel) bool {
+	for _, c := range s.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultReminderStages apply to employees who never chose their own: one
+// email ten minutes before the booking starts.
+var DefaultReminderStages = []ReminderStage{{MinutesBefore: 10, Channels: []ReminderChannel{ReminderEmail}}}
+
+// ReminderSettings are the reminders an employee wants for the bookings they
+// organize. Saving no stages turns reminders off.
+type ReminderSettings struct {
+	EmployeeID uint            `gorm:"primaryKey;autoIncrement:false"`
+	Stages     []ReminderStage `gorm:"type:text;serializer:json"`
+	UpdatedAt  time.Time
+}
+
+// StagesFor returns the stages sent over the channel, soonest before the
+// start first.
+func (s ReminderSettings) StagesFor(channel ReminderChannel) []int {
+	var minutes []int
+	for _, stage := range s.Stages {
+		if stage.Has(channel) {
+			minutes = append(minutes, stage.MinutesBefore)
+		}
+	}
+	sort.Ints(minutes)
+	return minutes
+}
+
+// Reminder records that a reminder was sent for a booking, or once for all
+// rooms of a booking group, in which case BookingID is 0. Zero rather than
+// NULL keeps the unique index effective.
+type Reminder struct {
+	ID            uint            `gorm:"primaryKey"`
+	BookingID     uint            `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	GroupID       uint            `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	EmployeeID    uint            `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	Channel       ReminderChannel `gorm:"size:16;not null;uniqueIndex:idx_reminder_once"`
+	MinutesBefore int             `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	// StartTime is when the booking started when the reminder was sent. A
+	// booking moved afterwards is reminded again for its new time.
+	StartTime time.Time `gorm:"not null;uniqueIndex:idx_reminder_once"`
+	SentAt    time.Time
+}
+
+// BeforeSave stores times in UTC, as for bookings.
+func (r *Reminder) BeforeSave(tx *gorm.DB) error {
+	r.StartTime = r.StartTime.UTC()
+	r.SentAt = r.SentAt.UTC()
+	return nil
+}
+
+// ReminderSettingsDTO represents reminder preferences for Swagger
+// swagger:model ReminderSettings
+type ReminderSettingsDTO struct {
+	// Stages replaces the current stages; an empty list turns reminders off.
+	Stages []ReminderStage `json:"stages"`
+}
+
+func (d ReminderSettingsDTO) Validate() error {
+	var v validation.Validator
+	v.Check(d.Stages != nil, "stages", validation.CodeRequired, "stages is required; send an empty list to turn reminders off")
+	v.Check(len(d.Stages) <= MaxReminderStages, "stages", validation.CodeInvalid,
+		fmt.Sprintf("at most %d reminders are allowed", MaxReminderStages))
+	seen := map[int]bool{}
+	for i, stage := range d.Stages {
+		field := fmt.Sprintf("stages[%d]", i)
+		v.Range(field+".minutes_before", stage.MinutesBefore, 1, MaxMinutesBefore)
+		v.Check(!seen[stage.MinutesBefore], field+".minutes_before", validation.CodeInvalid,
+			"each reminder needs a different time")
+		seen[stage.MinutesBefore] = true
+
+		v.Check(len(stage.Channels) > 0, field+".channels", validation.CodeRequired, "choose at least one channel")
+		channels := map[ReminderChannel]bool{}
+		for _, channel := range stage.Channels {
+			v.Check(validReminderChannel(channel), field+".channels", validation.CodeInvalid,
+				fmt.Sprintf("unknown channel %q; use email or calendar", channel))
+			v.Check(!channels[channel], field+".channels", validation.CodeInvalid,
+				fmt.Sprintf("channel %q is listed twice", channel))
+			channels[channel] = true
+		}
+	}
+	return v.Err()
+}
+
+func validReminderChannel(channel ReminderChannel) bool {
+	for _, c := range ReminderChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// ReminderSettingsResponse is what the API returns for reminder preferences.
+// swagger:model ReminderSettingsResponse
+type ReminderSettingsResponse struct {
+	Stages []ReminderStage `json:"stages"`
+	// Default is true while the employee has not chosen their own stages.
+	Default   bool       `json:"default"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+func NewReminderSettingsResponse(s ReminderSettings) ReminderSettingsResponse {
+	resp := ReminderSettingsResponse{Stages: s.Stages, Default: s.UpdatedAt.IsZero()}
+	if resp.Stages == nil {
+		resp.Stages = []ReminderStage{}
+	}
+	if !resp.Default {
+		resp.UpdatedAt = &s.UpdatedAt
+	}
+	return resp
+}
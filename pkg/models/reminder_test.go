@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReminderSettingsValidation(t *testing.T) {
+	email := []ReminderChannel{ReminderEmail}
+	tests := []struct {
+		name   string
+		stages []ReminderStage
+		fields []string
+	}{
+		{"several stages", []ReminderStage{{1440, email}, {60, []ReminderChannel{ReminderEmail, ReminderCalendar}}}, nil},
+		{"turned off", []ReminderStage{}, nil},
+		{"missing", nil, []string{"stages"}},
+		{"too early", []ReminderStage{{MaxMinutesBefore + 1, email}}, []string{"stages[0].minutes_before"}},
+		{"same time twice", []ReminderStage{{10, email}, {10, []ReminderChannel{ReminderCalendar}}}, []string{"stages[1].minutes_before"}},
+		{"no channel", []ReminderStage{{10, nil}}, []string{"stages[0].channels"}},
+		{"unknown channel", []ReminderStage{{10, []ReminderChannel{"sms"}}}, []string{"stages[0].channels"}},
+		{"too many", []ReminderStage{{1, email}, {2, email}, {3, email}, {4, email}, {5, email}, {6, email}}, []string{"stages"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ReminderSettingsDTO{Stages: tt.stages}.Validate()
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var errs validation.Errors
+			assert.ErrorAs(t, err, &errs)
+			var fields []string
+			for _, e := range errs {
+				fields = append(fields, e.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestStagesForSortsByMinutes(t *testing.T) {
+	settings := ReminderSettings{Stages: []ReminderStage{
+		{60, []ReminderChannel{ReminderEmail}},
+		{1440, []ReminderChannel{ReminderCalendar}},
+		{10, []ReminderChannel{ReminderCalendar, ReminderEmail}},
+	}}
+	assert.Equal(t, []int{10, 60}, settings.StagesFor(ReminderEmail))
+	assert.Equal(t, []int{10, 1440}, settings.StagesFor(ReminderCalendar))
+}
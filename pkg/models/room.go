@@ -1,21 +1,168 @@
 package models
 
 import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
 	"gorm.io/gorm"
 )
 
 type Room struct {
 	gorm.Model
-	Name     string    `json:"name"`
-	Capacity *int      `json:"capacity"`
-	Location string    `json:"location"`
-	Bookings []Booking `json:"bookings,omitempty"`
+	Name             string         `json:"name"`
+	Capacity         *int           `json:"capacity"`
+	Location         string         `json:"location"`
+	Floor            int            `json:"floor"`
+	Amenities        []string       `json:"amenities" gorm:"serializer:json"`
+	RequiresApproval bool           `json:"requires_approval"`
+	ApproverGroupID  *uint          `json:"approver_group_id"`
+	ApproverGroup    *ApproverGroup `json:"approver_group,omitempty"`
+	Bookings         []Booking      `json:"bookings,omitempty"`
 }
 
 // RoomDTO represents a Room for Swagger
 // swagger:model Room
 type RoomDTO struct {
+	Name             string   `json:"name"`
+	Capacity         *int     `json:"capacity"`
+	Location         string   `json:"location"`
+	Floor            int      `json:"floor"`
+	Amenities        []string `json:"amenities"`
+	RequiresApproval bool     `json:"requires_approval"`
+	ApproverGroupID  *uint    `json:"approver_group_id"`
+}
+
+func (d RoomDTO) Validate() error {
+	var v validation.Validator
+	v.Required("name", d.Name)
+	v.Check(d.Capacity != nil, "capacity", validation.CodeRequired, "is required")
+	if d.Capacity != nil {
+		v.Positive("capacity", *d.Capacity)
+	}
+	validateAmenities(&v, d.Amenities)
+	v.Check(!d.RequiresApproval || d.ApproverGroupID != nil, "approver_group_id", validation.CodeRequired,
+		"is required for rooms that require approval")
+	return v.Err()
+}
+
+// RoomUpdateDTO represents changes to a room for Swagger; omitted fields are
+// left unchanged
+// swagger:model RoomUpdate
+type RoomUpdateDTO struct {
+	Name             *string   `json:"name"`
+	Capacity         *int      `json:"capacity"`
+	Location         *string   `json:"location"`
+	Floor            *int      `json:"floor"`
+	Amenities        *[]string `json:"amenities"`
+	RequiresApproval *bool     `json:"requires_approval"`
+	ApproverGroupID  *uint     `json:"approver_group_id"`
+}
+
+func (d RoomUpdateDTO) Validate() error {
+	var v validation.Validator
+	if d.Name != nil {
+		v.Required("name", *d.Name)
+	}
+	if d.Capacity != nil {
+		v.Positive("capacity", *d.Capacity)
+	}
+	if d.Amenities != nil {
+		validateAmenities(&v, *d.Amenities)
+	}
+	return v.Err()
+}
+
+func validateAmenities(v *validation.Validator, amenities []string) {
+	for _, a := range amenities {
+		if a == "" {
+			v.Add("amenities", validation.CodeInvalid, "must not contain empty names")
+			return
+		}
+	}
+}
+
+// RoomSuggestionDTO represents a room suggestion request for Swagger
+// swagger:model RoomSuggestionRequest
+type RoomSuggestionDTO struct {
+	AttendeeIDs []uint `json:"attendee_ids"`
+	// NumAttendees overrides the head count derived from attendee_ids.
+	NumAttendees      int       `json:"num_attendees"`
+	DurationMinutes   int       `json:"duration_minutes"`
+	WindowStart       time.Time `json:"window_start"`
+	WindowEnd         time.Time `json:"window_end"`
+	Amenities         []string  `json:"amenities"`
+	UseGoogleCalendar bool      `json:"use_google_calendar"`
+	Limit             int       `json:"limit"`
+}
+
+// MaxSuggestionWindow is the longest window rooms can be suggested for.
+const MaxSuggestionWindow = 14 * 24 * time.Hour
+
+func (d RoomSuggestionDTO) Validate() error {
+	var v validation.Validator
+	v.Positive("duration_minutes", d.DurationMinutes)
+	v.RequiredTime("window_start", d.WindowStart)
+	v.RequiredTime("window_end", d.WindowEnd)
+	v.After("window_end", d.WindowEnd, "window_start", d.WindowStart)
+	window := d.WindowEnd.Sub(d.WindowStart)
+	v.Check(window <= MaxSuggestionWindow, "window_end", validation.CodeOutOfRange, "the window cannot be longer than 14 days")
+	v.Check(window <= 0 || time.Duration(d.DurationMinutes)*time.Minute <= window, "duration_minutes",
+		validation.CodeOutOfRange, "the meeting does not fit in the window")
+	v.NonNegative("num_attendees", d.NumAttendees)
+	v.Range("limit", d.Limit, 0, 100)
+	v.IDs("attendee_ids", d.AttendeeIDs)
+	return v.Err()
+}
+
+// RoomSummary identifies a room inside other responses.
+// swagger:model RoomSummary
+type RoomSummary struct {
+	ID       uint   `json:"id"`
 	Name     string `json:"name"`
-	Capacity *int   `json:"capacity"`
 	Location string `json:"location"`
-}
\ No newline at end of file
+	Floor    int    `json:"floor"`
+}
+
+// RoomResponse is what the API returns for a room.
+// swagger:model RoomResponse
+type RoomResponse struct {
+	ID               uint              `json:"id"`
+	Name             string            `json:"name"`
+	Capacity         *int              `json:"capacity"`
+	Location         string            `json:"location"`
+	Floor            int               `json:"floor"`
+	Amenities        []string          `json:"amenities"`
+	RequiresApproval bool              `json:"requires_approval"`
+	ApproverGroupID  *uint             `json:"approver_group_id"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	Bookings         []BookingResponse `json:"bookings,omitempty"`
+}
+
+func NewRoomResponse(r Room) RoomResponse {
+	amenities := r.Amenities
+	if amenities == nil {
+		amenities = []string{}
+	}
+	return RoomResponse{
+		ID:               r.ID,
+		Name:             r.Name,
+		Capacity:         r.Capacity,
+		Location:         r.Location,
+		Floor:            r.Floor,
+		Amenities:        amenities,
+		RequiresApproval: r.RequiresApproval,
+		ApproverGroupID:  r.ApproverGroupID,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+		Bookings:         NewBookingResponses(r.Bookings),
+	}
+}
+
+func NewRoomResponses(rooms []Room) []RoomResponse {
+	responses := make([]RoomResponse, len(rooms))
+	for i, r := range rooms {
+		responses[i] = NewRoomResponse(r)
+	}
+	return responses
+}
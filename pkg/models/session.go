@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Session is a sign-in of an employee on one device. The cookie carries a
+// random token; the ID is the SHA-256 of the token, so the stored sessions
+// cannot be used to sign in.
+type Session struct {
+	ID         string `gorm:"primaryKey;size:64"`
+	EmployeeID uint   `gorm:"index"`
+	// Data holds the encoded session values.
+	Data       []byte
+	UserAgent  string `gorm:"size:255"`
+	IPAddress  string `gorm:"size:64"`
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// ExpiresAt is the earlier of the idle and the absolute timeout.
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	Current    bool      `json:"current"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewSessionResponse describes the session; current marks the one the
+// request was made with.
+func NewSessionResponse(s Session, current bool) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		Current:    current,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
+}
+
+// MeResponse tells a client who is signed in with the session and what they
+// may do.
+// swagger:model MeResponse
+type MeResponse struct {
+	EmployeeID uint             `json:"employee_id"`
+	Employee   EmployeeResponse `json:"employee"`
+	// Roles are "employee", "admin" for administrators and "approver" for
+	// members of an approver group.
+	Roles                []string  `json:"roles" example:"employee,approver"`
+	GoogleCalendarLinked bool      `json:"google_calendar_linked"`
+	SessionExpiresAt     time.Time `json:"session_expires_at"`
+}
+
+func NewMeResponse(e Employee, approver, calendarLinked bool, s Session) MeResponse {
+	roles := []string{string(RoleEmployee)}
+	if e.IsAdmin() {
+		roles = append(roles, string(RoleAdmin))
+	}
+	if approver {
+		roles = append(roles, "approver")
+	}
+	return MeResponse{
+		EmployeeID:           e.ID,
+		Employee:             NewEmployeeResponse(e),
+		Roles:                roles,
+		GoogleCalendarLinked: calendarLinked,
+		SessionExpiresAt:     s.ExpiresAt,
+	}
+}
@@ -0,0 +1,181 @@
+// Package outbox delivers emails reliably. Handlers enqueue emails in the
+// database; a dispatcher sends them in the background, retries failures
+// with backoff and flushes whatever is left when the server shuts down.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/metrics"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// maxAttempts is how often an email is tried before it is marked failed.
+	maxAttempts = 5
+	// pollInterval is how often the dispatcher looks for due emails when
+	// nothing wakes it up earlier.
+	pollInterval = 10 * time.Second
+	// claimTimeout is how long an email may stay claimed before another
+	// dispatcher assumes the sender died and tries again.
+	claimTimeout = 10 * time.Minute
+	batchSize    = 50
+)
+
+// Sender delivers an email, e.g. utils.SMTPMailer.
+type Sender interface {
+	Send(to, subject, body string) error
+}
+
+// Outbox queues emails and dispatches them through a Sender.
+type Outbox struct {
+	repo   repository.OutboxRepository
+	sender Sender
+	wake   chan struct{}
+
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+func New(repo repository.OutboxRepository, sender Sender) *Outbox {
+	return &Outbox{repo: repo, sender: sender, wake: make(chan struct{}, 1)}
+}
+
+// Send queues the email, tagged with the request ID of ctx; it is delivered
+// by the dispatcher.
+func (o *Outbox) Send(ctx context.Context, to, subject, body string) error {
+	_, span := tracing.Start(ctx, "email.queue", attribute.String("email.subject", subject))
+	email := &models.OutboxEmail{To: to, Subject: subject, Body: body, RequestID: logging.RequestID(ctx)}
+	if err := o.repo.Enqueue(email); err != nil {
+		err = fmt.Errorf("failed to queue email: %w", err)
+		tracing.End(span, err)
+		return err
+	}
+	tracing.End(span, nil)
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Start dispatches queued emails in the background until Shutdown.
+func (o *Outbox) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	o.stopped = make(chan struct{})
+	go func() {
+		defer close(o.stopped)
+		o.run(ctx)
+	}()
+}
+
+// Shutdown stops the background dispatcher and then sends what is still
+// queued, giving up when ctx expires. Emails that could not be sent stay
+// queued for the next start.
+func (o *Outbox) Shutdown(ctx context.Context) error {
+	if o.cancel != nil {
+		o.cancel()
+		select {
+		case <-o.stopped:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	sent, err := o.Dispatch(ctx)
+	if sent > 0 {
+		slog.Info("Flushed queued emails", "count", sent)
+	}
+	return err
+}
+
+func (o *Outbox) run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := o.repo.ReleaseStale(time.Now().Add(-claimTimeout)); err != nil {
+			slog.Error("Failed to release stale outbox emails", "error", err)
+		}
+		if _, err := o.Dispatch(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Failed to dispatch emails", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends every email that is due and returns how many were sent. It
+// stops early if ctx is cancelled.
+func (o *Outbox) Dispatch(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		due, err := o.repo.Due(time.Now(), batchSize)
+		if err != nil {
+			return sent, err
+		}
+		if len(due) == 0 {
+			return sent, nil
+		}
+		for _, email := range due {
+			if ctx.Err() != nil {
+				return sent, ctx.Err()
+			}
+			ok, err := o.deliver(ctx, email)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+		if len(due) < batchSize {
+			return sent, nil
+		}
+	}
+}
+
+// deliver sends one email unless another dispatcher got to it first. The
+// sending gets a trace of its own, tagged with the ID of the request that
+// queued the email.
+func (o *Outbox) deliver(ctx context.Context, email models.OutboxEmail) (bool, error) {
+	claimed, err := o.repo.Claim(email.ID, time.Now())
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	_, span := tracing.Start(ctx, "email.send", attribute.Int("email.id", int(email.ID)),
+		attribute.String("email.request_id", email.RequestID), attribute.Int("email.attempt", email.Attempts+1))
+	sendErr := o.sender.Send(email.To, email.Subject, email.Body)
+	tracing.End(span, sendErr)
+	if sendErr != nil {
+		metrics.EmailsSent.WithLabelValues("failure").Inc()
+		attempts := email.Attempts + 1
+		var retryAt *time.Time
+		if attempts < maxAttempts {
+			next := time.Now().Add(backoff(attempts))
+			retryAt = &next
+		}
+		slog.Warn("Failed to send email", "request_id", email.RequestID, "email_id", email.ID,
+			"subject", email.Subject, "to", email.To, "attempt", attempts, "error", sendErr)
+		return false, o.repo.Retry(email.ID, attempts, sendErr.Error(), retryAt)
+	}
+	metrics.EmailsSent.WithLabelValues("success").Inc()
+	slog.Debug("Email sent", "request_id", email.RequestID, "email_id", email.ID, "subject", email.Subject, "to", email.To)
+	return true, o.repo.MarkSent(email.ID, time.Now())
+}
+
+// backoff is the wait before the next attempt: 1, 4, 9, 16 minutes.
+func backoff(attempts int) time.Duration {
+	return time.Duration(attempts*attempts) * time.Minute
+}
@@ -0,0 +1,144 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracing"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []string
+	fail error
+}
+
+func (s *recordingSender) Send(to, subject, body string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		return s.fail
+	}
+	s.sent = append(s.sent, to+": "+subject)
+	return nil
+}
+
+func newTestOutbox(t *testing.T, sender Sender) (*Outbox, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	return New(repository.NewOutboxRepository(db), sender), db
+}
+
+func TestShutdownFlushesQueuedEmails(t *testing.T) {
+	sender := &recordingSender{}
+	mail, db := newTestOutbox(t, sender)
+
+	assert.NoError(t, mail.Send(logging.WithRequestID(context.Background(), "req-1"), "ann@example.com", "Booked", "Room 1"))
+	assert.NoError(t, mail.Send(context.Background(), "bob@example.com", "Cancelled", "Room 2"))
+	assert.Empty(t, sender.sent, "Send only queues")
+	var queued models.OutboxEmail
+	db.Where("recipient = ?", "ann@example.com").First(&queued)
+	assert.Equal(t, "req-1", queued.RequestID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, mail.Shutdown(ctx))
+	assert.Equal(t, []string{"ann@example.com: Booked", "bob@example.com: Cancelled"}, sender.sent)
+
+	var pending int64
+	db.Model(&models.OutboxEmail{}).Where("status <> ?", models.EmailSent).Count(&pending)
+	assert.Zero(t, pending)
+}
+
+func TestSendingIsTraced(t *testing.T) {
+	spans := tracingtest.Record(t)
+	sender := &recordingSender{fail: errors.New("mailbox full")}
+	mail, _ := newTestOutbox(t, sender)
+
+	ctx, request := tracing.Start(logging.WithRequestID(context.Background(), "req-9"), "request")
+	assert.NoError(t, mail.Send(ctx, "ann@example.com", "Booked", "Room 1"))
+	request.End()
+	_, err := mail.Dispatch(context.Background())
+	assert.NoError(t, err)
+
+	ended := spans.GetSpans()
+	assert.Equal(t, []string{"email.queue", "request", "email.send"}, tracingtest.Names(ended))
+	queued, _ := tracingtest.Find(ended, "email.queue")
+	assert.Equal(t, request.SpanContext().SpanID(), queued.Parent.SpanID())
+
+	sent, _ := tracingtest.Find(ended, "email.send")
+	assert.Contains(t, sent.Attributes, attribute.String("email.request_id", "req-9"))
+	assert.Equal(t, codes.Error, sent.Status.Code)
+}
+
+func TestFailedEmailsAreRetriedThenGivenUp(t *testing.T) {
+	sender := &recordingSender{fail: errors.New("smtp down")}
+	mail, db := newTestOutbox(t, sender)
+	assert.NoError(t, mail.Send(context.Background(), "ann@example.com", "Booked", "Room 1"))
+
+	sent, err := mail.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+
+	var email models.OutboxEmail
+	db.First(&email)
+	assert.Equal(t, models.EmailPending, email.Status)
+	assert.Equal(t, 1, email.Attempts)
+	assert.Equal(t, "smtp down", email.LastError)
+	assert.True(t, email.NextAttemptAt.After(time.Now()), "the retry waits")
+
+	// Not due yet, so nothing is tried.
+	sent, err = mail.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+
+	for attempt := 2; attempt <= maxAttempts; attempt++ {
+		db.Model(&email).Update("next_attempt_at", time.Now().Add(-time.Second).UTC())
+		_, err = mail.Dispatch(context.Background())
+		assert.NoError(t, err)
+	}
+	db.First(&email)
+	assert.Equal(t, models.EmailFailed, email.Status)
+	assert.Equal(t, maxAttempts, email.Attempts)
+}
+
+func TestClaimedEmailsAreNotSentTwice(t *testing.T) {
+	sender := &recordingSender{}
+	mail, db := newTestOutbox(t, sender)
+	other := New(repository.NewOutboxRepository(db), sender)
+	assert.NoError(t, mail.Send(context.Background(), "ann@example.com", "Booked", "Room 1"))
+
+	var email models.OutboxEmail
+	db.First(&email)
+	claimed, err := repository.NewOutboxRepository(db).Claim(email.ID, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	sent, err := other.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Empty(t, sender.sent)
+
+	// A claim that outlived its dispatcher is handed back.
+	released, err := repository.NewOutboxRepository(db).ReleaseStale(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, released)
+	sent, err = other.Dispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
@@ -0,0 +1,176 @@
+// Package reminders emails organizers before their bookings start, at the
+// stages each organizer chose, e.g. a day, an hour and ten minutes before.
+// Stages on the calendar channel are popups on the Google Calendar event
+// instead, which Google delivers itself.
+//
+// Every reminder sent is recorded with the start time it announced. When a
+// booking moves, the earlier records no longer match and the organizer is
+// reminded of the new time. Stages that were already due when a booking was
+// made or moved are not sent one after another: only the latest due stage
+// goes out.
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/logging"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+)
+
+// Sender delivers an email, e.g. the outbox.
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+type Reminders struct {
+	repos  repository.Repositories
+	sender Sender
+}
+
+func New(repos repository.Repositories, sender Sender) *Reminders {
+	return &Reminders{repos: repos, sender: sender}
+}
+
+// subject is what one reminder is about: a single-room booking or all rooms
+// of a booking group.
+type subject struct {
+	booking  models.Booking
+	groupID  uint
+	bookings []models.Booking
+}
+
+// Send sends the reminders that are due at now and returns how many were
+// sent.
+func (r *Reminders) Send(ctx context.Context, now time.Time) (int, error) {
+	upcoming, err := r.repos.Bookings.Starting(now, now.Add(models.MaxMinutesBefore*time.Minute))
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch upcoming bookings: %w", err)
+	}
+
+	var subjects []*subject
+	groups := map[uint]*subject{}
+	for _, booking := range upcoming {
+		if booking.GroupID == nil {
+			subjects = append(subjects, &subject{booking: booking, bookings: []models.Booking{booking}})
+			continue
+		}
+		if s, ok := groups[*booking.GroupID]; ok {
+			s.bookings = append(s.bookings, booking)
+			continue
+		}
+		s := &subject{booking: booking, groupID: *booking.GroupID, bookings: []models.Booking{booking}}
+		groups[*booking.GroupID] = s
+		subjects = append(subjects, s)
+	}
+
+	sent := 0
+	for _, s := range subjects {
+		ok, err := r.remind(ctx, s, now)
+		if err != nil {
+			logging.FromContext(ctx).Error("Failed to send reminder", "booking_id", s.booking.ID,
+				"to", s.booking.Employee.Email, "error", err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// remind emails the organizer if one of their stages is due and was not sent
+// for the booking's current start time.
+func (r *Reminders) remind(ctx context.Context, s *subject, now time.Time) (bool, error) {
+	organizer := s.booking.Employee
+	if organizer.ID == 0 || !organizer.IsActive() {
+		return false, nil
+	}
+	settings, err := r.repos.Reminders.Settings(organizer.ID)
+	if err != nil {
+		return false, err
+	}
+	minutes, ok := DueStage(settings.StagesFor(models.ReminderEmail), s.booking.StartTime, now)
+	if !ok {
+		return false, nil
+	}
+
+	bookingID := s.booking.ID
+	if s.groupID != 0 {
+		bookingID = 0
+	}
+	previous, err := r.repos.Reminders.Sent(bookingID, s.groupID, organizer.ID)
+	if err != nil {
+		return false, err
+	}
+
+	reminder := models.Reminder{
+		BookingID:     bookingID,
+		GroupID:       s.groupID,
+		EmployeeID:    organizer.ID,
+		Channel:       models.ReminderEmail,
+		MinutesBefore: minutes,
+		StartTime:     s.booking.StartTime,
+		SentAt:        now,
+	}
+	// Claiming before sending means that two runs overlapping, e.g. during
+	// a change of leader, cannot send the same reminder twice.
+	claimed, err := r.repos.Reminders.Claim(&reminder)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	subjectLine, body := message(s, previous)
+	logger := logging.FromContext(ctx).With("booking_id", s.booking.ID, "group_id", s.groupID, "minutes_before", minutes)
+	logger.Info("Sending reminder", "to", organizer.Email)
+	if err := r.sender.Send(ctx, organizer.Email, subjectLine, body); err != nil {
+		if unclaimErr := r.repos.Reminders.Unclaim(reminder); unclaimErr != nil {
+			logger.Error("Failed to unclaim reminder", "reminder_id", reminder.ID, "error", unclaimErr)
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DueStage returns the stage, in minutes before start, to send at now: the
+// latest one whose time has come. minutes must be sorted ascending.
+func DueStage(minutes []int, start, now time.Time) (int, bool) {
+	if !now.Before(start) {
+		return 0, false
+	}
+	for _, m := range minutes {
+		if !now.Before(start.Add(-time.Duration(m) * time.Minute)) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+func message(s *subject, previous []models.Reminder) (string, string) {
+	location := fmt.Sprintf("Room %d", s.booking.RoomID)
+	if s.groupID != 0 {
+		names := make([]string, 0, len(s.bookings))
+		for _, b := range s.bookings {
+			names = append(names, b.Room.Name)
+		}
+		location = strings.Join(names, ", ")
+	} else if s.booking.Room.Name != "" {
+		location = s.booking.Room.Name
+	}
+
+	start, end := s.booking.StartTime, s.booking.EndTime
+	body := fmt.Sprintf("Reminder: You have a meeting in %s from %s to %s.",
+		location, start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	// The last reminder announcing another start time means the booking
+	// moved since.
+	if n := len(previous); n > 0 && !previous[n-1].StartTime.Equal(start) {
+		body += fmt.Sprintf(" The meeting has moved; it was due to start at %s.",
+			previous[n-1].StartTime.Format(time.RFC3339))
+		return "Meeting Reminder: time changed", body
+	}
+	return "Meeting Reminder", body
+}
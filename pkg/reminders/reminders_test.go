@@ -0,0 +1,166 @@
+package reminders
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type recordingSender struct {
+	subjects []string
+	bodies   []string
+	fail     error
+}
+
+func (s *recordingSender) Send(_ context.Context, to, subject, body string) error {
+	if s.fail != nil {
+		return s.fail
+	}
+	s.subjects = append(s.subjects, to+": "+subject)
+	s.bodies = append(s.bodies, body)
+	return nil
+}
+
+type fixture struct {
+	db        *gorm.DB
+	repos     repository.Repositories
+	sender    *recordingSender
+	reminders *Reminders
+	organizer models.Employee
+	rooms     []models.Room
+}
+
+func newFixture(t *testing.T) *fixture {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+
+	f := &fixture{db: db, repos: repository.New(db), sender: &recordingSender{}}
+	f.reminders = New(f.repos, f.sender)
+	f.organizer = models.Employee{Name: "Ann", Email: "ann@example.com"}
+	db.Create(&f.organizer)
+	f.rooms = []models.Room{{Name: "Focus"}, {Name: "Annex"}}
+	db.Create(&f.rooms)
+	return f
+}
+
+func (f *fixture) book(t *testing.T, room models.Room, start time.Time, groupID *uint) models.Booking {
+	booking := models.Booking{RoomID: room.ID, EmployeeID: f.organizer.ID, CreatedByID: f.organizer.ID,
+		StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingConfirmed, GroupID: groupID}
+	assert.NoError(t, f.db.Create(&booking).Error)
+	return booking
+}
+
+func (f *fixture) send(t *testing.T, now time.Time) int {
+	sent, err := f.reminders.Send(context.Background(), now)
+	assert.NoError(t, err)
+	return sent
+}
+
+func TestRemindersFollowTheStagesChosen(t *testing.T) {
+	f := newFixture(t)
+	assert.NoError(t, f.repos.Reminders.SaveSettings(&models.ReminderSettings{
+		EmployeeID: f.organizer.ID,
+		Stages: []models.ReminderStage{
+			{MinutesBefore: 10, Channels: []models.ReminderChannel{models.ReminderEmail}},
+			{MinutesBefore: 1440, Channels: []models.ReminderChannel{models.ReminderEmail}},
+			// Google Calendar delivers these.
+			{MinutesBefore: 60, Channels: []models.ReminderChannel{models.ReminderCalendar}},
+		},
+	}))
+	start := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	f.book(t, f.rooms[0], start, nil)
+
+	assert.Zero(t, f.send(t, start.Add(-25*time.Hour)))
+	assert.Equal(t, 1, f.send(t, start.Add(-23*time.Hour)))
+	assert.Zero(t, f.send(t, start.Add(-22*time.Hour)), "each stage is sent once")
+	assert.Zero(t, f.send(t, start.Add(-30*time.Minute)))
+	assert.Equal(t, 1, f.send(t, start.Add(-9*time.Minute)))
+	assert.Zero(t, f.send(t, start.Add(-5*time.Minute)))
+	assert.Zero(t, f.send(t, start.Add(time.Minute)), "started bookings are not reminded")
+
+	assert.Equal(t, []string{"ann@example.com: Meeting Reminder", "ann@example.com: Meeting Reminder"}, f.sender.subjects)
+	assert.Contains(t, f.sender.bodies[0], "Focus")
+}
+
+func TestOnlyTheLatestDueStageIsSent(t *testing.T) {
+	f := newFixture(t)
+	assert.NoError(t, f.repos.Reminders.SaveSettings(&models.ReminderSettings{
+		EmployeeID: f.organizer.ID,
+		Stages: []models.ReminderStage{
+			{MinutesBefore: 1440, Channels: []models.ReminderChannel{models.ReminderEmail}},
+			{MinutesBefore: 60, Channels: []models.ReminderChannel{models.ReminderEmail}},
+		},
+	}))
+	// Booked 45 minutes ahead: the day and hour stages are both overdue.
+	start := time.Now().Add(45 * time.Minute).Truncate(time.Second)
+	f.book(t, f.rooms[0], start, nil)
+
+	assert.Equal(t, 1, f.send(t, start.Add(-45*time.Minute)))
+	assert.Zero(t, f.send(t, start.Add(-44*time.Minute)))
+	assert.Len(t, f.sender.subjects, 1)
+}
+
+func TestMovedBookingsAreRemindedOfTheNewTime(t *testing.T) {
+	f := newFixture(t)
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	booking := f.book(t, f.rooms[0], start, nil)
+
+	// The default stage: one email ten minutes before.
+	assert.Equal(t, 1, f.send(t, start.Add(-10*time.Minute)))
+
+	moved := start.Add(2 * time.Hour)
+	booking.StartTime, booking.EndTime = moved, moved.Add(time.Hour)
+	assert.NoError(t, f.db.Save(&booking).Error)
+
+	assert.Zero(t, f.send(t, start.Add(-5*time.Minute)), "the old time is no longer due")
+	assert.Equal(t, 1, f.send(t, moved.Add(-10*time.Minute)))
+	assert.Equal(t, "ann@example.com: Meeting Reminder: time changed", f.sender.subjects[1])
+	assert.Contains(t, f.sender.bodies[1], start.UTC().Format(time.RFC3339))
+}
+
+func TestBookingGroupsGetOneReminder(t *testing.T) {
+	f := newFixture(t)
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	group := models.BookingGroup{EmployeeID: f.organizer.ID, CreatedByID: f.organizer.ID,
+		StartTime: start, EndTime: start.Add(time.Hour)}
+	assert.NoError(t, f.db.Create(&group).Error)
+	f.book(t, f.rooms[0], start, &group.ID)
+	f.book(t, f.rooms[1], start, &group.ID)
+
+	assert.Equal(t, 1, f.send(t, start.Add(-5*time.Minute)))
+	assert.Zero(t, f.send(t, start.Add(-4*time.Minute)))
+	assert.Contains(t, f.sender.bodies[0], "Focus, Annex")
+}
+
+func TestTurnedOffRemindersAreNotSent(t *testing.T) {
+	f := newFixture(t)
+	assert.NoError(t, f.repos.Reminders.SaveSettings(&models.ReminderSettings{
+		EmployeeID: f.organizer.ID, Stages: []models.ReminderStage{}}))
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	f.book(t, f.rooms[0], start, nil)
+
+	assert.Zero(t, f.send(t, start.Add(-5*time.Minute)))
+	assert.Empty(t, f.sender.subjects)
+}
+
+func TestFailedRemindersAreRetried(t *testing.T) {
+	f := newFixture(t)
+	start := time.Now().Add(time.Hour).Truncate(time.Second)
+	f.book(t, f.rooms[0], start, nil)
+
+	f.sender.fail = errors.New("queue unavailable")
+	assert.Zero(t, f.send(t, start.Add(-5*time.Minute)))
+
+	f.sender.fail = nil
+	assert.Equal(t, 1, f.send(t, start.Add(-4*time.Minute)))
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+)
+
+type ApproverGroupRepository interface {
+	// Get loads an approver group with its members.
+	Get(id uint) (models.ApproverGroup, error)
+	// All returns every approver group with its members.
+	All() ([]models.ApproverGroup, error)
+	Create(group *models.ApproverGroup) error
+}
+
+type approverGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewApproverGroupRepository(db *gorm.DB) ApproverGroupRepository {
+	return &approverGroupRepository{db: db}
+}
+
+func (r *approverGroupRepository) Get(id uint) (models.ApproverGroup, error) {
+	var group models.ApproverGroup
+	err := r.db.Preload("Members").First(&group, id).Error
+	return group, err
+}
+
+func (r *approverGroupRepository) All() ([]models.ApproverGroup, error) {
+	var groups []models.ApproverGroup
+	err := r.db.Preload("Members").Find(&groups).Error
+	return groups, err
+}
+
+func (r *approverGroupRepository) Create(group *models.ApproverGroup) error {
+	return r.db.Create(group).Error
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/listing"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrStaleBooking is returned when a booking was changed by someone else
+// between loading and updating it.
+var ErrStaleBooking = errors.New("booking status changed concurrently")
+
+type BookingRepository interface {
+	// Get loads a booking and the named associations, e.g. "Room".
+	Get(id uint, preload ...string) (models.Booking, error)
+	// List returns one page of the bookings the viewer may see, with their
+	// room and organizer.
+	List(q *listing.Query, viewerID uint) ([]models.Booking, error)
+	// Overlapping returns the bookings holding the room at some point
+	// between start and end, other than excludeID.
+	Overlapping(roomID uint, start, end time.Time, excludeID uint) ([]models.Booking, error)
+	// OverlappingOutsideGroup is Overlapping for a room of a booking group,
+	// ignoring the group's own bookings.
+	OverlappingOutsideGroup(roomID uint, start, end time.Time, groupID uint) ([]models.Booking, error)
+	// During returns every booking holding a room at some point between
+	// start and end, with its attendees.
+	During(start, end time.Time) ([]models.Booking, error)
+	// Upcoming returns the organizer's active single-room bookings that start
+	// after now, with their room and attendees.
+	Upcoming(employeeID uint, now time.Time) ([]models.Booking, error)
+	// PendingFor returns the bookings awaiting the approver's decision.
+	PendingFor(approverID uint) ([]models.Booking, error)
+	// Lapsed returns bookings in the status whose deadline passed before now.
+	Lapsed(status models.BookingStatus, now time.Time) ([]models.Booking, error)
+	// Finished returns confirmed and checked-in bookings that ended before now.
+	Finished(now time.Time) ([]models.Booking, error)
+	// Starting returns confirmed bookings that start after from and no later
+	// than to, with their room and organizer.
+	Starting(from, to time.Time) ([]models.Booking, error)
+	// Agenda returns the active bookings starting between from and to, with
+	// their room, organizer and attendees, in order of start.
+	Agenda(from, to time.Time) ([]models.Booking, error)
+	// Unattended returns confirmed bookings that started before startedBefore
+	// without anyone checking in, and have not ended at now.
+	Unattended(startedBefore, now time.Time) ([]models.Booking, error)
+	History(id uint) ([]models.BookingTransition, error)
+	// Create inserts the booking and the first entry of its history.
+	Create(booking *models.Booking, actorID *uint, reason string) error
+	// Update saves the booking, replaces its attendees unless attendees is
+	// nil, and records a change from the previous status.
+	Update(booking *models.Booking, previous models.BookingStatus, attendees *[]models.Employee, actorID *uint, reason string) error
+	// Transition validates and applies a status change and records it.
+	Transition(booking *models.Booking, to models.BookingStatus, actorID *uint, reason string) error
+	// Reassign makes another employee the organizer of a single-room booking
+	// and records the change in its history.
+	Reassign(booking *models.Booking, fromID uint, to models.Employee, actorID *uint, reason string) error
+	SetCalendarID(id uint, eventID string) error
+}
+
+type bookingRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingRepository(db *gorm.DB) BookingRepository {
+	return &bookingRepository{db: db}
+}
+
+// Blocking scopes a query to bookings that currently hold their slot. Lapsed
+// holds stop blocking straight away, before the sweeper releases them.
+func Blocking(db *gorm.DB) *gorm.DB {
+	return db.Where("status IN ?", models.ActiveBookingStatuses).
+		Where("NOT (status = ? AND expires_at < ?)", models.BookingTentative, time.Now().UTC())
+}
+
+// LogTransition appends an entry to the booking's status history.
+func LogTransition(db *gorm.DB, bookingID uint, from, to models.BookingStatus, actorID *uint, reason string) error {
+	return db.Create(&models.BookingTransition{
+		BookingID:  bookingID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+		ActorID:    actorID,
+	}).Error
+}
+
+func (r *bookingRepository) Get(id uint, preload ...string) (models.Booking, error) {
+	query := r.db
+	for _, association := range preload {
+		query = query.Preload(association)
+	}
+	var booking models.Booking
+	err := query.First(&booking, id).Error
+	return booking, err
+}
+
+// List limits the bookings to those the viewer organizes or created and those
+// of the employees the viewer is a delegate of, as BookingService.CanView.
+func (r *bookingRepository) List(q *listing.Query, viewerID uint) ([]models.Booking, error) {
+	delegators := r.db.Model(&models.Delegation{}).Select("principal_id").Where("delegate_id = ?", viewerID)
+	visible := r.db.Where("employee_id = ? OR created_by_id = ? OR employee_id IN (?)", viewerID, viewerID, delegators)
+	query, err := q.Apply(visible.Preload("Room").Preload("Employee"))
+	if err != nil {
+		return nil, err
+	}
+	var bookings []models.Booking
+	err = query.Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) Overlapping(roomID uint, start, end time.Time, excludeID uint) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Scopes(Blocking).
+		Where("room_id = ? AND id <> ? AND start_time < ? AND end_time > ?", roomID, excludeID, end.UTC(), start.UTC()).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) OverlappingOutsideGroup(roomID uint, start, end time.Time, groupID uint) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Scopes(Blocking).
+		Where("room_id = ? AND start_time < ? AND end_time > ?", roomID, end.UTC(), start.UTC()).
+		Where("group_id IS NULL OR group_id <> ?", groupID).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) During(start, end time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Scopes(Blocking).Preload("Attendees").
+		Where("start_time < ? AND end_time > ?", end.UTC(), start.UTC()).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) Upcoming(employeeID uint, now time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Preload("Room").Preload("Attendees").
+		Where("employee_id = ? AND group_id IS NULL AND status IN ? AND start_time > ?",
+			employeeID, models.ActiveBookingStatuses, now.UTC()).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) PendingFor(approverID uint) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Preload("Room").Preload("Employee").
+		Joins("JOIN rooms ON rooms.id = bookings.room_id").
+		Joins("JOIN approver_group_members ON approver_group_members.approver_group_id = rooms.approver_group_id").
+		Where("approver_group_members.employee_id = ? AND bookings.status = ?", approverID, models.BookingPendingApproval).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) Lapsed(status models.BookingStatus, now time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Preload("Room").Preload("Employee").
+		Where("status = ? AND expires_at < ?", status, now.UTC()).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) Finished(now time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Where("status IN ? AND end_time < ?",
+		[]models.BookingStatus{models.BookingConfirmed, models.BookingCheckedIn}, now.UTC()).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) Starting(from, to time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Preload("Room").Preload("Employee").
+		Where("status = ? AND start_time > ? AND start_time <= ?", models.BookingConfirmed, from.UTC(), to.UTC()).
+		Order("start_time, id").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) Agenda(from, to time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Preload("Room").Preload("Employee").Preload("Attendees").
+		Where("status IN ? AND start_time >= ? AND start_time < ?", models.ActiveBookingStatuses, from.UTC(), to.UTC()).
+		Order("start_time, id").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) Unattended(startedBefore, now time.Time) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Preload("Room").Preload("Employee").
+		Where("status = ? AND start_time < ? AND end_time > ?", models.BookingConfirmed, startedBefore.UTC(), now.UTC()).
+		Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *bookingRepository) History(id uint) ([]models.BookingTransition, error) {
+	var transitions []models.BookingTransition
+	err := r.db.Where("booking_id = ?", id).Order("id").Find(&transitions).Error
+	return transitions, err
+}
+
+func (r *bookingRepository) Create(booking *models.Booking, actorID *uint, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(booking).Error; err != nil {
+			return err
+		}
+		return LogTransition(tx, booking.ID, "", booking.Status, actorID, reason)
+	})
+}
+
+func (r *bookingRepository) Update(booking *models.Booking, previous models.BookingStatus, attendees *[]models.Employee, actorID *uint, reason string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(booking).Error; err != nil {
+			return err
+		}
+		if attendees != nil {
+			if err := tx.Model(booking).Association("Attendees").Replace(*attendees); err != nil {
+				return err
+			}
+			booking.Attendees = *attendees
+		}
+		if booking.Status != previous {
+			return LogTransition(tx, booking.ID, previous, booking.Status, actorID, reason)
+		}
+		return nil
+	})
+}
+
+// Transition only succeeds if nobody else changed the status in the
+// meantime. ExpiresAt is kept only for statuses that lapse.
+func (r *bookingRepository) Transition(booking *models.Booking, to models.BookingStatus, actorID *uint, reason string) error {
+	from := booking.Status
+	if err := models.ValidateTransition(from, to); err != nil {
+		return err
+	}
+	var expiresAt *time.Time
+	if booking.ExpiresAt != nil && (to == models.BookingTentative || to == models.BookingPendingApproval) {
+		utc := booking.ExpiresAt.UTC()
+		expiresAt = &utc
+	}
+
+	updates := map[string]interface{}{"status": to, "expires_at": expiresAt}
+	if to == models.BookingPendingApproval {
+		updates["pending_since"] = booking.PendingSince
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Booking{}).
+			Where("id = ? AND status = ?", booking.ID, from).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleBooking
+		}
+		return LogTransition(tx, booking.ID, from, to, actorID, reason)
+	})
+	if err != nil {
+		return err
+	}
+
+	booking.Status = to
+	booking.ExpiresAt = expiresAt
+	return nil
+}
+
+func (r *bookingRepository) Reassign(booking *models.Booking, fromID uint, to models.Employee, actorID *uint, reason string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Booking{}).
+			Where("id = ? AND employee_id = ?", booking.ID, fromID).
+			Update("employee_id", to.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleBooking
+		}
+		// The new organizer does not need an invitation to their own meeting.
+		if err := tx.Model(booking).Association("Attendees").Delete(&to); err != nil {
+			return err
+		}
+		return LogTransition(tx, booking.ID, booking.Status, booking.Status, actorID, reason)
+	})
+	if err != nil {
+		return err
+	}
+	booking.EmployeeID = to.ID
+	booking.Employee = to
+	return nil
+}
+
+func (r *bookingRepository) SetCalendarID(id uint, eventID string) error {
+	return r.db.Model(&models.Booking{}).Where("id = ?", id).Update("calendar_id", eventID).Error
+}
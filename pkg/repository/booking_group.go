@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+)
+
+type BookingGroupRepository interface {
+	// Get loads a group and the named associations, e.g. "Bookings.Room".
+	Get(id uint, preload ...string) (models.BookingGroup, error)
+	// GetActive loads a group with its organizer and its active bookings,
+	// including their rooms and attendees.
+	GetActive(id uint) (models.BookingGroup, error)
+	// Upcoming returns the organizer's groups that start after now and still
+	// have active bookings, loaded like GetActive.
+	Upcoming(employeeID uint, now time.Time) ([]models.BookingGroup, error)
+	Create(group *models.BookingGroup) error
+	// Reschedule changes the title and slot of a group and forgets its
+	// calendar event.
+	Reschedule(group *models.BookingGroup, title string, start, end time.Time) error
+	// Reassign makes another employee the organizer of the group and of
+	// every booking in it, and records the change in each booking's history.
+	Reassign(group *models.BookingGroup, fromID uint, to models.Employee, actorID *uint, reason string) error
+	SetCalendarID(id uint, eventID string) error
+}
+
+type bookingGroupRepository struct {
+	db *gorm.DB
+}
+
+func NewBookingGroupRepository(db *gorm.DB) BookingGroupRepository {
+	return &bookingGroupRepository{db: db}
+}
+
+func (r *bookingGroupRepository) Get(id uint, preload ...string) (models.BookingGroup, error) {
+	query := r.db
+	for _, association := range preload {
+		query = query.Preload(association)
+	}
+	var group models.BookingGroup
+	err := query.First(&group, id).Error
+	return group, err
+}
+
+// active preloads what GetActive and Upcoming return.
+func (r *bookingGroupRepository) active() *gorm.DB {
+	return r.db.Preload("Employee").Preload("Bookings", "status IN ?", models.ActiveBookingStatuses).
+		Preload("Bookings.Room").Preload("Bookings.Attendees")
+}
+
+func (r *bookingGroupRepository) GetActive(id uint) (models.BookingGroup, error) {
+	var group models.BookingGroup
+	err := r.active().First(&group, id).Error
+	return group, err
+}
+
+func (r *bookingGroupRepository) Upcoming(employeeID uint, now time.Time) ([]models.BookingGroup, error) {
+	var groups []models.BookingGroup
+	err := r.active().Where("employee_id = ? AND start_time > ?", employeeID, now.UTC()).Find(&groups).Error
+	if err != nil {
+		return nil, err
+	}
+	active := groups[:0]
+	for _, g := range groups {
+		if len(g.Bookings) > 0 {
+			active = append(active, g)
+		}
+	}
+	return active, nil
+}
+
+func (r *bookingGroupRepository) Create(group *models.BookingGroup) error {
+	return r.db.Create(group).Error
+}
+
+func (r *bookingGroupRepository) Reschedule(group *models.BookingGroup, title string, start, end time.Time) error {
+	err := r.db.Model(group).Updates(map[string]interface{}{
+		"title":       title,
+		"start_time":  start.UTC(),
+		"end_time":    end.UTC(),
+		"calendar_id": "",
+	}).Error
+	if err == nil {
+		group.Title = title
+		group.StartTime = start
+		group.EndTime = end
+		group.CalendarID = ""
+	}
+	return err
+}
+
+func (r *bookingGroupRepository) Reassign(group *models.BookingGroup, fromID uint, to models.Employee, actorID *uint, reason string) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.BookingGroup{}).
+			Where("id = ? AND employee_id = ?", group.ID, fromID).
+			Update("employee_id", to.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStaleBooking
+		}
+		if err := tx.Model(&models.Booking{}).Where("group_id = ?", group.ID).Update("employee_id", to.ID).Error; err != nil {
+			return err
+		}
+		for i := range group.Bookings {
+			b := &group.Bookings[i]
+			// The new organizer does not need an invitation to their own meeting.
+			if err := tx.Model(b).Association("Attendees").Delete(&to); err != nil {
+				return err
+			}
+			if err := LogTransition(tx, b.ID, b.Status, b.Status, actorID, reason); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	group.EmployeeID = to.ID
+	group.Employee = to
+	for i := range group.Bookings {
+		group.Bookings[i].EmployeeID = to.ID
+	}
+	return nil
+}
+
+func (r *bookingGroupRepository) SetCalendarID(id uint, eventID string) error {
+	return r.db.Model(&models.BookingGroup{}).Where("id = ?", id).Update("calendar_id", eventID).Error
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/dbtest"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// eachDB runs fn against a migrated database of every test driver.
+func eachDB(t *testing.T, fn func(t *testing.T, repos Repositories, db *gorm.DB)) {
+	dbtest.Each(t, func(t *testing.T, db *gorm.DB) {
+		_, err := migrations.Up(db)
+		assert.NoError(t, err)
+		fn(t, New(db), db)
+	})
+}
+
+func seed(t *testing.T, db *gorm.DB) (models.Employee, models.Room) {
+	employee := models.Employee{Name: "Ann", Email: "ann@example.com"}
+	room := models.Room{Name: "Focus"}
+	assert.NoError(t, db.Create(&employee).Error)
+	assert.NoError(t, db.Create(&room).Error)
+	return employee, room
+}
+
+func TestOverlappingComparesTimesAcrossOffsets(t *testing.T) {
+	eachDB(t, func(t *testing.T, repos Repositories, db *gorm.DB) {
+		employee, room := seed(t, db)
+		start := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+		booking := models.Booking{RoomID: room.ID, EmployeeID: employee.ID, CreatedByID: employee.ID,
+			StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingConfirmed}
+		assert.NoError(t, repos.Bookings.Create(&booking, &employee.ID, "created"))
+
+		// 14:00-14:30 in UTC+5:30 is 08:30-09:00 UTC, right before the booking.
+		india := time.FixedZone("IST", 5*3600+1800)
+		before := time.Date(2030, 1, 7, 14, 0, 0, 0, india)
+		overlapping, err := repos.Bookings.Overlapping(room.ID, before, before.Add(30*time.Minute), 0)
+		assert.NoError(t, err)
+		assert.Empty(t, overlapping)
+
+		overlapping, err = repos.Bookings.Overlapping(room.ID, before, before.Add(45*time.Minute), 0)
+		assert.NoError(t, err)
+		assert.Len(t, overlapping, 1)
+
+		overlapping, err = repos.Bookings.Overlapping(room.ID, start, start.Add(time.Hour), booking.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, overlapping)
+	})
+}
+
+func TestLapsedHoldsStopBlocking(t *testing.T) {
+	eachDB(t, func(t *testing.T, repos Repositories, db *gorm.DB) {
+		employee, room := seed(t, db)
+		start := time.Now().Add(24 * time.Hour)
+		expired := time.Now().Add(-time.Minute)
+		hold := models.Booking{RoomID: room.ID, EmployeeID: employee.ID, CreatedByID: employee.ID,
+			StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingTentative, ExpiresAt: &expired}
+		assert.NoError(t, repos.Bookings.Create(&hold, &employee.ID, "held"))
+
+		overlapping, err := repos.Bookings.Overlapping(room.ID, start, start.Add(time.Hour), 0)
+		assert.NoError(t, err)
+		assert.Empty(t, overlapping)
+
+		lapsed, err := repos.Bookings.Lapsed(models.BookingTentative, time.Now())
+		assert.NoError(t, err)
+		assert.Len(t, lapsed, 1)
+		assert.Equal(t, room.Name, lapsed[0].Room.Name)
+	})
+}
+
+func TestTransitionDetectsStaleStatus(t *testing.T) {
+	eachDB(t, func(t *testing.T, repos Repositories, db *gorm.DB) {
+		employee, room := seed(t, db)
+		start := time.Now().Add(time.Hour)
+		booking := models.Booking{RoomID: room.ID, EmployeeID: employee.ID, CreatedByID: employee.ID,
+			StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingConfirmed}
+		assert.NoError(t, repos.Bookings.Create(&booking, &employee.ID, "created"))
+
+		stale := booking
+		assert.NoError(t, repos.Bookings.Transition(&booking, models.BookingCancelled, &employee.ID, "cancelled"))
+		assert.ErrorIs(t, repos.Bookings.Transition(&stale, models.BookingCheckedIn, &employee.ID, "checked in"), ErrStaleBooking)
+
+		history, err := repos.Bookings.History(booking.ID)
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, models.BookingCancelled, history[1].ToStatus)
+	})
+}
+
+func TestPendingForJoinsApproverGroups(t *testing.T) {
+	eachDB(t, func(t *testing.T, repos Repositories, db *gorm.DB) {
+		employee, _ := seed(t, db)
+		approver := models.Employee{Name: "Bob", Email: "bob@example.com"}
+		assert.NoError(t, db.Create(&approver).Error)
+		group := models.ApproverGroup{Name: "Facilities", Members: []models.Employee{approver}}
+		assert.NoError(t, repos.ApproverGroups.Create(&group))
+		room := models.Room{Name: "Board", RequiresApproval: true, ApproverGroupID: &group.ID}
+		assert.NoError(t, db.Create(&room).Error)
+
+		start := time.Now().Add(time.Hour)
+		booking := models.Booking{RoomID: room.ID, EmployeeID: employee.ID, CreatedByID: employee.ID,
+			StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingPendingApproval}
+		assert.NoError(t, repos.Bookings.Create(&booking, &employee.ID, "requested"))
+
+		pending, err := repos.Bookings.PendingFor(approver.ID)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, booking.ID, pending[0].ID)
+
+		pending, err = repos.Bookings.PendingFor(employee.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DelegationRepository interface {
+	Get(id uint) (models.Delegation, error)
+	// Between returns the delegation from principal to delegate, if any.
+	Between(principalID, delegateID uint) (models.Delegation, error)
+	// Grant creates the delegation or replaces the permissions of an
+	// existing one between the same employees.
+	Grant(delegation *models.Delegation) error
+	Granted(principalID uint) ([]models.Delegation, error)
+	Received(delegateID uint) ([]models.Delegation, error)
+	Delete(delegation *models.Delegation) error
+}
+
+type delegationRepository struct {
+	db *gorm.DB
+}
+
+func NewDelegationRepository(db *gorm.DB) DelegationRepository {
+	return &delegationRepository{db: db}
+}
+
+func (r *delegationRepository) Get(id uint) (models.Delegation, error) {
+	var delegation models.Delegation
+	err := r.db.First(&delegation, id).Error
+	return delegation, err
+}
+
+func (r *delegationRepository) Between(principalID, delegateID uint) (models.Delegation, error) {
+	var delegation models.Delegation
+	err := r.db.Where("principal_id = ? AND delegate_id = ?", principalID, delegateID).First(&delegation).Error
+	return delegation, err
+}
+
+func (r *delegationRepository) Grant(delegation *models.Delegation) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "principal_id"}, {Name: "delegate_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"can_book", "can_edit", "can_cancel", "updated_at"}),
+	}).Create(delegation).Error
+	if err != nil {
+		return err
+	}
+	// On conflict the ID and creation time are those of the existing row.
+	return r.db.Where("principal_id = ? AND delegate_id = ?", delegation.PrincipalID, delegation.DelegateID).
+		First(delegation).Error
+}
+
+func (r *delegationRepository) Granted(principalID uint) ([]models.Delegation, error) {
+	delegations := []models.Delegation{}
+	err := r.db.Where("principal_id = ?", principalID).Find(&delegations).Error
+	return delegations, err
+}
+
+func (r *delegationRepository) Received(delegateID uint) ([]models.Delegation, error) {
+	delegations := []models.Delegation{}
+	err := r.db.Where("delegate_id = ?", delegateID).Find(&delegations).Error
+	return delegations, err
+}
+
+func (r *delegationRepository) Delete(delegation *models.Delegation) error {
+	return r.db.Unscoped().Delete(delegation).Error
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/listing"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+)
+
+type EmployeeRepository interface {
+	Get(id uint) (models.Employee, error)
+	// GetWithBookings also loads the employee's bookings with their rooms.
+	GetWithBookings(id uint) (models.Employee, error)
+	GetByEmail(email string) (models.Employee, error)
+	// FindAll loads the employees with the given IDs, failing with
+	// ErrNotFound unless every one of them exists.
+	FindAll(ids []uint) ([]models.Employee, error)
+	List(q *listing.Query) ([]models.Employee, error)
+	// EmailTaken reports whether another employee than exceptID uses email.
+	EmailTaken(email string, exceptID uint) (bool, error)
+	Create(employee *models.Employee) error
+	Save(employee *models.Employee) error
+	// ApproverGroupIDs returns the approver groups the employee belongs to.
+	ApproverGroupIDs(employeeID uint) ([]uint, error)
+	// Deactivate marks the employee as deactivated and drops every
+	// delegation they granted or received.
+	Deactivate(employee *models.Employee, at time.Time) error
+}
+
+type employeeRepository struct {
+	db *gorm.DB
+}
+
+func NewEmployeeRepository(db *gorm.DB) EmployeeRepository {
+	return &employeeRepository{db: db}
+}
+
+func (r *employeeRepository) Get(id uint) (models.Employee, error) {
+	var employee models.Employee
+	err := r.db.First(&employee, id).Error
+	return employee, err
+}
+
+func (r *employeeRepository) GetWithBookings(id uint) (models.Employee, error) {
+	var employee models.Employee
+	err := r.db.Preload("Bookings.Room").Preload("Bookings.Employee").First(&employee, id).Error
+	return employee, err
+}
+
+func (r *employeeRepository) GetByEmail(email string) (models.Employee, error) {
+	var employee models.Employee
+	err := r.db.Where("email = ?", email).First(&employee).Error
+	return employee, err
+}
+
+func (r *employeeRepository) FindAll(ids []uint) ([]models.Employee, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	unique := map[uint]bool{}
+	for _, id := range ids {
+		unique[id] = true
+	}
+	var employees []models.Employee
+	if err := r.db.Find(&employees, ids).Error; err != nil {
+		return nil, err
+	}
+	if len(employees) != len(unique) {
+		return nil, ErrNotFound
+	}
+	return employees, nil
+}
+
+func (r *employeeRepository) List(q *listing.Query) ([]models.Employee, error) {
+	query, err := q.Apply(r.db)
+	if err != nil {
+		return nil, err
+	}
+	var employees []models.Employee
+	err = query.Find(&employees).Error
+	return employees, err
+}
+
+func (r *employeeRepository) EmailTaken(email string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Employee{}).Where("email = ? AND id <> ?", email, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *employeeRepository) Create(employee *models.Employee) error {
+	return r.db.Create(employee).Error
+}
+
+func (r *employeeRepository) Save(employee *models.Employee) error {
+	return r.db.Save(employee).Error
+}
+
+func (r *employeeRepository) ApproverGroupIDs(employeeID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Table("approver_group_members").Where("employee_id = ?", employeeID).
+		Pluck("approver_group_id", &ids).Error
+	return ids, err
+}
+
+func (r *employeeRepository) Deactivate(employee *models.Employee, at time.Time) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(employee).Update("deactivated_at", at).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("principal_id = ? OR delegate_id = ?", employee.ID, employee.ID).
+			Delete(&models.Delegation{}).Error
+	})
+	if err == nil {
+		employee.DeactivatedAt = &at
+	}
+	return err
+}
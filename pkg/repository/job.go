@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// JobRepository keeps the state of the background jobs, shared by every
+// instance of the server.
+type JobRepository interface {
+	// Register adds the job or updates its schedule, keeping its state.
+	Register(name, schedule string) error
+	Get(name string) (models.Job, error)
+	List() ([]models.Job, error)
+	// Begin marks the job running on holder and reports false if it is
+	// already running, unless that run started before staleBefore and is
+	// assumed to have died with its instance.
+	Begin(name, holder string, now, staleBefore time.Time) (bool, error)
+	// Finish records the outcome of a run and adds it to the totals.
+	Finish(name string, run models.JobRun) error
+	SetPaused(name string, paused bool) error
+	SetNextRun(name string, next *time.Time) error
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Register(name, schedule string) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"schedule", "updated_at"}),
+	}).Create(&models.Job{Name: name, Schedule: schedule, Status: models.JobIdle}).Error
+}
+
+func (r *jobRepository) Get(name string) (models.Job, error) {
+	var job models.Job
+	err := r.db.Where("name = ?", name).First(&job).Error
+	return job, err
+}
+
+func (r *jobRepository) List() ([]models.Job, error) {
+	jobs := []models.Job{}
+	err := r.db.Order("name").Find(&jobs).Error
+	return jobs, err
+}
+
+// Begin is a single conditional update, so two instances cannot both start
+// the same job.
+func (r *jobRepository) Begin(name, holder string, now, staleBefore time.Time) (bool, error) {
+	now = now.UTC()
+	result := r.db.Model(&models.Job{}).
+		Where("name = ? AND (status <> ? OR last_started_at < ?)", name, models.JobRunning, staleBefore.UTC()).
+		Updates(map[string]interface{}{
+			"status":          models.JobRunning,
+			"run_by":          holder,
+			"last_started_at": now,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *jobRepository) Finish(name string, run models.JobRun) error {
+	status, lastError := models.JobSucceeded, ""
+	failed := 0
+	if run.Err != nil {
+		status, lastError, failed = models.JobFailed, run.Err.Error(), 1
+	}
+	duration := run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	var next *time.Time
+	if run.NextRunAt != nil {
+		utc := run.NextRunAt.UTC()
+		next = &utc
+	}
+	return r.db.Model(&models.Job{}).Where("name = ?", name).Updates(map[string]interface{}{
+		"status":            status,
+		"run_by":            "",
+		"last_finished_at":  run.FinishedAt.UTC(),
+		"last_duration_ms":  duration,
+		"last_processed":    run.Processed,
+		"last_error":        lastError,
+		"next_run_at":       next,
+		"runs":              gorm.Expr("runs + 1"),
+		"failures":          gorm.Expr("failures + ?", failed),
+		"total_duration_ms": gorm.Expr("total_duration_ms + ?", duration),
+	}).Error
+}
+
+func (r *jobRepository) SetPaused(name string, paused bool) error {
+	return r.db.Model(&models.Job{}).Where("name = ?", name).Update("paused", paused).Error
+}
+
+func (r *jobRepository) SetNextRun(name string, next *time.Time) error {
+	if next != nil {
+		utc := next.UTC()
+		next = &utc
+	}
+	return r.db.Model(&models.Job{}).Where("name = ?", name).Update("next_run_at", next).Error
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeaseRepository hands out leases on named resources.
+type LeaseRepository interface {
+	// Acquire takes or renews the lease for holder until expiresAt. It
+	// reports false while someone else holds an unexpired lease.
+	Acquire(name, holder string, now, expiresAt time.Time) (bool, error)
+	// Release gives up the lease if holder has it.
+	Release(name, holder string) error
+	Get(name string) (models.Lease, error)
+}
+
+type leaseRepository struct {
+	db *gorm.DB
+}
+
+func NewLeaseRepository(db *gorm.DB) LeaseRepository {
+	return &leaseRepository{db: db}
+}
+
+// Acquire relies on single-statement updates and inserts being atomic, so
+// two instances racing for the same lease cannot both win.
+func (r *leaseRepository) Acquire(name, holder string, now, expiresAt time.Time) (bool, error) {
+	result := r.db.Model(&models.Lease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", name, holder, now.UTC()).
+		Updates(map[string]interface{}{"holder": holder, "expires_at": expiresAt.UTC()})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return true, nil
+	}
+
+	result = r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.Lease{Name: name, Holder: holder, ExpiresAt: expiresAt.UTC()})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *leaseRepository) Release(name, holder string) error {
+	return r.db.Where("name = ? AND holder = ?", name, holder).Delete(&models.Lease{}).Error
+}
+
+func (r *leaseRepository) Get(name string) (models.Lease, error) {
+	var lease models.Lease
+	err := r.db.Where("name = ?", name).First(&lease).Error
+	return lease, err
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+)
+
+// OutboxRepository stores the emails waiting to be sent.
+type OutboxRepository interface {
+	Enqueue(email *models.OutboxEmail) error
+	// Due returns up to limit pending emails whose next attempt is due.
+	Due(now time.Time, limit int) ([]models.OutboxEmail, error)
+	// Claim marks a pending email as being sent. It reports false if another
+	// dispatcher claimed it first.
+	Claim(id uint, now time.Time) (bool, error)
+	MarkSent(id uint, now time.Time) error
+	// Retry puts a claimed email back in the queue until retryAt, or fails
+	// it for good when retryAt is nil.
+	Retry(id uint, attempts int, lastError string, retryAt *time.Time) error
+	// ReleaseStale returns emails claimed before cutoff to the queue, in
+	// case their dispatcher died while sending them.
+	ReleaseStale(cutoff time.Time) (int64, error)
+	// Purge deletes sent and failed emails last touched before cutoff.
+	Purge(cutoff time.Time) (int64, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Enqueue(email *models.OutboxEmail) error {
+	if email.Status == "" {
+		email.Status = models.EmailPending
+	}
+	if email.NextAttemptAt.IsZero() {
+		email.NextAttemptAt = time.Now().UTC()
+	}
+	return r.db.Create(email).Error
+}
+
+func (r *outboxRepository) Due(now time.Time, limit int) ([]models.OutboxEmail, error) {
+	var emails []models.OutboxEmail
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", models.EmailPending, now.UTC()).
+		Order("id").Limit(limit).Find(&emails).Error
+	return emails, err
+}
+
+func (r *outboxRepository) Claim(id uint, now time.Time) (bool, error) {
+	result := r.db.Model(&models.OutboxEmail{}).
+		Where("id = ? AND status = ?", id, models.EmailPending).
+		Updates(map[string]interface{}{"status": models.EmailSending, "claimed_at": now.UTC()})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *outboxRepository) MarkSent(id uint, now time.Time) error {
+	return r.db.Model(&models.OutboxEmail{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": models.EmailSent, "sent_at": now.UTC(), "last_error": ""}).Error
+}
+
+func (r *outboxRepository) Retry(id uint, attempts int, lastError string, retryAt *time.Time) error {
+	updates := map[string]interface{}{"attempts": attempts, "last_error": lastError, "status": models.EmailFailed}
+	if retryAt != nil {
+		updates["status"] = models.EmailPending
+		updates["next_attempt_at"] = retryAt.UTC()
+	}
+	return r.db.Model(&models.OutboxEmail{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *outboxRepository) ReleaseStale(cutoff time.Time) (int64, error) {
+	result := r.db.Model(&models.OutboxEmail{}).
+		Where("status = ? AND claimed_at < ?", models.EmailSending, cutoff.UTC()).
+		Update("status", models.EmailPending)
+	return result.RowsAffected, result.Error
+}
+
+func (r *outboxRepository) Purge(cutoff time.Time) (int64, error) {
+	result := r.db.Where("status IN ? AND updated_at < ?",
+		[]models.EmailStatus{models.EmailSent, models.EmailFailed}, cutoff.UTC()).
+		Delete(&models.OutboxEmail{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReminderRepository interface {
+	// Settings returns the employee's reminder settings, or the default
+	// stages for employees who never saved their own.
+	Settings(employeeID uint) (models.ReminderSettings, error)
+	// SaveSettings creates or replaces the employee's settings.
+	SaveSettings(settings *models.ReminderSettings) error
+	// Claim records the reminder before it is sent and reports false if it
+	// was recorded already, so no reminder goes out twice.
+	Claim(reminder *models.Reminder) (bool, error)
+	// Unclaim forgets a claimed reminder that could not be sent, so it is
+	// tried again.
+	Unclaim(reminder models.Reminder) error
+	// Sent returns the reminders sent to the employee for a booking, or for a
+	// booking group when bookingID is 0.
+	Sent(bookingID, groupID, employeeID uint) ([]models.Reminder, error)
+	// Purge deletes the records of reminders for meetings that started
+	// before cutoff.
+	Purge(cutoff time.Time) (int64, error)
+}
+
+type reminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) ReminderRepository {
+	return &reminderRepository{db: db}
+}
+
+func (r *reminderRepository) Settings(employeeID uint) (models.ReminderSettings, error) {
+	var settings models.ReminderSettings
+	err := r.db.Where("employee_id = ?", employeeID).First(&settings).Error
+	if IsNotFound(err) {
+		return models.ReminderSettings{EmployeeID: employeeID, Stages: models.DefaultReminderStages}, nil
+	}
+	return settings, err
+}
+
+func (r *reminderRepository) SaveSettings(settings *models.ReminderSettings) error {
+	settings.UpdatedAt = time.Now()
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "employee_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"stages", "updated_at"}),
+	}).Create(settings).Error
+}
+
+func (r *reminderRepository) Claim(reminder *models.Reminder) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *reminderRepository) Unclaim(reminder models.Reminder) error {
+	return r.db.Delete(&models.Reminder{}, reminder.ID).Error
+}
+
+func (r *reminderRepository) Sent(bookingID, groupID, employeeID uint) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	err := r.db.Where("booking_id = ? AND group_id = ? AND employee_id = ?", bookingID, groupID, employeeID).
+		Order("sent_at, id").Find(&reminders).Error
+	return reminders, err
+}
+
+func (r *reminderRepository) Purge(cutoff time.Time) (int64, error) {
+	result := r.db.Where("start_time < ?", cutoff.UTC()).Delete(&models.Reminder{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,72 @@
+// Package repository wraps database access for rooms, bookings, booking
+// groups, employees, approver groups, delegations, Google tokens, reminders,
+// the email outbox, leases, background jobs and sessions behind interfaces.
+// The gorm implementations work with any dialect, so handlers and services
+// can be tested against an in-memory SQLite database.
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is returned when a record does not exist.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Repositories bundles the repositories of one database connection.
+type Repositories struct {
+	Rooms          RoomRepository
+	Bookings       BookingRepository
+	BookingGroups  BookingGroupRepository
+	Employees      EmployeeRepository
+	ApproverGroups ApproverGroupRepository
+	Delegations    DelegationRepository
+	Tokens         TokenRepository
+	Reminders      ReminderRepository
+	Outbox         OutboxRepository
+	Leases         LeaseRepository
+	Jobs           JobRepository
+	Sessions       SessionRepository
+
+	db *gorm.DB
+}
+
+// New returns gorm-backed repositories using db.
+func New(db *gorm.DB) Repositories {
+	return Repositories{
+		Rooms:          NewRoomRepository(db),
+		Bookings:       NewBookingRepository(db),
+		BookingGroups:  NewBookingGroupRepository(db),
+		Employees:      NewEmployeeRepository(db),
+		ApproverGroups: NewApproverGroupRepository(db),
+		Delegations:    NewDelegationRepository(db),
+		Tokens:         NewTokenRepository(db),
+		Reminders:      NewReminderRepository(db),
+		Outbox:         NewOutboxRepository(db),
+		Leases:         NewLeaseRepository(db),
+		Jobs:           NewJobRepository(db),
+		Sessions:       NewSessionRepository(db),
+		db:             db,
+	}
+}
+
+// Transaction runs fn with repositories sharing one database transaction,
+// which is committed if fn returns nil and rolled back otherwise.
+func (r Repositories) Transaction(fn func(tx Repositories) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(New(tx))
+	})
+}
+
+// WithContext returns the repositories running their queries with ctx, so
+// the queries are cancelled with it and traced as part of its span.
+func (r Repositories) WithContext(ctx context.Context) Repositories {
+	return New(r.db.WithContext(ctx))
+}
+
+// IsNotFound reports whether err means that a record does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"slices"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/listing"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoomRepository interface {
+	Get(id uint) (models.Room, error)
+	// List returns one page of rooms.
+	List(q *listing.Query) ([]models.Room, error)
+	All() ([]models.Room, error)
+	Create(room *models.Room) error
+	Save(room *models.Room) error
+	ApproverGroupExists(id uint) (bool, error)
+	// Lock locks the rooms until the surrounding transaction ends, so that
+	// bookings of the same room are checked and written one at a time.
+	Lock(ids ...uint) error
+}
+
+type roomRepository struct {
+	db *gorm.DB
+}
+
+func NewRoomRepository(db *gorm.DB) RoomRepository {
+	return &roomRepository{db: db}
+}
+
+func (r *roomRepository) Get(id uint) (models.Room, error) {
+	var room models.Room
+	err := r.db.First(&room, id).Error
+	return room, err
+}
+
+func (r *roomRepository) List(q *listing.Query) ([]models.Room, error) {
+	query, err := q.Apply(r.db)
+	if err != nil {
+		return nil, err
+	}
+	var rooms []models.Room
+	err = query.Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *roomRepository) All() ([]models.Room, error) {
+	var rooms []models.Room
+	err := r.db.Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *roomRepository) Create(room *models.Room) error {
+	return r.db.Create(room).Error
+}
+
+func (r *roomRepository) Save(room *models.Room) error {
+	return r.db.Save(room).Error
+}
+
+func (r *roomRepository) ApproverGroupExists(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ApproverGroup{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// Lock takes the rooms in ascending ID order so that two transactions
+// locking overlapping sets of rooms cannot deadlock. SQLite has no row locks
+// and drops the clause; it serializes writers anyway.
+func (r *roomRepository) Lock(ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	var rooms []models.Room
+	return r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").Order("id").Find(&rooms, slices.Compact(ids)).Error
+}
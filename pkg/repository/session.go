@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SessionRepository stores sign-in sessions.
+type SessionRepository interface {
+	Get(id string) (models.Session, error)
+	// Save creates the session or replaces the stored one.
+	Save(session *models.Session) error
+	Delete(id string) error
+	// ListActive returns the employee's sessions that have not expired by
+	// now, most recently used first.
+	ListActive(employeeID uint, now time.Time) ([]models.Session, error)
+	// DeleteByEmployee deletes the employee's sessions except the one with
+	// the ID except, if any.
+	DeleteByEmployee(employeeID uint, except string) (int64, error)
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// Get uses Find rather than First: cookies of ended sessions are common and
+// not worth a "record not found" line in the log.
+func (r *sessionRepository) Get(id string) (models.Session, error) {
+	var session models.Session
+	result := r.db.Where("id = ?", id).Limit(1).Find(&session)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return session, ErrNotFound
+	}
+	return session, result.Error
+}
+
+func (r *sessionRepository) Save(session *models.Session) error {
+	session.CreatedAt = session.CreatedAt.UTC()
+	session.LastSeenAt = session.LastSeenAt.UTC()
+	session.ExpiresAt = session.ExpiresAt.UTC()
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(session).Error
+}
+
+func (r *sessionRepository) Delete(id string) error {
+	return r.db.Where("id = ?", id).Delete(&models.Session{}).Error
+}
+
+func (r *sessionRepository) ListActive(employeeID uint, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("employee_id = ? AND expires_at > ?", employeeID, now.UTC()).
+		Order("last_seen_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *sessionRepository) DeleteByEmployee(employeeID uint, except string) (int64, error) {
+	result := r.db.Where("employee_id = ? AND id <> ?", employeeID, except).Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *sessionRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at <= ?", now.UTC()).Delete(&models.Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"gorm.io/gorm"
+)
+
+// TokenRepository stores the Google OAuth tokens of employees who linked
+// their calendar.
+type TokenRepository interface {
+	Get(employeeID uint) (models.GoogleToken, error)
+	// Save stores the employee's token, replacing any previous one.
+	Save(token *models.GoogleToken) error
+	Delete(employeeID uint) error
+	// Expiring returns the tokens that can be refreshed and expire before
+	// the deadline.
+	Expiring(before time.Time) ([]models.GoogleToken, error)
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) Get(employeeID uint) (models.GoogleToken, error) {
+	var token models.GoogleToken
+	err := r.db.Where("employee_id = ?", employeeID).Order("id DESC").First(&token).Error
+	return token, err
+}
+
+func (r *tokenRepository) Save(token *models.GoogleToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("employee_id = ?", token.EmployeeID).Delete(&models.GoogleToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *tokenRepository) Delete(employeeID uint) error {
+	return r.db.Unscoped().Where("employee_id = ?", employeeID).Delete(&models.GoogleToken{}).Error
+}
+
+func (r *tokenRepository) Expiring(before time.Time) ([]models.GoogleToken, error) {
+	var tokens []models.GoogleToken
+	err := r.db.Where("refresh_token <> ? AND expiry < ?", "", before.UTC()).Find(&tokens).Error
+	return tokens, err
+}
@@ -1,31 +1,81 @@
 package routes
 
 import (
+	"net/http"
+
 	"github.com/gorilla/mux"
 	"github.com/koushikidey/go-meetingroombook/pkg/controllers"
 )
 
-func RegisterMeetingRoomRoutes(router *mux.Router) {
-	router.HandleFunc("/register", controllers.Register).Methods("POST")
-	router.HandleFunc("/login", controllers.Login).Methods("POST")
-	router.HandleFunc("/logout", controllers.Logout).Methods("POST")
+// endpoint is a handler method, taken as a method expression so that every
+// request can be served by a handler bound to the request context.
+type endpoint func(*controllers.Handler, http.ResponseWriter, *http.Request)
+
+func RegisterMeetingRoomRoutes(router *mux.Router, h *controllers.Handler) {
+	handle := func(path string, fn endpoint) *mux.Route {
+		return router.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			fn(h.WithContext(r.Context()), w, r)
+		})
+	}
+
+	handle("/register", (*controllers.Handler).Register).Methods("POST")
+	handle("/login", (*controllers.Handler).Login).Methods("POST")
+	handle("/logout", (*controllers.Handler).Logout).Methods("POST")
+	handle("/me", (*controllers.Handler).GetMe).Methods("GET")
+	handle("/session-status", (*controllers.Handler).GetMe).Methods("GET")
+	handle("/csrf-token", (*controllers.Handler).GetCSRFToken).Methods("GET")
+	handle("/sessions", (*controllers.Handler).GetSessions).Methods("GET")
+	handle("/sessions/{id}", (*controllers.Handler).DeleteSession).Methods("DELETE")
 
-	router.HandleFunc("/employees", controllers.GetEmployees).Methods("GET")
-	router.HandleFunc("/employees/{id}", controllers.GetEmployeeByIDWithCache).Methods("GET")
+	handle("/employees", (*controllers.Handler).GetEmployees).Methods("GET")
+	handle("/employees/{id}", (*controllers.Handler).GetEmployeeByIDWithCache).Methods("GET")
 	//router.HandleFunc("/employees/{id}", controllers.GetEmployee).Methods("GET")
-	router.HandleFunc("/employees/{id}", controllers.UpdateEmployees).Methods("PUT")
+	handle("/employees/{id}", (*controllers.Handler).UpdateEmployees).Methods("PUT")
+
+	handle("/rooms", (*controllers.Handler).CreateRoom).Methods("POST")
+	handle("/rooms", (*controllers.Handler).GetRooms).Methods("GET")
+	handle("/rooms/suggestions", (*controllers.Handler).SuggestRooms).Methods("POST")
+	handle("/rooms/{id}", (*controllers.Handler).UpdateRoom).Methods("PUT")
+
+	handle("/bookings", (*controllers.Handler).CreateBooking).Methods("POST")
+	handle("/bookings", (*controllers.Handler).GetBookings).Methods("GET")
+	handle("/bookings/{id}", (*controllers.Handler).GetBooking).Methods("GET")
+	handle("/bookings/{id}", (*controllers.Handler).UpdateBooking).Methods("PUT")
+	handle("/bookings/{id}", (*controllers.Handler).DeleteBooking).Methods("DELETE")
+	handle("/bookings/{id}/confirm", (*controllers.Handler).ConfirmBooking).Methods("POST")
+	handle("/bookings/{id}/status", (*controllers.Handler).TransitionBooking).Methods("POST")
+	handle("/bookings/{id}/history", (*controllers.Handler).GetBookingHistory).Methods("GET")
+	handle("/bookings/{id}/approve", (*controllers.Handler).ApprovalPage).Methods("GET")
+	handle("/bookings/{id}/approve", (*controllers.Handler).ApproveBooking).Methods("POST")
+	handle("/bookings/{id}/reject", (*controllers.Handler).ApprovalPage).Methods("GET")
+	handle("/bookings/{id}/reject", (*controllers.Handler).RejectBooking).Methods("POST")
+
+	handle("/booking-groups", (*controllers.Handler).CreateBookingGroup).Methods("POST")
+	handle("/booking-groups/{id}", (*controllers.Handler).GetBookingGroup).Methods("GET")
+	handle("/booking-groups/{id}", (*controllers.Handler).UpdateBookingGroup).Methods("PUT")
+	handle("/booking-groups/{id}", (*controllers.Handler).DeleteBookingGroup).Methods("DELETE")
+
+	handle("/bookings/{id}/transfer", (*controllers.Handler).TransferBooking).Methods("POST")
+	handle("/employees/{id}/bookings/transfer", (*controllers.Handler).TransferEmployeeBookings).Methods("POST")
+	handle("/employees/{id}/deactivate", (*controllers.Handler).DeactivateEmployee).Methods("POST")
+
+	handle("/delegations", (*controllers.Handler).CreateDelegation).Methods("POST")
+	handle("/delegations", (*controllers.Handler).GetDelegations).Methods("GET")
+	handle("/delegations/{id}", (*controllers.Handler).DeleteDelegation).Methods("DELETE")
+
+	handle("/reminder-settings", (*controllers.Handler).GetReminderSettings).Methods("GET")
+	handle("/reminder-settings", (*controllers.Handler).UpdateReminderSettings).Methods("PUT")
 
-	router.HandleFunc("/rooms", controllers.CreateRoom).Methods("POST")
-	router.HandleFunc("/rooms", controllers.GetRooms).Methods("GET")
-	router.HandleFunc("/rooms/{id}", controllers.UpdateRoom).Methods("PUT")
+	handle("/approver-groups", (*controllers.Handler).CreateApproverGroup).Methods("POST")
+	handle("/approver-groups", (*controllers.Handler).GetApproverGroups).Methods("GET")
+	handle("/approvals", (*controllers.Handler).GetPendingApprovals).Methods("GET")
 
-	router.HandleFunc("/bookings", controllers.CreateBooking).Methods("POST")
-	router.HandleFunc("/bookings", controllers.GetBookings).Methods("GET")
-	router.HandleFunc("/bookings/{id}", controllers.GetBooking).Methods("GET")
-	router.HandleFunc("/bookings/{id}", controllers.UpdateBooking).Methods("PUT")
-	router.HandleFunc("/bookings/{id}", controllers.DeleteBooking).Methods("DELETE")
+	handle("/admin/jobs", (*controllers.Handler).GetJobs).Methods("GET")
+	handle("/admin/jobs/{name}/run", (*controllers.Handler).RunJob).Methods("POST")
+	handle("/admin/jobs/{name}/pause", (*controllers.Handler).PauseJob).Methods("POST")
+	handle("/admin/jobs/{name}/resume", (*controllers.Handler).ResumeJob).Methods("POST")
 
-	router.HandleFunc("/google/login", controllers.GoogleLogin).Methods("GET")
-	router.HandleFunc("/oauth2callback", controllers.GoogleCallback).Methods("GET")
+	handle("/google/login", (*controllers.Handler).GoogleLogin).Methods("GET")
+	handle("/oauth2callback", (*controllers.Handler).GoogleCallback).Methods("GET")
 
 }
\ No newline at end of file
@@ -0,0 +1,344 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/controllers"
+	"github.com/koushikidey/go-meetingroombook/pkg/csrf"
+	"github.com/koushikidey/go-meetingroombook/pkg/googleapi"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracing"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/api/calendar/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type recordingMailer struct {
+	mu       sync.Mutex
+	subjects []string
+}
+
+func (m *recordingMailer) Send(_ context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subjects = append(m.subjects, subject)
+	return nil
+}
+
+type unlinkedCalendar struct{}
+
+func (unlinkedCalendar) InsertEvent(context.Context, uint, *calendar.Event) (string, error) {
+	return "", googleapi.ErrNotLinked
+}
+
+func (unlinkedCalendar) DeleteEvent(context.Context, uint, string) error {
+	return googleapi.ErrNotLinked
+}
+
+func (unlinkedCalendar) FreeBusy(context.Context, uint, time.Time, time.Time) ([]googleapi.BusyPeriod, error) {
+	return nil, googleapi.ErrNotLinked
+}
+
+func (unlinkedCalendar) RevokeToken(context.Context, uint) error {
+	return nil
+}
+
+func (unlinkedCalendar) RefreshTokens(context.Context, time.Time) (int, error) {
+	return 0, nil
+}
+
+// newTestServer serves the whole API from an in-memory SQLite database,
+// traced like in production and behind the given handler middleware.
+func newTestServer(t *testing.T, middleware ...func(*controllers.Handler) mux.MiddlewareFunc) (*httptest.Server, *http.Client) {
+	return newTestServerOn(t, newTestDB(t), middleware...)
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	_, err = migrations.Up(db)
+	assert.NoError(t, err)
+	assert.NoError(t, tracing.Instrument(db))
+	return db
+}
+
+// newTestServerOn is newTestServer on the given database, for tests that
+// need to set up what the API cannot, like administrators.
+func newTestServerOn(t *testing.T, db *gorm.DB, middleware ...func(*controllers.Handler) mux.MiddlewareFunc) (*httptest.Server, *http.Client) {
+	cfg := config.Default()
+	cfg.Session.Key = "test-session-key-of-at-least-32-bytes"
+	cfg.Approval.Secret = "test-approval-secret"
+	deps := controllers.NewDependencies(db, cfg)
+	deps.Mailer = &recordingMailer{}
+	deps.Calendar = unlinkedCalendar{}
+	router := mux.NewRouter()
+	router.Use(otelmux.Middleware(tracing.ServiceName))
+	h := controllers.NewHandler(deps)
+	for _, m := range middleware {
+		router.Use(m(h))
+	}
+	RegisterMeetingRoomRoutes(router, h)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	jar, _ := cookiejar.New(nil)
+	return server, &http.Client{Jar: jar}
+}
+
+func call(t *testing.T, client *http.Client, method, url string, body, out interface{}) int {
+	payload, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	if out != nil {
+		json.NewDecoder(resp.Body).Decode(out)
+	}
+	return resp.StatusCode
+}
+
+func TestBookingLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	server, client := newTestServerOn(t, db)
+
+	status := call(t, client, "POST", server.URL+"/register", models.EmployeeDTO{
+		Name: "Alice", Email: "alice@example.com", Password: "correct-horse",
+	}, nil)
+	assert.Equal(t, http.StatusCreated, status)
+	status = call(t, client, "POST", server.URL+"/login", models.CredentialsDTO{
+		Email: "alice@example.com", Password: "correct-horse",
+	}, nil)
+	assert.Equal(t, http.StatusOK, status)
+	// Only administrators set up rooms.
+	assert.NoError(t, config.PromoteAdmins(db, []string{"alice@example.com"}))
+
+	capacity := 4
+	var room models.RoomResponse
+	status = call(t, client, "POST", server.URL+"/rooms", models.RoomDTO{Name: "Focus", Capacity: &capacity}, &room)
+	assert.Equal(t, http.StatusCreated, status)
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	request := models.BookingDTO{RoomID: room.ID, StartTime: start, EndTime: start.Add(time.Hour), NumAttendees: 3}
+	var booking models.BookingResponse
+	status = call(t, client, "POST", server.URL+"/bookings", request, &booking)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, models.BookingConfirmed, booking.Status)
+
+	var conflict apierror.Response
+	request.StartTime = start.Add(30 * time.Minute)
+	request.EndTime = start.Add(90 * time.Minute)
+	status = call(t, client, "POST", server.URL+"/bookings", request, &conflict)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, apierror.CodeBookingConflict, conflict.Error.Code)
+
+	var tooMany apierror.Response
+	request.StartTime = start.Add(2 * time.Hour)
+	request.EndTime = start.Add(3 * time.Hour)
+	request.NumAttendees = 5
+	status = call(t, client, "POST", server.URL+"/bookings", request, &tooMany)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, apierror.CodeCapacityExceeded, tooMany.Error.Code)
+
+	var fetched models.BookingResponse
+	url := fmt.Sprintf("%s/bookings/%d", server.URL, booking.ID)
+	status = call(t, client, "GET", url, nil, &fetched)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Focus", fetched.Room.Name)
+
+	status = call(t, client, "DELETE", url, models.BookingTransitionDTO{Reason: "plans changed"}, nil)
+	assert.Equal(t, http.StatusNoContent, status)
+
+	var history []models.BookingHistoryResponse
+	status = call(t, client, "GET", url+"/history", nil, &history)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Len(t, history, 2)
+	assert.Equal(t, models.BookingCancelled, history[1].ToStatus)
+}
+
+func TestBookingRequiresLogin(t *testing.T) {
+	server, client := newTestServer(t)
+
+	var response apierror.Response
+	status := call(t, client, "GET", server.URL+"/bookings/1", nil, &response)
+	assert.Equal(t, http.StatusUnauthorized, status)
+	assert.Equal(t, apierror.CodeUnauthorized, response.Error.Code)
+}
+
+func TestQueriesAreTracedWithTheirRequest(t *testing.T) {
+	spans := tracingtest.Record(t)
+	server, client := newTestServer(t)
+	credentials := models.CredentialsDTO{Email: "alice@example.com", Password: "correct-horse"}
+	call(t, client, "POST", server.URL+"/register", models.EmployeeDTO{
+		Name: "Alice", Email: credentials.Email, Password: credentials.Password,
+	}, nil)
+	assert.Equal(t, http.StatusOK, call(t, client, "POST", server.URL+"/login", credentials, nil))
+
+	status := call(t, client, "GET", server.URL+"/rooms", nil, nil)
+	assert.Equal(t, http.StatusOK, status)
+
+	ended := spans.GetSpans()
+	request, ok := tracingtest.Find(ended, "/rooms")
+	if !assert.True(t, ok, "no span for the request in %v", tracingtest.Names(ended)) {
+		return
+	}
+	query, ok := tracingtest.Find(ended, "select rooms")
+	assert.True(t, ok, "no span for the query in %v", tracingtest.Names(ended))
+	assert.Equal(t, trace.SpanKindServer, request.SpanKind)
+	assert.Equal(t, request.SpanContext.TraceID(), query.SpanContext.TraceID())
+	assert.Equal(t, request.SpanContext.SpanID(), query.Parent.SpanID())
+}
+
+func TestSessionsCanBeListedAndRevoked(t *testing.T) {
+	server, laptop := newTestServer(t)
+	jar, _ := cookiejar.New(nil)
+	phone := &http.Client{Jar: jar}
+	credentials := models.CredentialsDTO{Email: "alice@example.com", Password: "correct-horse"}
+	call(t, laptop, "POST", server.URL+"/register", models.EmployeeDTO{
+		Name: "Alice", Email: credentials.Email, Password: credentials.Password,
+	}, nil)
+	assert.Equal(t, http.StatusOK, call(t, laptop, "POST", server.URL+"/login", credentials, nil))
+	assert.Equal(t, http.StatusOK, call(t, phone, "POST", server.URL+"/login", credentials, nil))
+
+	var sessions []models.SessionResponse
+	assert.Equal(t, http.StatusOK, call(t, laptop, "GET", server.URL+"/sessions", nil, &sessions))
+	if !assert.Len(t, sessions, 2) {
+		return
+	}
+	other := sessions[0]
+	if other.Current {
+		other = sessions[1]
+	}
+	assert.False(t, other.Current)
+	assert.Equal(t, "127.0.0.1", other.IPAddress)
+
+	assert.Equal(t, http.StatusNoContent, call(t, laptop, "DELETE", server.URL+"/sessions/"+other.ID, nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, call(t, phone, "GET", server.URL+"/sessions", nil, nil))
+	assert.Equal(t, http.StatusNotFound, call(t, laptop, "DELETE", server.URL+"/sessions/"+other.ID, nil, nil))
+
+	// Changing the password signs out everywhere else.
+	assert.Equal(t, http.StatusOK, call(t, phone, "POST", server.URL+"/login", credentials, nil))
+	status := call(t, laptop, "PUT", server.URL+"/employees/1", models.EmployeeDTO{
+		Name: "Alice", Email: credentials.Email, Password: "battery-staple",
+	}, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, http.StatusUnauthorized, call(t, phone, "GET", server.URL+"/sessions", nil, nil))
+	assert.Equal(t, http.StatusOK, call(t, laptop, "GET", server.URL+"/sessions", nil, nil))
+
+	// A cookie kept from before logging out is refused.
+	kept, _ := cookiejar.New(nil)
+	u, _ := url.Parse(server.URL)
+	kept.SetCookies(u, laptop.Jar.Cookies(u))
+	assert.Equal(t, http.StatusOK, call(t, laptop, "POST", server.URL+"/logout", nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, call(t, &http.Client{Jar: kept}, "GET", server.URL+"/sessions", nil, nil))
+}
+
+func TestMeDescribesTheLoggedInEmployee(t *testing.T) {
+	server, client := newTestServer(t)
+	assert.Equal(t, http.StatusUnauthorized, call(t, client, "GET", server.URL+"/me", nil, nil))
+
+	credentials := models.CredentialsDTO{Email: "alice@example.com", Password: "correct-horse"}
+	call(t, client, "POST", server.URL+"/register", models.EmployeeDTO{
+		Name: "Alice", Email: credentials.Email, Password: credentials.Password,
+	}, nil)
+	assert.Equal(t, http.StatusOK, call(t, client, "POST", server.URL+"/login", credentials, nil))
+
+	var me models.MeResponse
+	assert.Equal(t, http.StatusOK, call(t, client, "GET", server.URL+"/me", nil, &me))
+	assert.Equal(t, uint(1), me.EmployeeID)
+	assert.Equal(t, "alice@example.com", me.Employee.Email)
+	assert.Equal(t, []string{"employee"}, me.Roles)
+	assert.False(t, me.GoogleCalendarLinked)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), me.SessionExpiresAt, time.Minute)
+
+	var status models.MeResponse
+	assert.Equal(t, http.StatusOK, call(t, client, "GET", server.URL+"/session-status", nil, &status))
+	assert.Equal(t, me.EmployeeID, status.EmployeeID)
+
+	assert.Equal(t, http.StatusOK, call(t, client, "POST", server.URL+"/logout", nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, call(t, client, "GET", server.URL+"/session-status", nil, nil))
+}
+
+func TestCrossSiteRequestsAreRejected(t *testing.T) {
+	server, client := newTestServer(t, (*controllers.Handler).CSRFProtection)
+	credentials := models.CredentialsDTO{Email: "alice@example.com", Password: "correct-horse"}
+	assert.Equal(t, http.StatusCreated, call(t, client, "POST", server.URL+"/register", models.EmployeeDTO{
+		Name: "Alice", Email: credentials.Email, Password: credentials.Password,
+	}, nil))
+	assert.Equal(t, http.StatusOK, call(t, client, "POST", server.URL+"/login", credentials, nil))
+
+	// A page on another site can make the browser send the cookie, but not
+	// the token.
+	settings := models.ReminderSettingsDTO{Stages: []models.ReminderStage{}}
+	var failure apierror.Response
+	assert.Equal(t, http.StatusForbidden, call(t, client, "PUT", server.URL+"/reminder-settings", settings, &failure))
+	assert.Equal(t, "Missing or invalid CSRF token", failure.Error.Message)
+	assert.Equal(t, http.StatusForbidden, callWithToken(t, client, "PUT", server.URL+"/reminder-settings", "forged", settings))
+	assert.Equal(t, http.StatusForbidden, call(t, client, "DELETE", server.URL+"/sessions/1", nil, nil))
+
+	var token map[string]string
+	assert.Equal(t, http.StatusOK, call(t, client, "GET", server.URL+"/csrf-token", nil, &token))
+	assert.NotEmpty(t, token["csrf_token"])
+	var again map[string]string
+	call(t, client, "GET", server.URL+"/csrf-token", nil, &again)
+	assert.Equal(t, token, again, "the token lasts as long as the session")
+	assert.Equal(t, http.StatusOK, callWithToken(t, client, "PUT", server.URL+"/reminder-settings", token["csrf_token"], settings))
+
+	// Clients without the session cookie have nothing to forge.
+	anonymous := &http.Client{}
+	assert.Equal(t, http.StatusUnauthorized, call(t, anonymous, "PUT", server.URL+"/reminder-settings", settings, nil))
+}
+
+func TestLoginReplacesTheCSRFToken(t *testing.T) {
+	server, client := newTestServer(t, (*controllers.Handler).CSRFProtection)
+	credentials := models.CredentialsDTO{Email: "alice@example.com", Password: "correct-horse"}
+	assert.Equal(t, http.StatusCreated, call(t, client, "POST", server.URL+"/register", models.EmployeeDTO{
+		Name: "Alice", Email: credentials.Email, Password: credentials.Password,
+	}, nil))
+
+	// The token of the anonymous session, which an attacker could have
+	// planted along with its cookie, only lasts until logging in.
+	var before map[string]string
+	assert.Equal(t, http.StatusOK, call(t, client, "GET", server.URL+"/csrf-token", nil, &before))
+	assert.Equal(t, http.StatusOK, callWithToken(t, client, "POST", server.URL+"/login", before["csrf_token"], credentials))
+	settings := models.ReminderSettingsDTO{Stages: []models.ReminderStage{}}
+	assert.Equal(t, http.StatusForbidden, callWithToken(t, client, "PUT", server.URL+"/reminder-settings", before["csrf_token"], settings))
+
+	var after map[string]string
+	assert.Equal(t, http.StatusOK, call(t, client, "GET", server.URL+"/csrf-token", nil, &after))
+	assert.NotEqual(t, before["csrf_token"], after["csrf_token"])
+	assert.Equal(t, http.StatusOK, callWithToken(t, client, "PUT", server.URL+"/reminder-settings", after["csrf_token"], settings))
+}
+
+func callWithToken(t *testing.T, client *http.Client, method, url, token string, body interface{}) int {
+	payload, err := json.Marshal(body)
+	assert.NoError(t, err)
+	req, err := http.NewRequest(method, url, bytes.NewReader(payload))
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(csrf.HeaderName, token)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
@@ -0,0 +1,575 @@
+// Package services holds the business rules of the application on top of
+// the repositories. Services return models and *Error values; turning them
+// into HTTP responses and notifications is left to the handlers.
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+)
+
+const (
+	defaultHoldDuration = 15 * time.Minute
+	maxHoldDuration     = time.Hour
+	// checkInAllowance is how long before the start attendees may check in.
+	checkInAllowance = 15 * time.Minute
+)
+
+// BookingService decides who may act on which bookings and enforces room
+// capacity, availability, holds, approvals and the status lifecycle.
+type BookingService struct {
+	repos       repository.Repositories
+	rooms       repository.RoomRepository
+	bookings    repository.BookingRepository
+	groups      repository.BookingGroupRepository
+	employees   repository.EmployeeRepository
+	delegations repository.DelegationRepository
+	// approvalTimeout is how long approvers have to answer a request.
+	approvalTimeout time.Duration
+	// noShowGrace is how long after the start nobody may have checked in
+	// before a booking counts as a no-show.
+	noShowGrace time.Duration
+}
+
+func NewBookingService(repos repository.Repositories, approvalTimeout, noShowGrace time.Duration) *BookingService {
+	return &BookingService{
+		repos:           repos,
+		rooms:           repos.Rooms,
+		bookings:        repos.Bookings,
+		groups:          repos.BookingGroups,
+		employees:       repos.Employees,
+		delegations:     repos.Delegations,
+		approvalTimeout: approvalTimeout,
+		noShowGrace:     noShowGrace,
+	}
+}
+
+// WithContext returns the service working on the repositories bound to ctx;
+// see repository.Repositories.WithContext.
+func (s *BookingService) WithContext(ctx context.Context) *BookingService {
+	return NewBookingService(s.repos.WithContext(ctx), s.approvalTimeout, s.noShowGrace)
+}
+
+// CanActFor reports whether the actor may perform the action on bookings
+// organized by the organizer, either as the organizer or as their delegate.
+func (s *BookingService) CanActFor(actorID, organizerID uint, action models.DelegateAction) bool {
+	if actorID == organizerID {
+		return true
+	}
+	delegation, err := s.delegations.Between(organizerID, actorID)
+	if err != nil {
+		return false
+	}
+	return delegation.Allows(action)
+}
+
+// CanView reports whether the actor may see a booking: its organizer, the
+// employee who created it, or any delegate of the organizer.
+func (s *BookingService) CanView(actorID uint, booking models.Booking) bool {
+	if actorID == booking.EmployeeID || actorID == booking.CreatedByID {
+		return true
+	}
+	_, err := s.delegations.Between(booking.EmployeeID, actorID)
+	return err == nil
+}
+
+// ResolveOrganizer returns the organizer of a new booking: the requested
+// employee if the actor may book for them, otherwise the actor.
+func (s *BookingService) ResolveOrganizer(actorID, requestedID uint) (uint, bool) {
+	if requestedID == 0 || requestedID == actorID {
+		return actorID, true
+	}
+	return requestedID, s.CanActFor(actorID, requestedID, models.DelegateBook)
+}
+
+// IsAdmin reports whether the employee is an administrator.
+func (s *BookingService) IsAdmin(employeeID uint) bool {
+	employee, err := s.employees.Get(employeeID)
+	return err == nil && employee.IsAdmin()
+}
+
+// Attendees looks up the employees invited to a booking.
+func (s *BookingService) Attendees(ids []uint) ([]models.Employee, error) {
+	attendees, err := s.employees.FindAll(ids)
+	if repository.IsNotFound(err) {
+		return nil, invalidField("attendee_ids", validation.CodeNotFound, "one or more attendees do not exist")
+	}
+	return attendees, err
+}
+
+// BookableRoom loads the room of a booking and checks that the attendees fit.
+func (s *BookingService) BookableRoom(roomID uint, attendees int) (models.Room, error) {
+	room, err := s.rooms.Get(roomID)
+	if repository.IsNotFound(err) {
+		return room, invalidField("room_id", validation.CodeNotFound, "room does not exist")
+	}
+	if err != nil {
+		return room, err
+	}
+	if room.Capacity == nil {
+		return room, nil
+	}
+	if _, err := utils.IsCapacityExceeding(attendees, *room.Capacity); err != nil {
+		return room, &Error{
+			Kind:    KindInvalid,
+			Code:    apierror.CodeCapacityExceeded,
+			Message: "Capacity Exceeded",
+			Details: validation.Errors{{
+				Field:   "num_attendees",
+				Code:    validation.CodeOutOfRange,
+				Message: fmt.Sprintf("room holds at most %d people", *room.Capacity),
+			}},
+		}
+	}
+	return room, nil
+}
+
+// checkAvailable locks the room and fails if another booking holds it during
+// the slot. It must run in the transaction that writes the booking, so that
+// no concurrent request can take the slot in between.
+func checkAvailable(repos repository.Repositories, roomID uint, start, end time.Time, excludeID uint) error {
+	if err := repos.Rooms.Lock(roomID); err != nil {
+		return err
+	}
+	overlapping, err := repos.Bookings.Overlapping(roomID, start, end, excludeID)
+	if err != nil {
+		return err
+	}
+	if len(overlapping) > 0 {
+		return conflict(apierror.CodeBookingConflict, "Booking time conflicts with an existing booking")
+	}
+	return nil
+}
+
+// load fetches a booking with its room and organizer.
+func (s *BookingService) load(id uint) (models.Booking, error) {
+	booking, err := s.bookings.Get(id, "Room", "Employee")
+	if repository.IsNotFound(err) {
+		return booking, notFound("Booking not found")
+	}
+	return booking, err
+}
+
+// Get returns a booking the actor may see.
+func (s *BookingService) Get(actorID, id uint) (models.Booking, error) {
+	booking, err := s.load(id)
+	if err != nil {
+		return booking, err
+	}
+	if !s.CanView(actorID, booking) {
+		return booking, forbidden("Forbidden")
+	}
+	return booking, nil
+}
+
+// History returns the status history of a booking the actor may see, oldest
+// first.
+func (s *BookingService) History(actorID, id uint) ([]models.BookingTransition, error) {
+	if _, err := s.Get(actorID, id); err != nil {
+		return nil, err
+	}
+	return s.bookings.History(id)
+}
+
+// Create books a room, or holds it when input.Hold is set. Rooms that
+// require approval start out pending.
+func (s *BookingService) Create(actorID uint, input models.BookingDTO) (booking models.Booking, err error) {
+	defer func() { recordCreation("single", err) }()
+	booking = models.Booking{
+		RoomID:       input.RoomID,
+		StartTime:    input.StartTime,
+		EndTime:      input.EndTime,
+		NumAttendees: input.NumAttendees,
+		CreatedByID:  actorID,
+	}
+	if booking.Attendees, err = s.Attendees(input.AttendeeIDs); err != nil {
+		return booking, err
+	}
+	organizerID, allowed := s.ResolveOrganizer(actorID, input.EmployeeID)
+	if !allowed {
+		return booking, forbidden("You are not allowed to book on behalf of this employee")
+	}
+	booking.EmployeeID = organizerID
+
+	room, err := s.BookableRoom(booking.RoomID, booking.NumAttendees)
+	if err != nil {
+		return booking, err
+	}
+
+	reason := "booking created"
+	if input.Hold {
+		expiry, err := HoldDeadline(booking.StartTime, input.HoldMinutes)
+		if err != nil {
+			return booking, invalidField("hold_minutes", validation.CodeOutOfRange, err.Error())
+		}
+		booking.Status = models.BookingTentative
+		booking.ExpiresAt = &expiry
+		reason = "hold placed"
+	} else {
+		s.ApplyApprovalState(&booking, room)
+	}
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		if err := checkAvailable(tx, room.ID, booking.StartTime, booking.EndTime, 0); err != nil {
+			return err
+		}
+		return tx.Bookings.Create(&booking, &actorID, reason)
+	})
+	if err != nil {
+		return booking, err
+	}
+
+	booking.Room = room
+	booking.Employee, _ = s.employees.Get(organizerID)
+	return booking, nil
+}
+
+// Update moves a single-room booking to a new room or slot. It also returns
+// the booking as it was, so its calendar event can be replaced.
+func (s *BookingService) Update(actorID, id uint, input models.BookingUpdateDTO) (models.Booking, models.Booking, error) {
+	existing, err := s.load(id)
+	if err != nil {
+		return existing, existing, err
+	}
+	previous := existing
+	if !s.CanActFor(actorID, existing.EmployeeID, models.DelegateEdit) {
+		return existing, previous, forbidden("Forbidden")
+	}
+	if existing.GroupID != nil {
+		return existing, previous, conflict(apierror.CodeConflict, "Booking is part of a group, update it via /booking-groups/%d", *existing.GroupID)
+	}
+	if !existing.Status.IsActive() || existing.Status == models.BookingCheckedIn {
+		return existing, previous, conflict(apierror.CodeConflict, "A %s booking can no longer be changed", existing.Status)
+	}
+
+	var attendees *[]models.Employee
+	if input.AttendeeIDs != nil {
+		list, err := s.Attendees(*input.AttendeeIDs)
+		if err != nil {
+			return existing, previous, err
+		}
+		attendees = &list
+	}
+	room, err := s.BookableRoom(input.RoomID, input.NumAttendees)
+	if err != nil {
+		return existing, previous, err
+	}
+
+	existing.RoomID = room.ID
+	existing.Room = room
+	existing.StartTime = input.StartTime
+	existing.EndTime = input.EndTime
+	existing.NumAttendees = input.NumAttendees
+	existing.CalendarID = ""
+	// Holds stay tentative until they are explicitly confirmed.
+	if previous.Status != models.BookingTentative {
+		s.ApplyApprovalState(&existing, room)
+	}
+	if existing.Status != previous.Status {
+		if err := models.ValidateTransition(previous.Status, existing.Status); err != nil {
+			return existing, previous, err
+		}
+	}
+
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		if err := checkAvailable(tx, room.ID, input.StartTime, input.EndTime, existing.ID); err != nil {
+			if _, ok := err.(*Error); ok {
+				err = conflict(apierror.CodeBookingConflict, "Updated time conflicts with another booking")
+			}
+			return err
+		}
+		return tx.Bookings.Update(&existing, previous.Status, attendees, &actorID, "booking updated")
+	})
+	if err != nil {
+		return existing, previous, err
+	}
+	return existing, previous, nil
+}
+
+// Cancel cancels a single-room booking. The booking is kept with status
+// cancelled.
+func (s *BookingService) Cancel(actorID, id uint, reason string) (models.Booking, error) {
+	booking, err := s.load(id)
+	if err != nil {
+		return booking, err
+	}
+	if !s.CanActFor(actorID, booking.EmployeeID, models.DelegateCancel) {
+		return booking, forbidden("Forbidden")
+	}
+	if booking.GroupID != nil {
+		return booking, conflict(apierror.CodeConflict, "Booking is part of a group, cancel it via /booking-groups/%d", *booking.GroupID)
+	}
+	err = s.bookings.Transition(&booking, models.BookingCancelled, &actorID, reason)
+	return booking, err
+}
+
+// ChangeStatus moves a booking through its lifecycle on behalf of the
+// organizer or a delegate. Approval decisions are not accepted here so they
+// can be checked against the room's approver group.
+func (s *BookingService) ChangeStatus(actorID, id uint, input models.BookingTransitionDTO) (models.Booking, error) {
+	if input.Status == models.BookingConfirmed || input.Status == models.BookingRejected ||
+		input.Status == models.BookingPendingApproval {
+		return models.Booking{}, invalidField("status", validation.CodeInvalid, "use the approval endpoints to change this status")
+	}
+
+	booking, err := s.load(id)
+	if err != nil {
+		return booking, err
+	}
+	action := models.DelegateEdit
+	if input.Status == models.BookingCancelled {
+		action = models.DelegateCancel
+	}
+	if !s.CanActFor(actorID, booking.EmployeeID, action) {
+		return booking, forbidden("Forbidden")
+	}
+	if booking.GroupID != nil && input.Status == models.BookingCancelled {
+		return booking, conflict(apierror.CodeConflict, "Booking is part of a group, cancel it via /booking-groups/%d", *booking.GroupID)
+	}
+	if err := s.checkStatusTime(booking, input.Status, time.Now()); err != nil {
+		return booking, err
+	}
+	err = s.bookings.Transition(&booking, input.Status, &actorID, input.Reason)
+	return booking, err
+}
+
+// checkStatusTime refuses statuses that only make sense once the meeting is
+// due: attendees check in from shortly before the start until the end, a
+// meeting completes once it started and is a no-show once nobody checked
+// in within the grace period.
+func (s *BookingService) checkStatusTime(booking models.Booking, status models.BookingStatus, now time.Time) error {
+	switch status {
+	case models.BookingCheckedIn:
+		if now.Before(booking.StartTime.Add(-checkInAllowance)) {
+			return conflict(apierror.CodeInvalidTransition, "Check-in opens %s before the start", checkInAllowance)
+		}
+		if !now.Before(booking.EndTime) {
+			return conflict(apierror.CodeInvalidTransition, "The meeting has already ended")
+		}
+	case models.BookingCompleted:
+		if now.Before(booking.StartTime) {
+			return conflict(apierror.CodeInvalidTransition, "The meeting has not started yet")
+		}
+	case models.BookingNoShow:
+		if now.Before(booking.StartTime.Add(s.noShowGrace)) {
+			return conflict(apierror.CodeInvalidTransition, "A booking is a no-show only %s after the start", s.noShowGrace)
+		}
+	}
+	return nil
+}
+
+// ConfirmHold converts a tentative hold into a booking. Rooms that require
+// approval move to pending_approval instead of confirmed.
+func (s *BookingService) ConfirmHold(actorID, id uint) (models.Booking, error) {
+	booking, err := s.load(id)
+	if err != nil {
+		return booking, err
+	}
+	if !s.CanActFor(actorID, booking.EmployeeID, models.DelegateBook) {
+		return booking, forbidden("Forbidden")
+	}
+	if booking.Status != models.BookingTentative {
+		return booking, conflict(apierror.CodeConflict, "Booking is not a hold")
+	}
+	if booking.ExpiresAt != nil && booking.ExpiresAt.Before(time.Now()) {
+		return booking, conflict(apierror.CodeConflict, "Hold has expired")
+	}
+
+	target := models.BookingConfirmed
+	if booking.Room.RequiresApproval {
+		s.requestApproval(&booking)
+		target = models.BookingPendingApproval
+	}
+	err = s.bookings.Transition(&booking, target, &actorID, "hold confirmed")
+	return booking, err
+}
+
+// Decide approves or rejects a pending booking. A nil approver means the
+// decision came with a signed link from the approval email.
+func (s *BookingService) Decide(booking *models.Booking, approve bool, approverID *uint, reason string) error {
+	if approverID != nil && !s.isApprover(booking.Room.ApproverGroupID, *approverID) {
+		return forbidden("Forbidden")
+	}
+	if booking.Status != models.BookingPendingApproval {
+		return conflict(apierror.CodeConflict, "Booking is not pending approval")
+	}
+	target, action := models.BookingConfirmed, "approve"
+	if !approve {
+		target, action = models.BookingRejected, "reject"
+	}
+	if reason == "" && approverID == nil {
+		reason = action + "d via email link"
+	}
+	return s.bookings.Transition(booking, target, approverID, reason)
+}
+
+func (s *BookingService) isApprover(groupID *uint, employeeID uint) bool {
+	if groupID == nil {
+		return false
+	}
+	groups, err := s.employees.ApproverGroupIDs(employeeID)
+	if err != nil {
+		return false
+	}
+	for _, id := range groups {
+		if id == *groupID {
+			return true
+		}
+	}
+	return false
+}
+
+// NewOrganizer loads the employee who is to take over bookings, who must be
+// active.
+func (s *BookingService) NewOrganizer(id uint) (models.Employee, error) {
+	employee, err := s.employees.Get(id)
+	if err != nil || !employee.IsActive() {
+		return employee, invalidField("new_organizer_id", validation.CodeNotFound, "new organizer does not exist or is deactivated")
+	}
+	return employee, nil
+}
+
+// Upcoming returns the employee's active single-room bookings and booking
+// groups that have not started yet.
+func (s *BookingService) Upcoming(employeeID uint) ([]models.Booking, []models.BookingGroup, error) {
+	now := time.Now()
+	bookings, err := s.bookings.Upcoming(employeeID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	groups, err := s.groups.Upcoming(employeeID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bookings, groups, nil
+}
+
+// Transition applies a status change decided elsewhere, e.g. by a job.
+func (s *BookingService) Transition(booking *models.Booking, to models.BookingStatus, actorID *uint, reason string) error {
+	return s.bookings.Transition(booking, to, actorID, reason)
+}
+
+// ReleaseExpiredHolds releases tentative holds whose expiry has passed and
+// returns them.
+func (s *BookingService) ReleaseExpiredHolds(now time.Time) ([]models.Booking, error) {
+	return s.lapse(models.BookingTentative, models.BookingReleased, now, "hold expired")
+}
+
+// ExpirePendingApprovals rejects pending bookings whose approval deadline
+// has passed and returns them.
+func (s *BookingService) ExpirePendingApprovals(now time.Time) ([]models.Booking, error) {
+	return s.lapse(models.BookingPendingApproval, models.BookingRejected, now,
+		"the approval request expired without a response")
+}
+
+func (s *BookingService) lapse(from, to models.BookingStatus, now time.Time, reason string) ([]models.Booking, error) {
+	bookings, err := s.bookings.Lapsed(from, now)
+	if err != nil {
+		return nil, err
+	}
+	var lapsed []models.Booking
+	for i := range bookings {
+		if err := s.bookings.Transition(&bookings[i], to, nil, reason); err != nil {
+			slog.Error("Failed to change booking status", "booking_id", bookings[i].ID, "status", to, "error", err)
+			continue
+		}
+		lapsed = append(lapsed, bookings[i])
+	}
+	return lapsed, nil
+}
+
+// ReleaseNoShows marks confirmed bookings that nobody checked in to within
+// grace of the start as no-shows, which frees their room, and returns them.
+func (s *BookingService) ReleaseNoShows(now time.Time, grace time.Duration) ([]models.Booking, error) {
+	bookings, err := s.bookings.Unattended(now.Add(-grace), now)
+	if err != nil {
+		return nil, err
+	}
+	var released []models.Booking
+	for i := range bookings {
+		if err := s.bookings.Transition(&bookings[i], models.BookingNoShow, nil, "nobody checked in"); err != nil {
+			slog.Error("Failed to release no-show booking", "booking_id", bookings[i].ID, "error", err)
+			continue
+		}
+		released = append(released, bookings[i])
+	}
+	return released, nil
+}
+
+// CompleteFinished marks confirmed and checked-in bookings whose end time
+// has passed as completed and returns how many were.
+func (s *BookingService) CompleteFinished(now time.Time) (int, error) {
+	bookings, err := s.bookings.Finished(now)
+	if err != nil {
+		return 0, err
+	}
+	completed := 0
+	for i := range bookings {
+		if err := s.bookings.Transition(&bookings[i], models.BookingCompleted, nil, "meeting ended"); err != nil {
+			slog.Error("Failed to complete booking", "booking_id", bookings[i].ID, "error", err)
+			continue
+		}
+		completed++
+	}
+	return completed, nil
+}
+
+// HoldDeadline returns when a tentative hold lapses. Holds default to 15
+// minutes, may last up to an hour, and never outlive the start of the meeting.
+func HoldDeadline(start time.Time, minutes int) (time.Time, error) {
+	duration := defaultHoldDuration
+	if minutes != 0 {
+		duration = time.Duration(minutes) * time.Minute
+	}
+	if duration <= 0 || duration > maxHoldDuration {
+		return time.Time{}, fmt.Errorf("hold_minutes must be between 1 and %d", int(maxHoldDuration.Minutes()))
+	}
+	deadline := time.Now().Add(duration)
+	if start.Before(deadline) {
+		deadline = start
+	}
+	if !deadline.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("cannot hold a room for a meeting that has already started")
+	}
+	return deadline, nil
+}
+
+// ApprovalDeadline returns when an unanswered approval request lapses. It is
+// the approval timeout from now, but never later than the meeting itself.
+func (s *BookingService) ApprovalDeadline(start time.Time) time.Time {
+	deadline := time.Now().Add(s.approvalTimeout)
+	if start.Before(deadline) {
+		return start
+	}
+	return deadline
+}
+
+// ApplyApprovalState puts bookings for restricted rooms into the pending
+// state and confirms everything else.
+func (s *BookingService) ApplyApprovalState(booking *models.Booking, room models.Room) {
+	if room.RequiresApproval {
+		booking.Status = models.BookingPendingApproval
+		s.requestApproval(booking)
+		return
+	}
+	booking.Status = models.BookingConfirmed
+	booking.ExpiresAt = nil
+	booking.PendingSince = nil
+}
+
+// requestApproval starts a new approval request for the booking, which
+// lapses at the approval deadline. Links sent for earlier requests stop
+// working. Times are kept to the millisecond, which every database stores.
+func (s *BookingService) requestApproval(booking *models.Booking) {
+	deadline := s.ApprovalDeadline(booking.StartTime)
+	since := time.Now().Truncate(time.Millisecond)
+	booking.ExpiresAt = &deadline
+	booking.PendingSince = &since
+}
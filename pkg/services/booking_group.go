@@ -0,0 +1,229 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/koushikidey/go-meetingroombook/pkg/utils"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+)
+
+// checkGroupRooms verifies that every requested room exists, fits its
+// attendees and is free for the whole slot, refusing the request with every
+// reason at once. Bookings that already belong to the group are ignored so a
+// group can be moved onto its own slots. The rooms stay locked until the
+// transaction ends, as in checkAvailable.
+func checkGroupRooms(repos repository.Repositories, input models.BookingGroupDTO, groupID uint) (map[uint]models.Room, error) {
+	ids := make([]uint, len(input.Rooms))
+	for i, req := range input.Rooms {
+		ids[i] = req.RoomID
+	}
+	if err := repos.Rooms.Lock(ids...); err != nil {
+		return nil, err
+	}
+
+	rooms := map[uint]models.Room{}
+	var problems validation.Errors
+	conflicting := false
+	for i, req := range input.Rooms {
+		field := fmt.Sprintf("rooms[%d].room_id", i)
+		room, err := repos.Rooms.Get(req.RoomID)
+		if repository.IsNotFound(err) {
+			problems = append(problems, validation.FieldError{Field: field, Code: validation.CodeNotFound,
+				Message: fmt.Sprintf("room %d does not exist", req.RoomID)})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if room.Capacity != nil {
+			if _, err := utils.IsCapacityExceeding(req.NumAttendees, *room.Capacity); err != nil {
+				problems = append(problems, validation.FieldError{Field: field, Code: apierror.CodeCapacityExceeded,
+					Message: fmt.Sprintf("%s: capacity exceeded", room.Name)})
+				continue
+			}
+		}
+
+		overlapping, err := repos.Bookings.OverlappingOutsideGroup(room.ID, input.StartTime, input.EndTime, groupID)
+		if err != nil {
+			return nil, err
+		}
+		if len(overlapping) > 0 {
+			conflicting = true
+			problems = append(problems, validation.FieldError{Field: field, Code: apierror.CodeBookingConflict,
+				Message: fmt.Sprintf("%s: conflicts with an existing booking", room.Name)})
+			continue
+		}
+		rooms[room.ID] = room
+	}
+
+	switch {
+	case conflicting:
+		return nil, &Error{Kind: KindConflict, Code: apierror.CodeBookingConflict,
+			Message: "One or more rooms are unavailable", Details: problems}
+	case len(problems) > 0:
+		return nil, &Error{Kind: KindInvalid, Code: apierror.CodeValidation,
+			Message: "One or more rooms cannot be booked", Details: problems}
+	}
+	return rooms, nil
+}
+
+// GetGroup loads a booking group with its bookings and makes sure the actor
+// may perform the action on it. An empty action means viewing.
+func (s *BookingService) GetGroup(actorID, id uint, action models.DelegateAction) (models.BookingGroup, error) {
+	group, err := s.groups.Get(id, "Bookings")
+	if repository.IsNotFound(err) {
+		return group, notFound("Booking group not found")
+	}
+	if err != nil {
+		return group, err
+	}
+	allowed := false
+	if action == "" {
+		allowed = s.CanView(actorID, models.Booking{EmployeeID: group.EmployeeID, CreatedByID: group.CreatedByID})
+	} else {
+		allowed = s.CanActFor(actorID, group.EmployeeID, action)
+	}
+	if !allowed {
+		return group, forbidden("Forbidden")
+	}
+	return group, nil
+}
+
+// CreateGroup reserves all requested rooms for the same slot, or none of them
+// if any room is unavailable.
+func (s *BookingService) CreateGroup(actorID uint, input models.BookingGroupDTO) (group models.BookingGroup, err error) {
+	defer func() { recordCreation("group", err) }()
+	attendees, err := s.Attendees(input.AttendeeIDs)
+	if err != nil {
+		return models.BookingGroup{}, err
+	}
+	organizerID, allowed := s.ResolveOrganizer(actorID, input.EmployeeID)
+	if !allowed {
+		return models.BookingGroup{}, forbidden("You are not allowed to book on behalf of this employee")
+	}
+
+	group = models.BookingGroup{
+		Title:       input.Title,
+		EmployeeID:  organizerID,
+		CreatedByID: actorID,
+		StartTime:   input.StartTime,
+		EndTime:     input.EndTime,
+	}
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		rooms, err := checkGroupRooms(tx, input, 0)
+		if err != nil {
+			return err
+		}
+		if err := tx.BookingGroups.Create(&group); err != nil {
+			return err
+		}
+		for _, req := range input.Rooms {
+			booking := models.Booking{
+				RoomID:       req.RoomID,
+				EmployeeID:   organizerID,
+				CreatedByID:  actorID,
+				StartTime:    input.StartTime,
+				EndTime:      input.EndTime,
+				NumAttendees: req.NumAttendees,
+				GroupID:      &group.ID,
+				Attendees:    attendees,
+			}
+			s.ApplyApprovalState(&booking, rooms[req.RoomID])
+			if err := tx.Bookings.Create(&booking, &actorID, "group booking created"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return group, err
+}
+
+// UpdateGroup moves the group to a new slot and/or changes its rooms. Every
+// room is re-checked and the update is applied to all rooms or none. Rooms
+// left out of the request are cancelled.
+func (s *BookingService) UpdateGroup(actorID uint, group *models.BookingGroup, input models.BookingGroupDTO) error {
+	attendees, err := s.Attendees(input.AttendeeIDs)
+	if err != nil {
+		return err
+	}
+
+	current := map[uint]*models.Booking{}
+	for i := range group.Bookings {
+		b := &group.Bookings[i]
+		if b.Status == models.BookingCheckedIn {
+			return conflict(apierror.CodeConflict, "The meeting is already in progress")
+		}
+		if b.Status.IsActive() {
+			current[b.RoomID] = b
+		}
+	}
+
+	return s.repos.Transaction(func(tx repository.Repositories) error {
+		rooms, err := checkGroupRooms(tx, input, group.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, req := range input.Rooms {
+			booking, exists := current[req.RoomID]
+			if !exists {
+				booking = &models.Booking{
+					RoomID:      req.RoomID,
+					EmployeeID:  group.EmployeeID,
+					CreatedByID: actorID,
+					GroupID:     &group.ID,
+				}
+			}
+			previous := booking.Status
+			booking.StartTime = input.StartTime
+			booking.EndTime = input.EndTime
+			booking.NumAttendees = req.NumAttendees
+			booking.CalendarID = ""
+			s.ApplyApprovalState(booking, rooms[req.RoomID])
+			if exists && previous != booking.Status {
+				if err := models.ValidateTransition(previous, booking.Status); err != nil {
+					return err
+				}
+			}
+			if err := tx.Bookings.Update(booking, previous, &attendees, &actorID, "group booking updated"); err != nil {
+				return err
+			}
+			delete(current, req.RoomID)
+		}
+
+		for _, removed := range current {
+			if err := tx.Bookings.Transition(removed, models.BookingCancelled, &actorID, "room removed from group"); err != nil {
+				return err
+			}
+		}
+		return tx.BookingGroups.Reschedule(group, input.Title, input.StartTime, input.EndTime)
+	})
+}
+
+// CancelGroup cancels every active booking of the group, or none of them if
+// any cannot be cancelled, and returns the group with the bookings that were
+// cancelled.
+func (s *BookingService) CancelGroup(groupID uint, actorID *uint, reason string) (models.BookingGroup, []models.Booking, error) {
+	group, err := s.groups.GetActive(groupID)
+	if err != nil {
+		return group, nil, err
+	}
+	var cancelled []models.Booking
+	err = s.repos.Transaction(func(tx repository.Repositories) error {
+		for i := range group.Bookings {
+			b := &group.Bookings[i]
+			if err := tx.Bookings.Transition(b, models.BookingCancelled, actorID, reason); err != nil {
+				return err
+			}
+			cancelled = append(cancelled, *b)
+		}
+		return nil
+	})
+	if err != nil {
+		return group, nil, err
+	}
+	return group, cancelled, nil
+}
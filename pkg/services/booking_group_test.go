@@ -0,0 +1,191 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/dbtest"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// failBookingUpdate makes the nth update of a booking from now on fail.
+func failBookingUpdate(t *testing.T, db *gorm.DB, nth int) {
+	updates := 0
+	name := "test:fail_booking_update"
+	err := db.Callback().Update().Before("gorm:update").Register(name, func(tx *gorm.DB) {
+		if tx.Statement.Table != "bookings" {
+			return
+		}
+		if updates++; updates == nth {
+			tx.AddError(errors.New("disk full"))
+		}
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Callback().Update().Remove(name) })
+}
+
+func TestCancelGroupCancelsAllRoomsOrNone(t *testing.T) {
+	eachService(t, func(t *testing.T, s *BookingService, db *gorm.DB) {
+		organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+		db.Create(&organizer)
+		focus, huddle := models.Room{Name: "Focus"}, models.Room{Name: "Huddle"}
+		db.Create(&focus)
+		db.Create(&huddle)
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		group, err := s.CreateGroup(organizer.ID, models.BookingGroupDTO{
+			Title: "Offsite", StartTime: start, EndTime: start.Add(time.Hour),
+			Rooms: []models.BookingGroupRoomDTO{{RoomID: focus.ID, NumAttendees: 2}, {RoomID: huddle.ID, NumAttendees: 2}},
+		})
+		assert.NoError(t, err)
+
+		failBookingUpdate(t, db, 2)
+		_, cancelled, err := s.CancelGroup(group.ID, &organizer.ID, "plans changed")
+		assert.Error(t, err)
+		assert.Empty(t, cancelled)
+		var active int64
+		db.Model(&models.Booking{}).Where("group_id = ? AND status = ?", group.ID, models.BookingConfirmed).Count(&active)
+		assert.Equal(t, int64(2), active, "the first room is not cancelled on its own")
+
+		_, cancelled, err = s.CancelGroup(group.ID, &organizer.ID, "plans changed")
+		assert.NoError(t, err)
+		assert.Len(t, cancelled, 2)
+	})
+}
+
+func TestGroupIsCreatedWithAllRoomsOrNone(t *testing.T) {
+	eachService(t, func(t *testing.T, s *BookingService, db *gorm.DB) {
+		organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+		db.Create(&organizer)
+		focus, huddle := models.Room{Name: "Focus"}, models.Room{Name: "Huddle"}
+		db.Create(&focus)
+		db.Create(&huddle)
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		_, err := s.Create(organizer.ID, models.BookingDTO{RoomID: huddle.ID, StartTime: start, EndTime: start.Add(time.Hour)})
+		assert.NoError(t, err)
+
+		_, err = s.CreateGroup(organizer.ID, models.BookingGroupDTO{
+			Title: "Offsite", StartTime: start.Add(30 * time.Minute), EndTime: start.Add(90 * time.Minute),
+			Rooms: []models.BookingGroupRoomDTO{{RoomID: focus.ID}, {RoomID: huddle.ID}},
+		})
+		assertRefused(t, err, KindConflict)
+		var refused *Error
+		if errors.As(err, &refused) && assert.Len(t, refused.Details, 1) {
+			assert.Equal(t, "rooms[1].room_id", refused.Details[0].Field)
+		}
+		var groups, bookings int64
+		db.Model(&models.BookingGroup{}).Count(&groups)
+		db.Model(&models.Booking{}).Where("room_id = ?", focus.ID).Count(&bookings)
+		assert.Zero(t, groups)
+		assert.Zero(t, bookings, "the free room is not booked on its own")
+	})
+}
+
+func TestGroupIsUpdatedForAllRoomsOrNone(t *testing.T) {
+	eachService(t, func(t *testing.T, s *BookingService, db *gorm.DB) {
+		organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+		db.Create(&organizer)
+		focus, huddle := models.Room{Name: "Focus"}, models.Room{Name: "Huddle"}
+		db.Create(&focus)
+		db.Create(&huddle)
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		created, err := s.CreateGroup(organizer.ID, models.BookingGroupDTO{
+			Title: "Offsite", StartTime: start, EndTime: start.Add(time.Hour),
+			Rooms: []models.BookingGroupRoomDTO{{RoomID: focus.ID}, {RoomID: huddle.ID}},
+		})
+		assert.NoError(t, err)
+		later := start.Add(3 * time.Hour)
+		_, err = s.Create(organizer.ID, models.BookingDTO{RoomID: huddle.ID, StartTime: later, EndTime: later.Add(time.Hour)})
+		assert.NoError(t, err)
+
+		group, err := s.GetGroup(organizer.ID, created.ID, models.DelegateEdit)
+		assert.NoError(t, err)
+		err = s.UpdateGroup(organizer.ID, &group, models.BookingGroupDTO{
+			Title: "Offsite", StartTime: later, EndTime: later.Add(time.Hour),
+			Rooms: []models.BookingGroupRoomDTO{{RoomID: focus.ID}, {RoomID: huddle.ID}},
+		})
+		assertRefused(t, err, KindConflict)
+		group, err = s.GetGroup(organizer.ID, created.ID, "")
+		assert.NoError(t, err)
+		assert.True(t, start.Equal(group.StartTime))
+		for _, b := range group.Bookings {
+			assert.True(t, start.Equal(b.StartTime), "room %d was moved on its own", b.RoomID)
+		}
+
+		// A slot free in every room moves the whole group.
+		err = s.UpdateGroup(organizer.ID, &group, models.BookingGroupDTO{
+			Title: "Offsite", StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour),
+			Rooms: []models.BookingGroupRoomDTO{{RoomID: focus.ID}, {RoomID: huddle.ID}},
+		})
+		assert.NoError(t, err)
+		group, err = s.GetGroup(organizer.ID, created.ID, "")
+		assert.NoError(t, err)
+		for _, b := range group.Bookings {
+			assert.True(t, start.Add(time.Hour).Equal(b.StartTime))
+		}
+	})
+}
+
+// TestConcurrentRequestsBookASlotOnce races single bookings and groups taking
+// their rooms in either order for the same slot. It needs a server with row
+// locks: the in-memory SQLite database is not shared between connections.
+func TestConcurrentRequestsBookASlotOnce(t *testing.T) {
+	if !slices.Contains(dbtest.Drivers(), "postgres") {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	db := dbtest.Open(t, "postgres")
+	_, err := migrations.Up(db)
+	assert.NoError(t, err)
+	s := NewBookingService(repository.New(db), 24*time.Hour, 15*time.Minute)
+
+	organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+	db.Create(&organizer)
+	focus, huddle := models.Room{Name: "Focus"}, models.Room{Name: "Huddle"}
+	db.Create(&focus)
+	db.Create(&huddle)
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	const attempts = 12
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			switch i % 3 {
+			case 0:
+				_, errs[i] = s.Create(organizer.ID, models.BookingDTO{RoomID: huddle.ID, StartTime: start, EndTime: start.Add(time.Hour)})
+			case 1:
+				_, errs[i] = s.CreateGroup(organizer.ID, models.BookingGroupDTO{
+					Title: "Offsite", StartTime: start, EndTime: start.Add(time.Hour),
+					Rooms: []models.BookingGroupRoomDTO{{RoomID: focus.ID}, {RoomID: huddle.ID}},
+				})
+			case 2:
+				_, errs[i] = s.CreateGroup(organizer.ID, models.BookingGroupDTO{
+					Title: "Offsite", StartTime: start, EndTime: start.Add(time.Hour),
+					Rooms: []models.BookingGroupRoomDTO{{RoomID: huddle.ID}, {RoomID: focus.ID}},
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assertRefused(t, err, KindConflict)
+	}
+	assert.Equal(t, 1, succeeded)
+	var booked int64
+	db.Model(&models.Booking{}).Where("room_id = ?", huddle.ID).Count(&booked)
+	assert.EqualValues(t, 1, booked)
+}
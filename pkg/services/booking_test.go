@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/dbtest"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// eachService runs fn with a service on a migrated database of every test
+// driver.
+func eachService(t *testing.T, fn func(t *testing.T, s *BookingService, db *gorm.DB)) {
+	dbtest.Each(t, func(t *testing.T, db *gorm.DB) {
+		_, err := migrations.Up(db)
+		assert.NoError(t, err)
+		fn(t, NewBookingService(repository.New(db), 24*time.Hour, 15*time.Minute), db)
+	})
+}
+
+// assertRefused checks that err is a refusal of the kind.
+func assertRefused(t *testing.T, err error, kind Kind) {
+	t.Helper()
+	var refused *Error
+	if assert.True(t, errors.As(err, &refused), "got %v", err) {
+		assert.Equal(t, kind, refused.Kind, refused.Message)
+	}
+}
+
+func TestHoldDeadline(t *testing.T) {
+	later := time.Now().Add(24 * time.Hour)
+	tests := []struct {
+		name    string
+		start   time.Time
+		minutes int
+		want    time.Duration
+		invalid bool
+	}{
+		{name: "Default", start: later, want: 15 * time.Minute},
+		{name: "Up to an hour", start: later, minutes: 60, want: time.Hour},
+		{name: "Longer than an hour", start: later, minutes: 61, invalid: true},
+		{name: "Negative", start: later, minutes: -5, invalid: true},
+		{name: "Not past the start", start: time.Now().Add(10 * time.Minute), minutes: 30, want: 10 * time.Minute},
+		{name: "Meeting already started", start: time.Now().Add(-time.Minute), invalid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deadline, err := HoldDeadline(test.start, test.minutes)
+			if test.invalid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.WithinDuration(t, time.Now().Add(test.want), deadline, 5*time.Second)
+		})
+	}
+}
+
+func TestHoldsAreConfirmedOnce(t *testing.T) {
+	eachService(t, func(t *testing.T, s *BookingService, db *gorm.DB) {
+		organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+		other := models.Employee{Name: "Bob", Email: "bob@example.com"}
+		db.Create(&organizer)
+		db.Create(&other)
+		room := models.Room{Name: "Focus"}
+		db.Create(&room)
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		request := models.BookingDTO{RoomID: room.ID, StartTime: start, EndTime: start.Add(time.Hour), Hold: true}
+
+		hold, err := s.Create(organizer.ID, request)
+		assert.NoError(t, err)
+		assert.Equal(t, models.BookingTentative, hold.Status)
+		if assert.NotNil(t, hold.ExpiresAt) {
+			assert.WithinDuration(t, time.Now().Add(15*time.Minute), *hold.ExpiresAt, 5*time.Second)
+		}
+
+		// The hold keeps the room for its holder.
+		request.Hold = false
+		_, err = s.Create(other.ID, request)
+		assertRefused(t, err, KindConflict)
+		_, err = s.ConfirmHold(other.ID, hold.ID)
+		assertRefused(t, err, KindForbidden)
+
+		confirmed, err := s.ConfirmHold(organizer.ID, hold.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.BookingConfirmed, confirmed.Status)
+		assert.Nil(t, confirmed.ExpiresAt)
+		_, err = s.ConfirmHold(organizer.ID, hold.ID)
+		assertRefused(t, err, KindConflict)
+	})
+}
+
+func TestConfirmingAHoldOnARestrictedRoomAsksForApproval(t *testing.T) {
+	eachService(t, func(t *testing.T, s *BookingService, db *gorm.DB) {
+		organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+		db.Create(&organizer)
+		group := models.ApproverGroup{Name: "Facilities"}
+		db.Create(&group)
+		room := models.Room{Name: "Boardroom", RequiresApproval: true, ApproverGroupID: &group.ID}
+		db.Create(&room)
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+		hold, err := s.Create(organizer.ID, models.BookingDTO{
+			RoomID: room.ID, StartTime: start, EndTime: start.Add(time.Hour), Hold: true, HoldMinutes: 30,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, models.BookingTentative, hold.Status, "holds are not sent for approval yet")
+
+		pending, err := s.ConfirmHold(organizer.ID, hold.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.BookingPendingApproval, pending.Status)
+		stored, err := s.bookings.Get(hold.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.BookingPendingApproval, stored.Status)
+		assert.NotNil(t, stored.PendingSince)
+		if assert.NotNil(t, stored.ExpiresAt) {
+			assert.WithinDuration(t, time.Now().Add(24*time.Hour), *stored.ExpiresAt, 5*time.Second)
+		}
+	})
+}
+
+func TestExpiredHoldsAreReleased(t *testing.T) {
+	eachService(t, func(t *testing.T, s *BookingService, db *gorm.DB) {
+		organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+		db.Create(&organizer)
+		room := models.Room{Name: "Focus"}
+		db.Create(&room)
+		start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+		lapsing, err := s.Create(organizer.ID, models.BookingDTO{
+			RoomID: room.ID, StartTime: start, EndTime: start.Add(time.Hour), Hold: true, HoldMinutes: 5,
+		})
+		assert.NoError(t, err)
+		lasting, err := s.Create(organizer.ID, models.BookingDTO{
+			RoomID: room.ID, StartTime: start.Add(2 * time.Hour), EndTime: start.Add(3 * time.Hour), Hold: true, HoldMinutes: 60,
+		})
+		assert.NoError(t, err)
+
+		released, err := s.ReleaseExpiredHolds(time.Now().Add(10 * time.Minute))
+		assert.NoError(t, err)
+		if assert.Len(t, released, 1) {
+			assert.Equal(t, lapsing.ID, released[0].ID)
+			assert.Equal(t, models.BookingReleased, released[0].Status)
+		}
+		stored, err := s.bookings.Get(lasting.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.BookingTentative, stored.Status)
+
+		// A hold that lapsed can no longer be confirmed, and its room is free.
+		_, err = s.ConfirmHold(organizer.ID, lapsing.ID)
+		assertRefused(t, err, KindConflict)
+		_, err = s.Create(organizer.ID, models.BookingDTO{RoomID: room.ID, StartTime: start, EndTime: start.Add(time.Hour)})
+		assert.NoError(t, err)
+	})
+}
+
+func TestStatusesFollowTheMeetingTime(t *testing.T) {
+	eachService(t, func(t *testing.T, s *BookingService, db *gorm.DB) {
+		organizer := models.Employee{Name: "Ann", Email: "ann@example.com"}
+		db.Create(&organizer)
+
+		tests := []struct {
+			name    string
+			start   time.Duration
+			status  models.BookingStatus
+			allowed bool
+		}{
+			{name: "Check in a week early", start: 7 * 24 * time.Hour, status: models.BookingCheckedIn},
+			{name: "Check in shortly before the start", start: 10 * time.Minute, status: models.BookingCheckedIn, allowed: true},
+			{name: "Check in during the meeting", start: -30 * time.Minute, status: models.BookingCheckedIn, allowed: true},
+			{name: "Check in after the end", start: -2 * time.Hour, status: models.BookingCheckedIn},
+			{name: "Complete before the start", start: 10 * time.Minute, status: models.BookingCompleted},
+			{name: "Complete once started", start: -time.Minute, status: models.BookingCompleted, allowed: true},
+			{name: "No-show before the start", start: time.Hour, status: models.BookingNoShow},
+			{name: "No-show within the grace period", start: -10 * time.Minute, status: models.BookingNoShow},
+			{name: "No-show after the grace period", start: -20 * time.Minute, status: models.BookingNoShow, allowed: true},
+			{name: "Cancel any time before the start", start: 7 * 24 * time.Hour, status: models.BookingCancelled, allowed: true},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				// A room per case keeps the bookings from overlapping.
+				room := models.Room{Name: test.name}
+				db.Create(&room)
+				start := time.Now().Add(test.start).Truncate(time.Second)
+				booking := models.Booking{RoomID: room.ID, EmployeeID: organizer.ID, CreatedByID: organizer.ID,
+					StartTime: start, EndTime: start.Add(time.Hour), Status: models.BookingConfirmed}
+				db.Create(&booking)
+
+				changed, err := s.ChangeStatus(organizer.ID, booking.ID, models.BookingTransitionDTO{Status: test.status})
+				if test.allowed {
+					assert.NoError(t, err)
+					assert.Equal(t, test.status, changed.Status)
+					return
+				}
+				assertRefused(t, err, KindConflict)
+				stored, err := s.bookings.Get(booking.ID)
+				assert.NoError(t, err)
+				assert.Equal(t, models.BookingConfirmed, stored.Status)
+			})
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/apierror"
+	"github.com/koushikidey/go-meetingroombook/pkg/metrics"
+	"github.com/koushikidey/go-meetingroombook/pkg/validation"
+)
+
+// Kind classifies why a request was refused, so handlers can choose the
+// HTTP status.
+type Kind int
+
+const (
+	KindInvalid Kind = iota + 1
+	KindForbidden
+	KindNotFound
+	KindConflict
+)
+
+// Error is a request the business rules refuse. Code is one of the apierror
+// codes; Details names the offending fields, if any.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details validation.Errors
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func notFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Code: apierror.CodeNotFound, Message: message}
+}
+
+func forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Code: apierror.CodeForbidden, Message: message}
+}
+
+func conflict(code, message string, args ...interface{}) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: fmt.Sprintf(message, args...)}
+}
+
+// invalidField refuses a request because of a single field.
+func invalidField(field, code, message string) *Error {
+	return &Error{
+		Kind:    KindInvalid,
+		Code:    apierror.CodeValidation,
+		Message: "Request validation failed",
+		Details: validation.Errors{{Field: field, Code: code, Message: message}},
+	}
+}
+
+// recordCreation counts a booking of the kind, single or group, or the
+// reason it was refused if the room was taken or too small.
+func recordCreation(kind string, err error) {
+	if err == nil {
+		metrics.BookingsCreated.WithLabelValues(kind).Inc()
+		return
+	}
+	var refused *Error
+	if !errors.As(err, &refused) {
+		return
+	}
+	if refused.Code == apierror.CodeBookingConflict {
+		metrics.BookingRejections.WithLabelValues(kind, "conflict").Inc()
+		return
+	}
+	// Groups report rooms that are too small per room.
+	capacity := refused.Code == apierror.CodeCapacityExceeded
+	for _, detail := range refused.Details {
+		capacity = capacity || detail.Code == apierror.CodeCapacityExceeded
+	}
+	if capacity {
+		metrics.BookingRejections.WithLabelValues(kind, "capacity").Inc()
+	}
+}
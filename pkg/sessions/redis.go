@@ -0,0 +1,128 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/redis/go-redis/v9"
+)
+
+type redisBackend struct {
+	client redis.UniversalClient
+}
+
+// Redis keeps sessions in Redis, which expires them by itself. Every
+// session is a JSON value; a set per employee lists their session IDs.
+func Redis(client redis.UniversalClient) Backend {
+	return redisBackend{client: client}
+}
+
+func sessionKey(id string) string {
+	return "session:" + id
+}
+
+func employeeKey(employeeID uint) string {
+	return fmt.Sprintf("sessions:employee:%d", employeeID)
+}
+
+func (b redisBackend) Get(ctx context.Context, id string) (models.Session, error) {
+	var session models.Session
+	raw, err := b.client.Get(ctx, sessionKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return session, ErrNotFound
+	}
+	if err != nil {
+		return session, err
+	}
+	return session, json.Unmarshal(raw, &session)
+}
+
+func (b redisBackend) Save(ctx context.Context, session models.Session) error {
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return b.Delete(ctx, session.ID)
+	}
+	raw, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sessionKey(session.ID), raw, ttl)
+		if session.EmployeeID != 0 {
+			pipe.SAdd(ctx, employeeKey(session.EmployeeID), session.ID)
+		}
+		return nil
+	})
+	return err
+}
+
+func (b redisBackend) Delete(ctx context.Context, id string) error {
+	session, err := b.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(id))
+		pipe.SRem(ctx, employeeKey(session.EmployeeID), id)
+		return nil
+	})
+	return err
+}
+
+// List also forgets the IDs of sessions Redis expired.
+func (b redisBackend) List(ctx context.Context, employeeID uint, now time.Time) ([]models.Session, error) {
+	ids, err := b.client.SMembers(ctx, employeeKey(employeeID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	var sessions []models.Session
+	for _, id := range ids {
+		session, err := b.Get(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			if err := b.client.SRem(ctx, employeeKey(employeeID), id).Err(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+func (b redisBackend) DeleteEmployee(ctx context.Context, employeeID uint, except string) (int, error) {
+	ids, err := b.client.SMembers(ctx, employeeKey(employeeID)).Result()
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, id := range ids {
+		if id == except {
+			continue
+		}
+		removed, err := b.client.Del(ctx, sessionKey(id)).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if err := b.client.SRem(ctx, employeeKey(employeeID), id).Err(); err != nil {
+			return deleted, err
+		}
+		deleted += int(removed)
+	}
+	return deleted, nil
+}
@@ -1,11 +1,327 @@
+// Package session keeps sign-in sessions on the server. The cookie only
+// carries a random token signed with the session key; what the session
+// holds and when it expires is stored in the database or in Redis, so an
+// employee's sessions can be listed and revoked.
 package session
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
 )
 
-var store = sessions.NewCookieStore([]byte("super-secret-key"))
+// EmployeeKey is the session value holding the ID of the signed-in
+// employee.
+const EmployeeKey = "employee_id"
+
+// touchInterval is how often using a session is recorded, which pushes
+// back its idle timeout.
+const touchInterval = time.Minute
+
+// ErrNotFound is returned for sessions that do not exist or have expired.
+var ErrNotFound = errors.New("session not found")
+
+// Backend keeps sessions by ID.
+type Backend interface {
+	Get(ctx context.Context, id string) (models.Session, error)
+	Save(ctx context.Context, session models.Session) error
+	Delete(ctx context.Context, id string) error
+	// List returns the employee's sessions that have not expired by now,
+	// most recently used first.
+	List(ctx context.Context, employeeID uint, now time.Time) ([]models.Session, error)
+	// DeleteEmployee deletes the employee's sessions except the one with
+	// the ID except, and returns how many were deleted.
+	DeleteEmployee(ctx context.Context, employeeID uint, except string) (int, error)
+}
+
+type database struct {
+	repo repository.SessionRepository
+}
+
+// Database keeps sessions in the sessions table. Expired rows are deleted
+// by the purge job.
+func Database(repo repository.SessionRepository) Backend {
+	return database{repo: repo}
+}
+
+func (d database) Get(_ context.Context, id string) (models.Session, error) {
+	session, err := d.repo.Get(id)
+	if repository.IsNotFound(err) {
+		return session, ErrNotFound
+	}
+	return session, err
+}
+
+func (d database) Save(_ context.Context, session models.Session) error {
+	return d.repo.Save(&session)
+}
+
+func (d database) Delete(_ context.Context, id string) error {
+	return d.repo.Delete(id)
+}
+
+func (d database) List(_ context.Context, employeeID uint, now time.Time) ([]models.Session, error) {
+	return d.repo.ListActive(employeeID, now)
+}
+
+func (d database) DeleteEmployee(_ context.Context, employeeID uint, except string) (int, error) {
+	deleted, err := d.repo.DeleteByEmployee(employeeID, except)
+	return int(deleted), err
+}
+
+// Store is a gorilla/sessions store keeping the sessions in a Backend.
+type Store struct {
+	backend  Backend
+	codecs   []securecookie.Codec
+	options  sessions.Options
+	idle     time.Duration
+	absolute time.Duration
+	now      func() time.Time
+}
+
+// NewStore returns a store keeping sessions in backend. Cookies are signed
+// with cfg.Key; those signed with cfg.PreviousKeys are still accepted.
+func NewStore(backend Backend, cfg config.SessionConfig) *Store {
+	absolute := time.Duration(cfg.AbsoluteTimeout)
+	var pairs [][]byte
+	for _, key := range append([]string{cfg.Key}, cfg.PreviousKeys...) {
+		pairs = append(pairs, []byte(key), nil)
+	}
+	codecs := securecookie.CodecsFromPairs(pairs...)
+	for _, codec := range codecs {
+		if cookie, ok := codec.(*securecookie.SecureCookie); ok {
+			cookie.MaxAge(int(absolute.Seconds()))
+		}
+	}
+	return &Store{
+		backend: backend,
+		codecs:  codecs,
+		options: sessions.Options{
+			Path:     "/",
+			MaxAge:   int(absolute.Seconds()),
+			Secure:   cfg.Secure,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+		idle:     time.Duration(cfg.IdleTimeout),
+		absolute: absolute,
+		now:      time.Now,
+	}
+}
+
+// Get returns the named session of the request, loading it once per
+// request.
+func (s *Store) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New loads the session named by the request's cookie. Without a valid
+// cookie, or once the session expired or was revoked, it returns a new,
+// empty session.
+func (s *Store) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	options := s.options
+	session.Options = &options
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	var token string
+	if err := securecookie.DecodeMulti(name, cookie.Value, &token, s.codecs...); err != nil {
+		return session, err
+	}
+	stored, err := s.load(r.Context(), hashToken(token))
+	if errors.Is(err, ErrNotFound) {
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+	if err := gob.NewDecoder(bytes.NewReader(stored.Data)).Decode(&session.Values); err != nil {
+		return session, err
+	}
+	session.ID = stored.ID
+	session.IsNew = false
+	return session, s.touch(r.Context(), stored)
+}
+
+// Save stores the session and sets the cookie of new sessions. A negative
+// MaxAge deletes the session and its cookie. Signing in as another employee
+// starts a new session, so a token obtained before sign-in is of no use
+// after it.
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	ctx := r.Context()
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := s.backend.Delete(ctx, session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	now := s.now()
+	employeeID, _ := session.Values[EmployeeKey].(uint)
+	stored := models.Session{CreatedAt: now}
+	if session.ID != "" {
+		previous, err := s.backend.Get(ctx, session.ID)
+		switch {
+		case err == nil && previous.EmployeeID == employeeID:
+			stored = previous
+		case err == nil:
+			if err := s.backend.Delete(ctx, session.ID); err != nil {
+				return err
+			}
+			session.ID = ""
+		case errors.Is(err, ErrNotFound):
+			session.ID = ""
+		default:
+			return err
+		}
+	}
+
+	var token string
+	if session.ID == "" {
+		var err error
+		if token, err = newToken(); err != nil {
+			return err
+		}
+		session.ID = hashToken(token)
+	}
+	var data bytes.Buffer
+	if err := gob.NewEncoder(&data).Encode(session.Values); err != nil {
+		return err
+	}
+	stored.ID = session.ID
+	stored.EmployeeID = employeeID
+	stored.Data = data.Bytes()
+	stored.UserAgent = truncate(r.UserAgent(), 255)
+	stored.IPAddress = clientIP(r)
+	stored.LastSeenAt = now
+	stored.ExpiresAt = s.expiry(stored.CreatedAt, now)
+	if err := s.backend.Save(ctx, stored); err != nil {
+		return err
+	}
+
+	if token != "" {
+		encoded, err := securecookie.EncodeMulti(session.Name(), token, s.codecs...)
+		if err != nil {
+			return err
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	}
+	session.IsNew = false
+	return nil
+}
+
+// Lookup returns the stored session with the ID, e.g. to tell when it
+// expires.
+func (s *Store) Lookup(ctx context.Context, id string) (models.Session, error) {
+	return s.load(ctx, id)
+}
+
+// List returns the employee's active sessions, most recently used first.
+func (s *Store) List(ctx context.Context, employeeID uint) ([]models.Session, error) {
+	return s.backend.List(ctx, employeeID, s.now())
+}
+
+// Revoke ends the employee's session with the ID. It returns ErrNotFound if
+// the employee has no such session.
+func (s *Store) Revoke(ctx context.Context, employeeID uint, id string) error {
+	stored, err := s.backend.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stored.EmployeeID != employeeID {
+		return ErrNotFound
+	}
+	return s.backend.Delete(ctx, id)
+}
+
+// RevokeAll ends the employee's sessions except the one with the ID except,
+// and returns how many were ended.
+func (s *Store) RevokeAll(ctx context.Context, employeeID uint, except string) (int, error) {
+	return s.backend.DeleteEmployee(ctx, employeeID, except)
+}
+
+// load returns the stored session unless it has expired.
+func (s *Store) load(ctx context.Context, id string) (models.Session, error) {
+	stored, err := s.backend.Get(ctx, id)
+	if err != nil {
+		return stored, err
+	}
+	if !s.now().Before(stored.ExpiresAt) {
+		if err := s.backend.Delete(ctx, id); err != nil {
+			return stored, err
+		}
+		return stored, ErrNotFound
+	}
+	return stored, nil
+}
+
+// touch records that the session was used, at most once per touchInterval.
+func (s *Store) touch(ctx context.Context, stored models.Session) error {
+	now := s.now()
+	if now.Sub(stored.LastSeenAt) < touchInterval {
+		return nil
+	}
+	stored.LastSeenAt = now
+	stored.ExpiresAt = s.expiry(stored.CreatedAt, now)
+	return s.backend.Save(ctx, stored)
+}
+
+// expiry is when a session created and last used at the given times ends:
+// after the idle timeout, but no later than the absolute timeout.
+func (s *Store) expiry(created, lastSeen time.Time) time.Time {
+	idle := lastSeen.Add(s.idle)
+	absolute := created.Add(s.absolute)
+	if idle.Before(absolute) {
+		return idle
+	}
+	return absolute
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return truncate(r.RemoteAddr, 64)
+	}
+	return host
+}
 
-func GetStore() *sessions.CookieStore {
-	return store
-}
\ No newline at end of file
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,193 @@
+package session
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gorilla/sessions"
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/dbtest"
+	"github.com/koushikidey/go-meetingroombook/pkg/migrations"
+	"github.com/koushikidey/go-meetingroombook/pkg/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const (
+	oldKey = "the-old-session-key-of-32-bytes!"
+	newKey = "the-new-session-key-of-32-bytes!"
+)
+
+func testConfig() config.SessionConfig {
+	return config.SessionConfig{
+		Key:             newKey,
+		IdleTimeout:     config.Duration(30 * time.Minute),
+		AbsoluteTimeout: config.Duration(2 * time.Hour),
+	}
+}
+
+// eachBackend runs fn with the database backend on every test database and
+// with the Redis backend on an in-memory Redis server.
+func eachBackend(t *testing.T, fn func(t *testing.T, backend Backend)) {
+	dbtest.Each(t, func(t *testing.T, db *gorm.DB) {
+		sqlDB, err := db.DB()
+		assert.NoError(t, err)
+		sqlDB.SetMaxOpenConns(1)
+		_, err = migrations.Up(db)
+		assert.NoError(t, err)
+		fn(t, Database(repository.NewSessionRepository(db)))
+	})
+	t.Run("redis", func(t *testing.T) {
+		server := miniredis.RunT(t)
+		fn(t, Redis(redis.NewClient(&redis.Options{Addr: server.Addr()})))
+	})
+}
+
+// signIn saves a new session of the employee and returns its cookie.
+func signIn(t *testing.T, store *Store, employeeID uint) *http.Cookie {
+	req := httptest.NewRequest("POST", "/login", nil)
+	rr := httptest.NewRecorder()
+	sess, err := store.Get(req, "session")
+	assert.NoError(t, err)
+	sess.Values[EmployeeKey] = employeeID
+	assert.NoError(t, sess.Save(req, rr))
+	cookies := rr.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	return cookies[0]
+}
+
+// load returns the session a request with the cookie gets.
+func load(store *Store, cookie *http.Cookie) (*sessions.Session, error) {
+	req := httptest.NewRequest("GET", "/bookings", nil)
+	req.AddCookie(cookie)
+	return store.Get(req, "session")
+}
+
+func TestSessionsAreKeptOnTheServer(t *testing.T) {
+	eachBackend(t, func(t *testing.T, backend Backend) {
+		store := NewStore(backend, testConfig())
+		cookie := signIn(t, store, 7)
+		assert.True(t, cookie.HttpOnly)
+		assert.Equal(t, int((2 * time.Hour).Seconds()), cookie.MaxAge)
+
+		sess, err := load(store, cookie)
+		assert.NoError(t, err)
+		assert.False(t, sess.IsNew)
+		assert.Equal(t, uint(7), sess.Values[EmployeeKey])
+
+		// Logging out deletes the session, so the cookie is of no more use.
+		req := httptest.NewRequest("POST", "/logout", nil)
+		req.AddCookie(cookie)
+		sess, _ = store.Get(req, "session")
+		sess.Options.MaxAge = -1
+		rr := httptest.NewRecorder()
+		assert.NoError(t, sess.Save(req, rr))
+		assert.Negative(t, rr.Result().Cookies()[0].MaxAge)
+
+		sess, err = load(store, cookie)
+		assert.NoError(t, err)
+		assert.True(t, sess.IsNew)
+		assert.Empty(t, sess.Values)
+	})
+}
+
+func TestSessionsExpire(t *testing.T) {
+	eachBackend(t, func(t *testing.T, backend Backend) {
+		store := NewStore(backend, testConfig())
+		now := time.Now()
+		store.now = func() time.Time { return now }
+		idle := signIn(t, store, 7)
+		busy := signIn(t, store, 7)
+
+		// Using a session pushes its idle timeout back.
+		for i := 0; i < 5; i++ {
+			now = now.Add(20 * time.Minute)
+			sess, _ := load(store, busy)
+			assert.False(t, sess.IsNew, "used after %s", time.Duration(i+1)*20*time.Minute)
+		}
+		sess, _ := load(store, idle)
+		assert.True(t, sess.IsNew, "idle for 100 minutes")
+
+		// Still, no session outlives the absolute timeout.
+		now = now.Add(20 * time.Minute)
+		sess, _ = load(store, busy)
+		assert.True(t, sess.IsNew, "two hours after sign-in")
+	})
+}
+
+func TestRotatedKeysStillVerifyCookies(t *testing.T) {
+	eachBackend(t, func(t *testing.T, backend Backend) {
+		cfg := testConfig()
+		cfg.Key = oldKey
+		cookie := signIn(t, NewStore(backend, cfg), 7)
+
+		cfg.Key = newKey
+		cfg.PreviousKeys = []string{oldKey}
+		sess, err := load(NewStore(backend, cfg), cookie)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), sess.Values[EmployeeKey])
+
+		sess, err = load(NewStore(backend, testConfig()), cookie)
+		assert.Error(t, err, "a dropped key no longer verifies")
+		assert.True(t, sess.IsNew)
+	})
+}
+
+func TestSignInAsAnotherEmployeeStartsANewSession(t *testing.T) {
+	eachBackend(t, func(t *testing.T, backend Backend) {
+		store := NewStore(backend, testConfig())
+		first := signIn(t, store, 7)
+
+		req := httptest.NewRequest("POST", "/login", nil)
+		req.AddCookie(first)
+		sess, _ := store.Get(req, "session")
+		sess.Values[EmployeeKey] = uint(8)
+		rr := httptest.NewRecorder()
+		assert.NoError(t, sess.Save(req, rr))
+		assert.Len(t, rr.Result().Cookies(), 1, "a new token is issued")
+
+		sess, _ = load(store, first)
+		assert.True(t, sess.IsNew)
+		sess, _ = load(store, rr.Result().Cookies()[0])
+		assert.Equal(t, uint(8), sess.Values[EmployeeKey])
+	})
+}
+
+func TestListAndRevokeSessions(t *testing.T) {
+	eachBackend(t, func(t *testing.T, backend Backend) {
+		store := NewStore(backend, testConfig())
+		ctx := context.Background()
+		laptop := signIn(t, store, 7)
+		phone := signIn(t, store, 7)
+		other := signIn(t, store, 8)
+		current, _ := load(store, laptop)
+		stranger, _ := load(store, other)
+
+		active, err := store.List(ctx, 7)
+		assert.NoError(t, err)
+		assert.Len(t, active, 2)
+
+		assert.ErrorIs(t, store.Revoke(ctx, 7, stranger.ID), ErrNotFound)
+		revoked, err := store.RevokeAll(ctx, 7, current.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, revoked)
+
+		sess, _ := load(store, phone)
+		assert.True(t, sess.IsNew)
+		active, err = store.List(ctx, 7)
+		assert.NoError(t, err)
+		assert.Len(t, active, 1)
+		assert.Equal(t, current.ID, active[0].ID)
+
+		assert.NoError(t, store.Revoke(ctx, 7, current.ID))
+		sess, _ = load(store, laptop)
+		assert.True(t, sess.IsNew)
+		sess, _ = load(store, other)
+		assert.False(t, sess.IsNew)
+	})
+}
@@ -0,0 +1,174 @@
+package suggest
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+)
+
+const (
+	DefaultStep  = 15 * time.Minute
+	DefaultLimit = 10
+
+	// Weights of the individual scoring criteria. A perfect option scores 100.
+	capacityWeight  = 50.0
+	floorWeight     = 30.0
+	earlinessWeight = 20.0
+	// Penalty per attendee who is busy during the slot.
+	busyPenalty = 40.0
+	// Score lost per floor between the room and the organizer's home floor.
+	floorDistancePenalty = 10.0
+)
+
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (i Interval) overlaps(start, end time.Time) bool {
+	return i.Start.Before(end) && start.Before(i.End)
+}
+
+type Request struct {
+	Attendees   int
+	Duration    time.Duration
+	WindowStart time.Time
+	WindowEnd   time.Time
+	Amenities   []string
+	// OrganizerFloor is the organizer's home floor, if known.
+	OrganizerFloor *int
+	// RoomBusy holds the existing bookings of each room.
+	RoomBusy map[uint][]Interval
+	// AttendeeBusy holds the busy periods of each attendee.
+	AttendeeBusy map[uint][]Interval
+	Step         time.Duration
+	Limit        int
+}
+
+// Option is a candidate room and time, with a score between 0 and 100.
+type Option struct {
+	RoomID          uint      `json:"room_id"`
+	RoomName        string    `json:"room_name"`
+	Location        string    `json:"location"`
+	Floor           int       `json:"floor"`
+	Capacity        int       `json:"capacity"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+	Score           float64   `json:"score"`
+	BusyAttendeeIDs []uint    `json:"busy_attendee_ids,omitempty"`
+}
+
+// Rank returns the best room and time combinations for the request, best first.
+// Rooms that are too small, lack a required amenity or are already booked are
+// never suggested; busy attendees only lower an option's score.
+func Rank(req Request, rooms []models.Room) []Option {
+	step := req.Step
+	if step <= 0 {
+		step = DefaultStep
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	window := req.WindowEnd.Sub(req.WindowStart) - req.Duration
+
+	var options []Option
+	for _, room := range rooms {
+		if room.Capacity == nil || *room.Capacity < req.Attendees || !hasAmenities(room, req.Amenities) {
+			continue
+		}
+		for start := req.WindowStart; !start.Add(req.Duration).After(req.WindowEnd); start = start.Add(step) {
+			end := start.Add(req.Duration)
+			if busy(req.RoomBusy[room.ID], start, end) {
+				continue
+			}
+
+			option := Option{
+				RoomID:    room.ID,
+				RoomName:  room.Name,
+				Location:  room.Location,
+				Floor:     room.Floor,
+				Capacity:  *room.Capacity,
+				StartTime: start,
+				EndTime:   end,
+			}
+			for attendeeID, periods := range req.AttendeeBusy {
+				if busy(periods, start, end) {
+					option.BusyAttendeeIDs = append(option.BusyAttendeeIDs, attendeeID)
+				}
+			}
+			sort.Slice(option.BusyAttendeeIDs, func(i, j int) bool {
+				return option.BusyAttendeeIDs[i] < option.BusyAttendeeIDs[j]
+			})
+			option.Score = score(req, room, start.Sub(req.WindowStart), window, len(option.BusyAttendeeIDs))
+			options = append(options, option)
+		}
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		if options[i].Score != options[j].Score {
+			return options[i].Score > options[j].Score
+		}
+		if !options[i].StartTime.Equal(options[j].StartTime) {
+			return options[i].StartTime.Before(options[j].StartTime)
+		}
+		return options[i].RoomID < options[j].RoomID
+	})
+	if len(options) > limit {
+		options = options[:limit]
+	}
+	return options
+}
+
+func score(req Request, room models.Room, offset, window time.Duration, busyAttendees int) float64 {
+	attendees := req.Attendees
+	if attendees < 1 {
+		attendees = 1
+	}
+	// Prefer rooms that are just big enough over half-empty ones.
+	total := capacityWeight * float64(attendees) / float64(*room.Capacity)
+
+	if req.OrganizerFloor == nil {
+		total += floorWeight / 2
+	} else {
+		distance := math.Abs(float64(room.Floor - *req.OrganizerFloor))
+		total += math.Max(0, floorWeight-floorDistancePenalty*distance)
+	}
+
+	if window > 0 {
+		total += earlinessWeight * (1 - float64(offset)/float64(window))
+	} else {
+		total += earlinessWeight
+	}
+
+	total -= busyPenalty * float64(busyAttendees)
+	return math.Round(math.Max(0, total)*10) / 10
+}
+
+func hasAmenities(room models.Room, required []string) bool {
+	for _, want := range required {
+		found := false
+		for _, have := range room.Amenities {
+			if strings.EqualFold(have, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func busy(periods []Interval, start, end time.Time) bool {
+	for _, p := range periods {
+		if p.overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
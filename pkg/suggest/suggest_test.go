@@ -0,0 +1,73 @@
+package suggest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func room(id uint, capacity, floor int, amenities ...string) models.Room {
+	r := models.Room{Name: "Room", Capacity: &capacity, Floor: floor, Amenities: amenities}
+	r.ID = id
+	return r
+}
+
+func TestRankPrefersBestFit(t *testing.T) {
+	start := time.Date(2025, 7, 1, 14, 0, 0, 0, time.UTC)
+	floor := 2
+	rooms := []models.Room{
+		room(1, 20, 2, "projector"),
+		room(2, 4, 2, "projector"),
+		room(3, 4, 5, "projector"),
+		room(4, 4, 2),
+		room(5, 2, 2, "projector"),
+	}
+
+	options := Rank(Request{
+		Attendees:      3,
+		Duration:       time.Hour,
+		WindowStart:    start,
+		WindowEnd:      start.Add(2 * time.Hour),
+		Amenities:      []string{"Projector"},
+		OrganizerFloor: &floor,
+		Limit:          50,
+	}, rooms)
+
+	assert.NotEmpty(t, options)
+	assert.Equal(t, uint(2), options[0].RoomID)
+	assert.Equal(t, start, options[0].StartTime)
+	for _, o := range options {
+		assert.NotEqual(t, uint(4), o.RoomID, "room without projector suggested")
+		assert.NotEqual(t, uint(5), o.RoomID, "room that is too small suggested")
+	}
+}
+
+func TestRankSkipsBookedRoomsAndPenalisesBusyAttendees(t *testing.T) {
+	start := time.Date(2025, 7, 1, 14, 0, 0, 0, time.UTC)
+	rooms := []models.Room{room(1, 4, 1)}
+
+	options := Rank(Request{
+		Attendees:   2,
+		Duration:    time.Hour,
+		WindowStart: start,
+		WindowEnd:   start.Add(2 * time.Hour),
+		RoomBusy: map[uint][]Interval{
+			1: {{Start: start, End: start.Add(30 * time.Minute)}},
+		},
+		AttendeeBusy: map[uint][]Interval{
+			7: {{Start: start.Add(30 * time.Minute), End: start.Add(45 * time.Minute)}},
+		},
+		Limit: 50,
+	}, rooms)
+
+	assert.Len(t, options, 3)
+	for _, o := range options {
+		assert.False(t, o.StartTime.Before(start.Add(30*time.Minute)), "booked slot suggested")
+	}
+	best := options[0]
+	assert.Equal(t, start.Add(45*time.Minute), best.StartTime)
+	assert.Empty(t, best.BusyAttendeeIDs)
+	assert.Equal(t, []uint{7}, options[len(options)-1].BusyAttendeeIDs)
+}
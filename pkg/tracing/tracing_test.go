@@ -0,0 +1,38 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/koushikidey/go-meetingroombook/pkg/config"
+	"github.com/koushikidey/go-meetingroombook/pkg/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestSetupWithoutExporter(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "none"})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), config.TracingConfig{Exporter: "jaeger"})
+	assert.Error(t, err)
+}
+
+func TestEndRecordsErrors(t *testing.T) {
+	spans := tracingtest.Record(t)
+
+	ctx, parent := Start(context.Background(), "booking.create")
+	_, child := Start(ctx, "google.insert_event")
+	End(child, errors.New("quota exceeded"))
+	End(parent, nil)
+
+	ended := spans.GetSpans()
+	assert.Equal(t, []string{"google.insert_event", "booking.create"}, tracingtest.Names(ended))
+	assert.Equal(t, codes.Error, ended[0].Status.Code)
+	assert.Equal(t, "quota exceeded", ended[0].Status.Description)
+	assert.Len(t, ended[0].Events, 1)
+	assert.Equal(t, ended[1].SpanContext.SpanID(), ended[0].Parent.SpanID())
+	assert.Equal(t, codes.Unset, ended[1].Status.Code)
+}
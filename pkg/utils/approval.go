@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrApprovalSecretMissing = errors.New("APPROVAL_SECRET is not set")
+	ErrInvalidApprovalToken  = errors.New("invalid approval token")
+	ErrApprovalTokenExpired  = errors.New("approval token expired")
+	ErrApprovalTokenReplaced = errors.New("approval token is for an earlier request")
+)
+
+// SignApprovalToken returns a token that authorises a single approval action
+// ("approve" or "reject") on a booking until the given expiry. It is bound to
+// the approval request made at requested, so links from an earlier request
+// stop working once the booking is sent for approval again. The token is
+// signed with secret.
+func SignApprovalToken(secret string, bookingID uint, action string, requested, expires time.Time) (string, error) {
+	if secret == "" {
+		return "", ErrApprovalSecretMissing
+	}
+	payload := fmt.Sprintf("%d:%s:%d:%d", bookingID, action, requested.UnixMilli(), expires.Unix())
+	return encodeSegment([]byte(payload)) + "." + encodeSegment(sign(secret, payload)), nil
+}
+
+// VerifyApprovalToken checks that the token was issued for this booking,
+// action and approval request and has not expired.
+func VerifyApprovalToken(secret, token string, bookingID uint, action string, requested time.Time) error {
+	if secret == "" {
+		return ErrApprovalSecretMissing
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return ErrInvalidApprovalToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return ErrInvalidApprovalToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(mac, sign(secret, string(payload))) {
+		return ErrInvalidApprovalToken
+	}
+
+	fields := strings.Split(string(payload), ":")
+	if len(fields) != 4 || fields[0] != strconv.FormatUint(uint64(bookingID), 10) || fields[1] != action {
+		return ErrInvalidApprovalToken
+	}
+	if fields[2] != strconv.FormatInt(requested.UnixMilli(), 10) {
+		return ErrApprovalTokenReplaced
+	}
+	expires, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return ErrInvalidApprovalToken
+	}
+	if time.Now().Unix() > expires {
+		return ErrApprovalTokenExpired
+	}
+	return nil
+}
+
+func sign(secret, payload string) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
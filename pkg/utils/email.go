@@ -2,25 +2,30 @@ package utils
 
 import (
 	"fmt"
-	"os"
 
 	"gopkg.in/gomail.v2"
 )
 
-func SendEmail(to string, subject string, body string) error {
-	from := os.Getenv("EMAIL_USER")
-	pass := os.Getenv("EMAIL_PASS")
+// SMTPMailer sends plain-text emails through an SMTP server, from the
+// account it logs in with.
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+}
 
-	m := gomail.NewMessage()
-	m.SetHeader("From", from)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/plain", body)
+func (m SMTPMailer) Send(to string, subject string, body string) error {
+	msg := gomail.NewMessage()
+	msg.SetHeader("From", m.User)
+	msg.SetHeader("To", to)
+	msg.SetHeader("Subject", subject)
+	msg.SetBody("text/plain", body)
 
-	d := gomail.NewDialer("smtp.gmail.com", 587, from, pass)
+	d := gomail.NewDialer(m.Host, m.Port, m.User, m.Password)
 
-	if err := d.DialAndSend(m); err != nil {
+	if err := d.DialAndSend(msg); err != nil {
 		return fmt.Errorf("could not send email: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
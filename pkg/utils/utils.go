@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/koushikidey/go-meetingroombook/pkg/models"
@@ -29,12 +31,28 @@ func IsBookingConflict(start1, end1, start2, end2 time.Time, room1, room2 models
 	return false, nil
 }
 
-func ParseBody(r *http.Request, x interface{}) {
-	if body, err := io.ReadAll(r.Body); err == nil {
-		if err := json.Unmarshal([]byte(body), x); err != nil {
-			return
+// ParseBody decodes the JSON request body into x. An empty body leaves x
+// unchanged, so optional bodies can be omitted.
+func ParseBody(r *http.Request, x interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, x)
+}
+
+// SplitList splits a comma-separated query value, dropping empty entries.
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
 	}
+	return items
 }
 
 var ErrCapacityExceeded = errors.New("capacity exceeded")
@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -50,4 +51,27 @@ func TestIsCapacityExceeding(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestApprovalToken(t *testing.T) {
+	const secret = "test-secret"
+	expires := time.Now().Add(time.Hour)
+	requested := time.Now().Add(-time.Minute)
+
+	token, err := SignApprovalToken(secret, 42, "approve", requested, expires)
+	assert.NoError(t, err)
+
+	assert.NoError(t, VerifyApprovalToken(secret, token, 42, "approve", requested))
+	assert.ErrorIs(t, VerifyApprovalToken(secret, token, 42, "reject", requested), ErrInvalidApprovalToken)
+	assert.ErrorIs(t, VerifyApprovalToken(secret, token, 43, "approve", requested), ErrInvalidApprovalToken)
+	assert.ErrorIs(t, VerifyApprovalToken(secret, token+"x", 42, "approve", requested), ErrInvalidApprovalToken)
+	assert.ErrorIs(t, VerifyApprovalToken(secret, token, 42, "approve", time.Now()), ErrApprovalTokenReplaced,
+		"the booking was sent for approval again")
+
+	expired, err := SignApprovalToken(secret, 42, "approve", requested, time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.ErrorIs(t, VerifyApprovalToken(secret, expired, 42, "approve", requested), ErrApprovalTokenExpired)
+
+	_, err = SignApprovalToken("", 42, "approve", requested, expires)
+	assert.ErrorIs(t, err, ErrApprovalSecretMissing)
+}
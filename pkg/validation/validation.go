@@ -0,0 +1,131 @@
+// Package validation collects field-level problems with request bodies.
+//
+// DTOs implement Validate() error using a Validator:
+//
+//	func (d RoomDTO) Validate() error {
+//		var v validation.Validator
+//		v.Required("name", d.Name)
+//		v.Positive("capacity", d.Capacity)
+//		return v.Err()
+//	}
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Machine-readable codes of field errors.
+const (
+	CodeRequired   = "required"
+	CodeInvalid    = "invalid"
+	CodeEmail      = "invalid_email"
+	CodeOutOfRange = "out_of_range"
+	CodeTooShort   = "too_short"
+	CodeOrder      = "invalid_order"
+	CodeNotFound   = "not_found"
+	CodeTaken      = "already_taken"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors lists every problem found in a request body.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, f := range e {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Validable is implemented by request bodies that can check themselves.
+type Validable interface {
+	Validate() error
+}
+
+// Validator accumulates field errors. The zero value is ready to use.
+type Validator struct {
+	errs Errors
+}
+
+func (v *Validator) Add(field, code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Check adds an error unless ok holds.
+func (v *Validator) Check(ok bool, field, code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, CodeRequired, "is required")
+}
+
+func (v *Validator) RequiredID(field string, id uint) {
+	v.Check(id != 0, field, CodeRequired, "is required")
+}
+
+func (v *Validator) RequiredTime(field string, t time.Time) {
+	v.Check(!t.IsZero(), field, CodeRequired, "is required")
+}
+
+// Email checks that a non-empty value is a plain email address.
+func (v *Validator) Email(field, value string) {
+	if value == "" {
+		return
+	}
+	addr, err := mail.ParseAddress(value)
+	v.Check(err == nil && addr.Address == value, field, CodeEmail, "must be a valid email address")
+}
+
+func (v *Validator) MinLength(field, value string, min int) {
+	v.Check(len(value) >= min, field, CodeTooShort, fmt.Sprintf("must be at least %d characters", min))
+}
+
+func (v *Validator) Positive(field string, n int) {
+	v.Check(n > 0, field, CodeOutOfRange, "must be positive")
+}
+
+func (v *Validator) NonNegative(field string, n int) {
+	v.Check(n >= 0, field, CodeOutOfRange, "must not be negative")
+}
+
+func (v *Validator) Range(field string, n, min, max int) {
+	v.Check(n >= min && n <= max, field, CodeOutOfRange, fmt.Sprintf("must be between %d and %d", min, max))
+}
+
+// After checks that t is after ref, when both are set.
+func (v *Validator) After(field string, t time.Time, refField string, ref time.Time) {
+	if t.IsZero() || ref.IsZero() {
+		return
+	}
+	v.Check(t.After(ref), field, CodeOrder, "must be after "+refField)
+}
+
+// IDs checks that a list of IDs contains no zero.
+func (v *Validator) IDs(field string, ids []uint) {
+	for _, id := range ids {
+		if id == 0 {
+			v.Add(field, CodeInvalid, "must not contain 0")
+			return
+		}
+	}
+}
+
+// Err returns the collected errors, or nil if there are none.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
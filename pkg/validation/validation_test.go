@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name  string
+		check func(v *Validator)
+		codes []string
+	}{
+		{"valid", func(v *Validator) {
+			v.Required("name", "Focus")
+			v.Email("email", "a@example.com")
+			v.Positive("capacity", 4)
+			v.After("end_time", now.Add(time.Hour), "start_time", now)
+		}, nil},
+		{"blank", func(v *Validator) { v.Required("name", "  ") }, []string{CodeRequired}},
+		{"bad email", func(v *Validator) { v.Email("email", "Someone <a@example.com>") }, []string{CodeEmail}},
+		{"empty email is left to Required", func(v *Validator) { v.Email("email", "") }, nil},
+		{"zero capacity", func(v *Validator) { v.Positive("capacity", 0) }, []string{CodeOutOfRange}},
+		{"out of range", func(v *Validator) { v.Range("limit", 101, 0, 100) }, []string{CodeOutOfRange}},
+		{"short password", func(v *Validator) { v.MinLength("password", "abc", 8) }, []string{CodeTooShort}},
+		{"end before start", func(v *Validator) { v.After("end_time", now, "start_time", now) }, []string{CodeOrder}},
+		{"zero id", func(v *Validator) { v.IDs("attendee_ids", []uint{1, 0}) }, []string{CodeInvalid}},
+		{"several", func(v *Validator) {
+			v.RequiredID("room_id", 0)
+			v.RequiredTime("start_time", time.Time{})
+		}, []string{CodeRequired, CodeRequired}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v Validator
+			tt.check(&v)
+			err := v.Err()
+			if tt.codes == nil {
+				assert.NoError(t, err)
+				return
+			}
+			errs, ok := err.(Errors)
+			assert.True(t, ok)
+			var codes []string
+			for _, f := range errs {
+				codes = append(codes, f.Code)
+			}
+			assert.Equal(t, tt.codes, codes)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package version describes the build of the server. The values are set at
+// link time, for example:
+//
+//	go build -ldflags "-X github.com/koushikidey/go-meetingroombook/pkg/version.Version=1.4.0 \
+//	  -X github.com/koushikidey/go-meetingroombook/pkg/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/koushikidey/go-meetingroombook/pkg/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/main
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	// Version is the release, "dev" for builds that did not set it.
+	Version = "dev"
+	// Commit is the git commit the server was built from.
+	Commit = ""
+	// Date is when the server was built, in RFC 3339.
+	Date = ""
+)
+
+// Info is the build metadata of the running server.
+type Info struct {
+	Version   string `json:"version" example:"1.4.0"`
+	Commit    string `json:"commit" example:"83c5f30e1d2c"`
+	Date      string `json:"date" example:"2026-10-19T08:00:00Z"`
+	GoVersion string `json:"go_version" example:"go1.24.4"`
+}
+
+// Get returns the build metadata. The commit and date fall back to what the
+// Go toolchain recorded when building from a git checkout.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version()}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.Date == "":
+				info.Date = setting.Value
+			}
+		}
+	}
+	return info
+}